@@ -1,15 +1,35 @@
 package api
 
+import "github.com/gmerten/accounts_transactions/internal/model"
+
 type CreateAccountRequest struct {
-	DocumentNumber string `json:"document_number" validate:"required"`
+	DocumentNumber       string       `json:"document_number" validate:"required,document" example:"529.982.247-25"`
+	AvailableCreditLimit *model.Money `json:"available_credit_limit,omitempty" validate:"omitempty,gte=0,money" swaggertype:"number" example:"5000.00"`
+	ClosingDay           int          `json:"closing_day,omitempty" validate:"omitempty,gte=1,lte=28" example:"25"`
+	DueDay               int          `json:"due_day,omitempty" validate:"omitempty,gte=1,lte=28" example:"5"`
 }
 
 type CreateAccountResponse struct {
-	DocumentNumber string `json:"document_number"`
-	ID             int64  `json:"account_id"`
+	DocumentNumber       string       `json:"document_number" example:"52998224725"`
+	DocumentType         string       `json:"document_type" example:"cpf"`
+	ID                   int64        `json:"account_id"`
+	AvailableCreditLimit *model.Money `json:"available_credit_limit" swaggertype:"number" example:"5000.00"`
+	Status               string       `json:"status" example:"active"`
+	ClosingDay           int          `json:"closing_day" example:"25"`
+	DueDay               int          `json:"due_day" example:"5"`
 }
 
 type GetAccountResponse struct {
-	DocumentNumber string `json:"document_number"`
-	ID             int64  `json:"account_id"`
+	DocumentNumber       string       `json:"document_number" example:"52998224725"`
+	DocumentType         string       `json:"document_type" example:"cpf"`
+	ID                   int64        `json:"account_id"`
+	Balance              model.Money  `json:"balance" swaggertype:"number" example:"-120.50"`
+	AvailableCreditLimit *model.Money `json:"available_credit_limit" swaggertype:"number" example:"4879.50"`
+	Status               string       `json:"status" example:"active"`
+	ClosingDay           int          `json:"closing_day" example:"25"`
+	DueDay               int          `json:"due_day" example:"5"`
+}
+
+type UpdateAccountStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active blocked closed" example:"blocked"`
 }
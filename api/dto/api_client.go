@@ -0,0 +1,30 @@
+package api
+
+import "time"
+
+type CreateAPIClientRequest struct {
+	Name   string   `json:"name" validate:"required,max=255" example:"billing"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=accounts:read accounts:write transactions:write admin" example:"accounts:read"`
+}
+
+type APIClientResponse struct {
+	ID int64 `json:"client_id"`
+	// KeyPrefix is the beginning of the key of the client, to recognize it.
+	KeyPrefix string     `json:"key_prefix" example:"ak_3f9c2a1b"`
+	Name      string     `json:"name" example:"billing"`
+	Scopes    []string   `json:"scopes" example:"accounts:read"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+}
+
+// APIClientKeyResponse is the only response that carries the key, returned
+// when it is issued or rotated.
+type APIClientKeyResponse struct {
+	APIClientResponse
+	Key string `json:"key"`
+}
+
+type ListAPIClientsResponse struct {
+	Clients []APIClientResponse `json:"clients"`
+}
@@ -0,0 +1,15 @@
+package api
+
+type CreateOperationTypeRequest struct {
+	ID          int64  `json:"operation_type_id" validate:"omitempty,gte=1"`
+	Description string `json:"description" validate:"required,max=255"`
+	Sign        string `json:"sign" validate:"required,oneof=debit credit"`
+	Active      *bool  `json:"active,omitempty"`
+}
+
+type OperationTypeResponse struct {
+	ID          int64  `json:"operation_type_id"`
+	Description string `json:"description"`
+	Sign        string `json:"sign"`
+	Active      bool   `json:"active"`
+}
@@ -0,0 +1,5 @@
+package api
+
+type RedriveOutboxMessagesResponse struct {
+	Redriven int64 `json:"redriven"`
+}
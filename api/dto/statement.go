@@ -0,0 +1,39 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+)
+
+type StatementResponse struct {
+	AccountID      int64       `json:"account_id"`
+	Period         string      `json:"period" example:"2024-09"`
+	PeriodStart    time.Time   `json:"period_start"`
+	PeriodEnd      time.Time   `json:"period_end"`
+	DueDate        time.Time   `json:"due_date"`
+	OpeningBalance model.Money `json:"opening_balance" swaggertype:"number" example:"-100.00"`
+	TotalDebits    model.Money `json:"total_debits" swaggertype:"number" example:"250.50"`
+	TotalCredits   model.Money `json:"total_credits" swaggertype:"number" example:"100.00"`
+	ClosingBalance model.Money `json:"closing_balance" swaggertype:"number" example:"-250.50"`
+	MinimumPayment model.Money `json:"minimum_payment" swaggertype:"number" example:"37.58"`
+	Closed         bool        `json:"closed"`
+	ClosedAt       *time.Time  `json:"closed_at,omitempty"`
+}
+
+type ListStatementsResponse struct {
+	Statements []StatementResponse `json:"statements"`
+}
+
+type StatementInstallmentResponse struct {
+	TransactionID int64       `json:"transaction_id"`
+	Number        int         `json:"number"`
+	Amount        model.Money `json:"amount" swaggertype:"number" example:"66.84"`
+	DueDate       time.Time   `json:"due_date"`
+}
+
+type StatementDetailResponse struct {
+	StatementResponse
+	Transactions []TransactionResponse          `json:"transactions"`
+	Installments []StatementInstallmentResponse `json:"installments"`
+}
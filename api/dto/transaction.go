@@ -1,14 +1,63 @@
 package api
 
+import (
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+)
+
 type CreateTransactionRequest struct {
-	AccountID       int64   `json:"account_id" validate:"required,gte=1"`
-	Amount          float64 `json:"amount" validate:"required,gte=0"`
-	OperationTypeID uint    `json:"operation_type_id" validate:"required,oneof=1 2 3 4"`
+	AccountID       int64       `json:"account_id" validate:"required,gte=1"`
+	Amount          model.Money `json:"amount" validate:"required,gte=0,money" swaggertype:"number" example:"200.50"`
+	OperationTypeID uint        `json:"operation_type_id" validate:"required,gte=1"`
+	Installments    int         `json:"installments,omitempty" validate:"omitempty,gte=1,lte=48" example:"3"`
 }
 
 type CreateTransactionResponse struct {
-	TransactionID   int64   `json:"transaction_id"`
-	AccountID       int64   `json:"account_id"`
-	Amount          float64 `json:"amount"`
-	OperationTypeID uint    `json:"operation_type_id"`
+	TransactionID   int64       `json:"transaction_id"`
+	AccountID       int64       `json:"account_id"`
+	Amount          model.Money `json:"amount" swaggertype:"number" example:"-200.50"`
+	Balance         model.Money `json:"balance" swaggertype:"number" example:"-200.50"`
+	OperationTypeID uint        `json:"operation_type_id"`
+}
+
+type TransactionResponse struct {
+	TransactionID           int64       `json:"transaction_id"`
+	AccountID               int64       `json:"account_id"`
+	Amount                  model.Money `json:"amount" swaggertype:"number" example:"-200.50"`
+	Balance                 model.Money `json:"balance" swaggertype:"number" example:"-200.50"`
+	OperationTypeID         uint        `json:"operation_type_id"`
+	TransactionDate         time.Time   `json:"transaction_date"`
+	Status                  string      `json:"status" example:"posted"`
+	ReversedAmount          model.Money `json:"reversed_amount" swaggertype:"number" example:"0"`
+	ReversalOfTransactionID *int64      `json:"reversal_of_transaction_id,omitempty"`
+}
+
+type ListTransactionsResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+}
+
+type CreateReversalRequest struct {
+	Amount *model.Money `json:"amount,omitempty" validate:"omitempty,gt=0,money" swaggertype:"number" example:"50.00"`
+}
+
+type ReversalResponse struct {
+	Reversal TransactionResponse `json:"reversal"`
+	Original TransactionResponse `json:"original"`
+}
+
+type InstallmentResponse struct {
+	Number  int         `json:"number"`
+	Amount  model.Money `json:"amount" swaggertype:"number" example:"66.84"`
+	DueDate time.Time   `json:"due_date"`
+	Status  string      `json:"status" example:"scheduled"`
+}
+
+type InstallmentPlanResponse struct {
+	TransactionID    int64                 `json:"transaction_id"`
+	AccountID        int64                 `json:"account_id"`
+	TotalAmount      model.Money           `json:"total_amount" swaggertype:"number" example:"200.50"`
+	InstallmentCount int                   `json:"installment_count"`
+	Installments     []InstallmentResponse `json:"installments"`
 }
@@ -0,0 +1,55 @@
+package api
+
+import "time"
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048" example:"https://example.com/webhooks"`
+	EventTypes []string `json:"event_types,omitempty" validate:"dive,oneof=account.created transaction.posted" example:"transaction.posted"`
+	Secret     string   `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+	Active     *bool    `json:"active,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048" example:"https://example.com/webhooks"`
+	EventTypes []string `json:"event_types,omitempty" validate:"dive,oneof=account.created transaction.posted" example:"transaction.posted"`
+	Active     *bool    `json:"active" validate:"required"`
+}
+
+type WebhookResponse struct {
+	ID         int64     `json:"webhook_id"`
+	URL        string    `json:"url" example:"https://example.com/webhooks"`
+	EventTypes []string  `json:"event_types" example:"transaction.posted"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateWebhookResponse is the only response that carries the signing secret.
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"delivery_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type" example:"transaction.posted"`
+	Status         string     `json:"status" example:"pending"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty" example:"503"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+type RedriveWebhookDeliveriesResponse struct {
+	Redriven int64 `json:"redriven"`
+}
@@ -8,10 +8,10 @@ import (
 	api "github.com/gmerten/accounts_transactions/api/dto"
 	"github.com/gmerten/accounts_transactions/api/mapper"
 	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
 	"github.com/gmerten/accounts_transactions/internal/service"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
-	log "github.com/sirupsen/logrus"
 )
 
 type accountHandler struct {
@@ -21,6 +21,7 @@ type accountHandler struct {
 type AccountHandler interface {
 	HandleGetAccount(w http.ResponseWriter, r *http.Request)
 	HandleCreateAccount(w http.ResponseWriter, r *http.Request)
+	HandleUpdateAccountStatus(w http.ResponseWriter, r *http.Request)
 }
 
 func NewAccountHandler(accountService service.AccountService) AccountHandler {
@@ -35,24 +36,26 @@ func NewAccountHandler(accountService service.AccountService) AccountHandler {
 // @Produce json
 // @Param accountID path uint true "Account ID"
 // @Success 200 {object} api.GetAccountResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
 // @Router /accounts/{accountID} [get]
 func (a *accountHandler) HandleGetAccount(w http.ResponseWriter, r *http.Request) {
 	accountIDParam := chi.URLParam(r, "accountID")
 	accountID, err := strconv.ParseInt(accountIDParam, 10, 64)
 	if err != nil {
-		HandleError(w, internalErrors.NewValidationError("Invalid Operation ID"))
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Operation ID"))
 		return
 	}
 
-	account, err := a.accountService.GetAccountById(accountID)
+	account, err := a.accountService.GetAccountById(r.Context(), accountID)
 	if err != nil {
-		log.WithField("accountID", accountID).WithError(err).Error("Error getting account")
+		logging.FromContext(r.Context()).WithField("accountID", accountID).WithError(err).Error("Error getting account")
 		_, ok := err.(CustomError)
 		if ok {
-			HandleError(w, err)
+			HandleError(w, r, err)
 			return
 		}
-		HandleError(w, internalErrors.NewUnknownError("Error getting account"))
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting account"))
 		return
 	}
 
@@ -70,44 +73,89 @@ func (a *accountHandler) HandleGetAccount(w http.ResponseWriter, r *http.Request
 // @Accept json
 // @Produce json
 // @Param account body api.CreateAccountRequest true "Request body"
+// @Param Idempotency-Key header string false "Makes retries of this request safe to send"
 // @Success 200 {object} api.CreateAccountResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
 // @Router /accounts [post]
 func (a *accountHandler) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var requestBody api.CreateAccountRequest
 
-	err := json.NewDecoder(r.Body).Decode(&requestBody)
+	err := decodeAndValidate(r, &requestBody)
 	if err != nil {
-		log.WithError(err).Error("Error parsing request body")
-		HandleError(w, internalErrors.NewValidationError("Invalid request body"))
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
 		return
 	}
 
-	err = validator.New().Struct(requestBody)
+	account := mapper.ToAccount(requestBody)
+
+	account, err = a.accountService.CreateAccount(r.Context(), account)
+
 	if err != nil {
-		log.WithError(err).Error("Error validating request body")
-		HandleError(w, internalErrors.NewValidationError("Invalid request body"))
+		logging.FromContext(r.Context()).WithError(err).Error("Error creating account")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error creating account"))
 		return
 	}
 
-	account := mapper.ToAccount(requestBody)
+	response := mapper.ToCreateAccountResponse(account)
 
-	account, err = a.accountService.CreateAccount(account)
+	w.WriteHeader(http.StatusCreated)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
 
+// HandleUpdateAccountStatus
+// @Summary Changes the status of an account
+// @Description This endpoint blocks, reactivates or closes an account. Closed accounts cannot be reopened, and only accounts with a zero balance can be closed
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param accountID path uint true "Account ID"
+// @Param status body api.UpdateAccountStatusRequest true "Request body"
+// @Success 200 {object} api.GetAccountResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /accounts/{accountID}/status [patch]
+func (a *accountHandler) HandleUpdateAccountStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "accountID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Account ID"))
+		return
+	}
+
+	var requestBody api.UpdateAccountStatusRequest
+
+	err = decodeAndValidate(r, &requestBody)
 	if err != nil {
-		log.WithError(err).Error("Error creating account")
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
+		return
+	}
+
+	account, err := a.accountService.UpdateAccountStatus(r.Context(), accountID, model.AccountStatus(requestBody.Status))
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("accountID", accountID).WithError(err).Error("Error updating account status")
 		_, ok := err.(CustomError)
 		if ok {
-			HandleError(w, err)
+			HandleError(w, r, err)
 			return
 		}
-		HandleError(w, internalErrors.NewUnknownError("Error creating account"))
+		HandleError(w, r, internalErrors.NewUnknownError("Error updating account status"))
 		return
 	}
 
-	response := mapper.ToCreateAccountResponse(account)
+	response := mapper.ToGetAccountResponse(account)
 
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusOK)
 
 	_ = json.NewEncoder(w).Encode(response)
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/gmerten/accounts_transactions/internal/model"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAccountHandler_CreateAccountSuccess(t *testing.T) {
@@ -21,11 +22,11 @@ func TestAccountHandler_CreateAccountSuccess(t *testing.T) {
 	handler := NewAccountHandler(mockService)
 
 	createAccountRequest := dto.CreateAccountRequest{
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	account := &model.Account{
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	mockService.On("CreateAccount", account).Return(account, nil)
@@ -65,16 +66,40 @@ func TestAccountHandler_CreateAccountInvalidJSONError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
 
+func TestAccountHandler_CreateAccountInvalidFields(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"document_number": "12345678900", "closing_day": 31}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateAccount(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	problem := decodeProblem(t, rr)
+	assert.Equal(t, "VALIDATION_FAILED", problem.Code)
+	assert.Equal(t, []FieldViolation{
+		{Field: "document_number", Rule: "document", Message: "document_number must be a valid CPF or CNPJ"},
+		{Field: "closing_day", Rule: "lte", Param: "28", Message: "closing_day must be less than or equal to 28"},
+	}, problem.Violations)
+	mockService.AssertNotCalled(t, "CreateAccount", mock.Anything)
+}
+
 func TestAccountHandler_CreateAccountError(t *testing.T) {
 	mockService := new(MockAccountService)
 	handler := NewAccountHandler(mockService)
 
 	createAccountRequest := dto.CreateAccountRequest{
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	account := &model.Account{
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	mockService.On("CreateAccount", account).Return(nil, errors.New("error creating account"))
@@ -119,19 +144,112 @@ func TestAccountHandler_CreateAccountInvalidRequest(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAccountHandler_CreateAccountInvalidDocumentNumberError(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	for _, documentNumber := range []string{"abc", "123.456.789-00", "11.222.333/0001-82"} {
+		createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: documentNumber})
+
+		req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+
+		handler.HandleCreateAccount(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, documentNumber)
+	}
+
+	mockService.AssertNotCalled(t, "CreateAccount", mock.Anything)
+}
+
+func TestAccountHandler_CreateAccountWithCreditLimitSuccess(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	availableCreditLimit := model.MustParseMoney("5000.00")
+
+	account := &model.Account{
+		DocumentNumber:       "12345678909",
+		AvailableCreditLimit: &availableCreditLimit,
+	}
+
+	mockService.On("CreateAccount", account).Return(account, nil)
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"document_number": "12345678909", "available_credit_limit": 5000}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateAccount(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rr.Body).Decode(&returnedAccount)
+
+	assert.Equal(t, availableCreditLimit, *returnedAccount.AvailableCreditLimit)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAccountHandler_CreateAccountNegativeCreditLimitError(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"document_number": "12345678909", "available_credit_limit": -1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateAccount(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccountHandler_CreateAccountCreditLimitTooLargeError(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"document_number": "12345678909", "available_credit_limit": 100000000}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateAccount(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"rule":"money"`)
+	mockService.AssertExpectations(t)
+}
+
 func TestAccountHandler_CreateAccountCustomError(t *testing.T) {
 	mockService := new(MockAccountService)
 	handler := NewAccountHandler(mockService)
 
 	createAccountRequest := dto.CreateAccountRequest{
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	account := &model.Account{
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
-	mockService.On("CreateAccount", account).Return(nil, internalErrors.NewConflictError("account already exists"))
+	mockService.On("CreateAccount", account).Return(nil, internalErrors.NewConflictError(internalErrors.DuplicateDocument, "account already exists"))
 
 	createAccountJSON, _ := json.Marshal(createAccountRequest)
 
@@ -155,7 +273,7 @@ func TestAccountHandler_GetAccountSuccess(t *testing.T) {
 
 	account := &model.Account{
 		ID:             1,
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	mockService.On("GetAccountById", int64(1)).Return(account, nil)
@@ -213,7 +331,7 @@ func TestAccountHandler_GetAccountCustomError(t *testing.T) {
 	mockService := new(MockAccountService)
 	handler := NewAccountHandler(mockService)
 
-	mockService.On("GetAccountById", int64(1)).Return(nil, internalErrors.NewNotFoundError("account not found"))
+	mockService.On("GetAccountById", int64(1)).Return(nil, internalErrors.NewNotFoundError(internalErrors.AccountNotFound, "account not found"))
 
 	req, err := http.NewRequest("GET", "/accounts/1", nil)
 	if err != nil {
@@ -260,3 +378,88 @@ func TestAccountHandler_GetAccountInternalServerError(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestAccountHandler_UpdateAccountStatusSuccess(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678909",
+		Status:         model.AccountBlocked,
+	}
+
+	mockService.On("UpdateAccountStatus", int64(1), model.AccountBlocked).Return(account, nil)
+
+	req, err := http.NewRequest("PATCH", "/accounts/1/status", bytes.NewBufferString(`{"status": "blocked"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleUpdateAccountStatus(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var returnedAccount dto.GetAccountResponse
+	_ = json.NewDecoder(rr.Body).Decode(&returnedAccount)
+
+	assert.Equal(t, "blocked", returnedAccount.Status)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAccountHandler_UpdateAccountStatusInvalidStatusError(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	req, err := http.NewRequest("PATCH", "/accounts/1/status", bytes.NewBufferString(`{"status": "frozen"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleUpdateAccountStatus(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertNotCalled(t, "UpdateAccountStatus", mock.Anything, mock.Anything)
+}
+
+func TestAccountHandler_UpdateAccountStatusCustomError(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	mockService.On("UpdateAccountStatus", int64(1), model.AccountClosed).
+		Return(nil, internalErrors.NewUnprocessableEntityError(internalErrors.AccountBalanceNotZero, "Account can only be closed with a zero balance"))
+
+	req, err := http.NewRequest("PATCH", "/accounts/1/status", bytes.NewBufferString(`{"status": "closed"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleUpdateAccountStatus(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	api "github.com/gmerten/accounts_transactions/api/dto"
+	"github.com/gmerten/accounts_transactions/api/mapper"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type apiClientHandler struct {
+	apiClientService service.APIClientService
+}
+
+type APIClientHandler interface {
+	HandleCreateAPIClient(w http.ResponseWriter, r *http.Request)
+	HandleListAPIClients(w http.ResponseWriter, r *http.Request)
+	HandleGetAPIClient(w http.ResponseWriter, r *http.Request)
+	HandleEnableAPIClient(w http.ResponseWriter, r *http.Request)
+	HandleDisableAPIClient(w http.ResponseWriter, r *http.Request)
+	HandleRotateAPIClientKey(w http.ResponseWriter, r *http.Request)
+	HandleRevokeAPIClient(w http.ResponseWriter, r *http.Request)
+}
+
+func NewAPIClientHandler(apiClientService service.APIClientService) APIClientHandler {
+	return &apiClientHandler{apiClientService}
+}
+
+// HandleCreateAPIClient
+// @Summary Issues an API key
+// @Description This endpoint registers an API client with the given scopes and issues its key. The key is only returned here and when it is rotated, so store it safely
+// @Tags api-clients
+// @Accept json
+// @Produce json
+// @Param client body api.CreateAPIClientRequest true "Request body"
+// @Success 201 {object} api.APIClientKeyResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/api-clients [post]
+func (h *apiClientHandler) HandleCreateAPIClient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var requestBody api.CreateAPIClientRequest
+
+	err := decodeAndValidate(r, &requestBody)
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
+		return
+	}
+
+	client, key, err := h.apiClientService.IssueClient(r.Context(), mapper.ToAPIClient(requestBody))
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error issuing API client")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error issuing API client"))
+		return
+	}
+
+	response := mapper.ToAPIClientKeyResponse(client, key)
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleListAPIClients
+// @Summary List the API clients
+// @Description This endpoint lists every API client, enabled or not, without their keys
+// @Tags api-clients
+// @Produce json
+// @Success 200 {object} api.ListAPIClientsResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/api-clients [get]
+func (h *apiClientHandler) HandleListAPIClients(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	clients, err := h.apiClientService.ListClients(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error listing API clients")
+		HandleError(w, r, internalErrors.NewUnknownError("Error listing API clients"))
+		return
+	}
+
+	response := mapper.ToListAPIClientsResponse(clients)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetAPIClient
+// @Summary Get an API client
+// @Tags api-clients
+// @Produce json
+// @Param clientID path uint true "API client ID"
+// @Success 200 {object} api.APIClientResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/api-clients/{clientID} [get]
+func (h *apiClientHandler) HandleGetAPIClient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	clientID, err := strconv.ParseInt(chi.URLParam(r, "clientID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid API client ID"))
+		return
+	}
+
+	client, err := h.apiClientService.GetClient(r.Context(), clientID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("apiClientID", clientID).WithError(err).Error("Error getting API client")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting API client"))
+		return
+	}
+
+	response := mapper.ToAPIClientResponse(client)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleEnableAPIClient
+// @Summary Enables an API client
+// @Description This endpoint accepts the key of a disabled API client again
+// @Tags api-clients
+// @Produce json
+// @Param clientID path uint true "API client ID"
+// @Success 200 {object} api.APIClientResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/api-clients/{clientID}/enable [post]
+func (h *apiClientHandler) HandleEnableAPIClient(w http.ResponseWriter, r *http.Request) {
+	h.setClientActive(w, r, true)
+}
+
+// HandleDisableAPIClient
+// @Summary Disables an API client
+// @Description This endpoint rejects the key of an API client until it is enabled again
+// @Tags api-clients
+// @Produce json
+// @Param clientID path uint true "API client ID"
+// @Success 200 {object} api.APIClientResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/api-clients/{clientID}/disable [post]
+func (h *apiClientHandler) HandleDisableAPIClient(w http.ResponseWriter, r *http.Request) {
+	h.setClientActive(w, r, false)
+}
+
+func (h *apiClientHandler) setClientActive(w http.ResponseWriter, r *http.Request, active bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	clientID, err := strconv.ParseInt(chi.URLParam(r, "clientID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid API client ID"))
+		return
+	}
+
+	client, err := h.apiClientService.SetClientActive(r.Context(), clientID, active)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("apiClientID", clientID).WithError(err).Error("Error updating API client")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error updating API client"))
+		return
+	}
+
+	response := mapper.ToAPIClientResponse(client)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleRotateAPIClientKey
+// @Summary Rotates the key of an API client
+// @Description This endpoint issues a new key for an API client. The previous key stops working at once
+// @Tags api-clients
+// @Produce json
+// @Param clientID path uint true "API client ID"
+// @Success 200 {object} api.APIClientKeyResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/api-clients/{clientID}/rotate [post]
+func (h *apiClientHandler) HandleRotateAPIClientKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	clientID, err := strconv.ParseInt(chi.URLParam(r, "clientID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid API client ID"))
+		return
+	}
+
+	client, key, err := h.apiClientService.RotateKey(r.Context(), clientID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("apiClientID", clientID).WithError(err).Error("Error rotating API key")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error rotating API key"))
+		return
+	}
+
+	response := mapper.ToAPIClientKeyResponse(client, key)
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleRevokeAPIClient
+// @Summary Revokes an API client
+// @Description This endpoint deletes an API client, so its key is rejected for good
+// @Tags api-clients
+// @Param clientID path uint true "API client ID"
+// @Success 204
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/api-clients/{clientID} [delete]
+func (h *apiClientHandler) HandleRevokeAPIClient(w http.ResponseWriter, r *http.Request) {
+	clientID, err := strconv.ParseInt(chi.URLParam(r, "clientID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid API client ID"))
+		return
+	}
+
+	err = h.apiClientService.RevokeClient(r.Context(), clientID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("apiClientID", clientID).WithError(err).Error("Error revoking API client")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error revoking API client"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
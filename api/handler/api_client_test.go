@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testAPIKey = "ak_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func withClientID(req *http.Request, clientID string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("clientID", clientID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
+
+func TestAPIClientHandler_CreateAPIClientSuccess(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	handler := NewAPIClientHandler(mockService)
+
+	mockService.On("IssueClient", mock.MatchedBy(func(client *model.APIClient) bool {
+		return client.Name == "billing" && len(client.Scopes) == 2 &&
+			client.Scopes[0] == model.ScopeAccountsRead && client.Scopes[1] == model.ScopeTransactionsWrite
+	})).Return(&model.APIClient{
+		ID:        1,
+		Name:      "billing",
+		KeyPrefix: "ak_01234567",
+		KeyHash:   "hash",
+		Scopes:    model.Scopes{model.ScopeAccountsRead, model.ScopeTransactionsWrite},
+		Active:    true,
+	}, testAPIKey, nil)
+
+	req, err := http.NewRequest("POST", "/admin/api-clients", bytes.NewBufferString(`{"name":"billing","scopes":["accounts:read","transactions:write"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateAPIClient(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.NotContains(t, rr.Body.String(), "hash")
+
+	var response dto.APIClientKeyResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, int64(1), response.ID)
+	assert.Equal(t, testAPIKey, response.Key)
+	assert.Equal(t, "ak_01234567", response.KeyPrefix)
+	assert.Equal(t, []string{"accounts:read", "transactions:write"}, response.Scopes)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAPIClientHandler_CreateAPIClientValidationError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing name", body: `{"scopes":["admin"]}`},
+		{name: "missing scopes", body: `{"name":"billing"}`},
+		{name: "no scopes", body: `{"name":"billing","scopes":[]}`},
+		{name: "unknown scope", body: `{"name":"billing","scopes":["accounts:delete"]}`},
+		{name: "client chosen key", body: `{"name":"billing","scopes":["admin"],"key":"` + testAPIKey + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIClientService)
+			handler := NewAPIClientHandler(mockService)
+
+			req, err := http.NewRequest("POST", "/admin/api-clients", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.HandleCreateAPIClient(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "IssueClient", mock.Anything)
+		})
+	}
+}
+
+func TestAPIClientHandler_ListAPIClientsSuccess(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	handler := NewAPIClientHandler(mockService)
+
+	mockService.On("ListClients").Return([]model.APIClient{
+		{ID: 1, Name: "billing", KeyPrefix: "ak_01234567", KeyHash: "hash", Scopes: model.Scopes{model.ScopeAdmin}, Active: true},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/admin/api-clients", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListAPIClients(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), `"key"`)
+	assert.NotContains(t, rr.Body.String(), "hash")
+
+	var response dto.ListAPIClientsResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Len(t, response.Clients, 1)
+	assert.Equal(t, []string{"admin"}, response.Clients[0].Scopes)
+}
+
+func TestAPIClientHandler_GetAPIClientNotFound(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	handler := NewAPIClientHandler(mockService)
+
+	mockService.On("GetClient", int64(2)).Return(nil, internalErrors.NewNotFoundError(internalErrors.APIClientNotFound, "API client not found"))
+
+	req, err := http.NewRequest("GET", "/admin/api-clients/2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetAPIClient(rr, withClientID(req, "2"))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "API_CLIENT_NOT_FOUND", decodeProblem(t, rr).Code)
+}
+
+func TestAPIClientHandler_EnableAndDisableAPIClient(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	handler := NewAPIClientHandler(mockService)
+
+	mockService.On("SetClientActive", int64(1), false).Return(&model.APIClient{ID: 1, Active: false}, nil)
+	mockService.On("SetClientActive", int64(1), true).Return(&model.APIClient{ID: 1, Active: true}, nil)
+
+	req, err := http.NewRequest("POST", "/admin/api-clients/1/disable", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleDisableAPIClient(rr, withClientID(req, "1"))
+
+	var response dto.APIClientResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, response.Active)
+
+	rr = httptest.NewRecorder()
+	handler.HandleEnableAPIClient(rr, withClientID(req, "1"))
+
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, response.Active)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAPIClientHandler_RotateAPIClientKey(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	handler := NewAPIClientHandler(mockService)
+
+	mockService.On("RotateKey", int64(1)).Return(&model.APIClient{ID: 1, KeyPrefix: "ak_01234567", Active: true}, testAPIKey, nil)
+
+	req, err := http.NewRequest("POST", "/admin/api-clients/1/rotate", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleRotateAPIClientKey(rr, withClientID(req, "1"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+	var response dto.APIClientKeyResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, testAPIKey, response.Key)
+}
+
+func TestAPIClientHandler_RevokeAPIClient(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	handler := NewAPIClientHandler(mockService)
+
+	mockService.On("RevokeClient", int64(1)).Return(nil)
+
+	req, err := http.NewRequest("DELETE", "/admin/api-clients/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleRevokeAPIClient(rr, withClientID(req, "1"))
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAPIClientHandler_RevokeAPIClientInternalServerError(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	handler := NewAPIClientHandler(mockService)
+
+	mockService.On("RevokeClient", int64(1)).Return(errors.New("database error"))
+
+	req, err := http.NewRequest("DELETE", "/admin/api-clients/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleRevokeAPIClient(rr, withClientID(req, "1"))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "database error")
+}
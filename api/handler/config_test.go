@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+
 	"github.com/gmerten/accounts_transactions/internal/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,7 +15,7 @@ type MockTransactionService struct {
 	mock.Mock
 }
 
-func (m *MockAccountService) CreateAccount(account *model.Account) (*model.Account, error) {
+func (m *MockAccountService) CreateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
 	args := m.Called(account)
 	res := args.Get(0)
 	err := args.Error(1)
@@ -24,7 +26,7 @@ func (m *MockAccountService) CreateAccount(account *model.Account) (*model.Accou
 	return res.(*model.Account), err
 }
 
-func (m *MockAccountService) GetAccountById(accountId int64) (*model.Account, error) {
+func (m *MockAccountService) GetAccountById(ctx context.Context, accountId int64) (*model.Account, error) {
 	args := m.Called(accountId)
 	res := args.Get(0)
 	err := args.Error(1)
@@ -36,8 +38,20 @@ func (m *MockAccountService) GetAccountById(accountId int64) (*model.Account, er
 	return res.(*model.Account), err
 }
 
-func (m *MockTransactionService) CreateTransaction(transaction *model.Transaction) (*model.Transaction, error) {
-	args := m.Called(transaction)
+func (m *MockAccountService) UpdateAccountStatus(ctx context.Context, accountId int64, status model.AccountStatus) (*model.Account, error) {
+	args := m.Called(accountId, status)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*model.Account), err
+}
+
+func (m *MockTransactionService) CreateTransaction(ctx context.Context, transaction *model.Transaction, installmentCount int) (*model.Transaction, error) {
+	args := m.Called(transaction, installmentCount)
 
 	res := args.Get(0)
 	err := args.Error(1)
@@ -47,3 +61,295 @@ func (m *MockTransactionService) CreateTransaction(transaction *model.Transactio
 	}
 	return res.(*model.Transaction), err
 }
+
+func (m *MockTransactionService) ListTransactions(ctx context.Context, filter model.TransactionFilter) (*model.TransactionPage, error) {
+	args := m.Called(filter)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.TransactionPage), err
+}
+
+type MockOperationTypeService struct {
+	mock.Mock
+}
+
+func (m *MockOperationTypeService) CreateOperationType(ctx context.Context, operationType *model.OperationTypeDefinition) (*model.OperationTypeDefinition, error) {
+	args := m.Called(operationType)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.OperationTypeDefinition), err
+}
+
+func (m *MockOperationTypeService) ListOperationTypes(ctx context.Context) ([]model.OperationTypeDefinition, error) {
+	args := m.Called()
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.OperationTypeDefinition), err
+}
+
+func (m *MockOperationTypeService) GetActiveOperationType(ctx context.Context, id model.OperationType) (*model.OperationTypeDefinition, error) {
+	args := m.Called(id)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.OperationTypeDefinition), err
+}
+
+func (m *MockTransactionService) ReverseTransaction(ctx context.Context, transactionID int64, amount *model.Money) (*model.Transaction, *model.Transaction, error) {
+	args := m.Called(transactionID, amount)
+
+	err := args.Error(2)
+
+	if err != nil {
+		return nil, nil, err
+	}
+	return args.Get(0).(*model.Transaction), args.Get(1).(*model.Transaction), err
+}
+
+type MockInstallmentService struct {
+	mock.Mock
+}
+
+func (m *MockInstallmentService) GetInstallmentPlan(ctx context.Context, transactionID int64) (*model.InstallmentPlan, error) {
+	args := m.Called(transactionID)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.InstallmentPlan), err
+}
+
+func (m *MockInstallmentService) GetInstallment(ctx context.Context, transactionID int64, number int) (*model.Installment, error) {
+	args := m.Called(transactionID, number)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.Installment), err
+}
+
+type MockStatementService struct {
+	mock.Mock
+}
+
+func (m *MockStatementService) CloseDueStatements(ctx context.Context, accountID int64) error {
+	args := m.Called(accountID)
+	return args.Error(0)
+}
+
+func (m *MockStatementService) CloseAllDueStatements(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStatementService) ListStatements(ctx context.Context, accountID int64) ([]model.Statement, error) {
+	args := m.Called(accountID)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.Statement), err
+}
+
+func (m *MockStatementService) GetStatement(ctx context.Context, accountID int64, period string) (*model.Statement, error) {
+	args := m.Called(accountID, period)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.Statement), err
+}
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := m.Called(subscription)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.WebhookSubscription), err
+}
+
+func (m *MockWebhookService) GetWebhook(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	args := m.Called(id)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.WebhookSubscription), err
+}
+
+func (m *MockWebhookService) ListWebhooks(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called()
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.WebhookSubscription), err
+}
+
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := m.Called(subscription)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.WebhookSubscription), err
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, id int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(id, status, limit)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.WebhookDelivery), err
+}
+
+func (m *MockWebhookService) RedriveDeadLetters(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockOutboxRelay struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxRelay) RedriveDeadLetters(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockHealthService struct {
+	mock.Mock
+}
+
+func (m *MockHealthService) Readiness(ctx context.Context) *model.Readiness {
+	args := m.Called()
+	return args.Get(0).(*model.Readiness)
+}
+
+type MockAPIClientService struct {
+	mock.Mock
+}
+
+func (m *MockAPIClientService) IssueClient(ctx context.Context, client *model.APIClient) (*model.APIClient, string, error) {
+	args := m.Called(client)
+	res := args.Get(0)
+	err := args.Error(2)
+
+	if err != nil {
+		return nil, "", err
+	}
+	return res.(*model.APIClient), args.String(1), err
+}
+
+func (m *MockAPIClientService) GetClient(ctx context.Context, id int64) (*model.APIClient, error) {
+	args := m.Called(id)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
+
+func (m *MockAPIClientService) ListClients(ctx context.Context) ([]model.APIClient, error) {
+	args := m.Called()
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.APIClient), err
+}
+
+func (m *MockAPIClientService) SetClientActive(ctx context.Context, id int64, active bool) (*model.APIClient, error) {
+	args := m.Called(id, active)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
+
+func (m *MockAPIClientService) RotateKey(ctx context.Context, id int64) (*model.APIClient, string, error) {
+	args := m.Called(id)
+	res := args.Get(0)
+	err := args.Error(2)
+
+	if err != nil {
+		return nil, "", err
+	}
+	return res.(*model.APIClient), args.String(1), err
+}
+
+func (m *MockAPIClientService) RevokeClient(ctx context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAPIClientService) Authenticate(ctx context.Context, key string) (*model.APIClient, error) {
+	args := m.Called(key)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
@@ -4,35 +4,89 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
 )
 
-type ErrorResponse struct {
-	StatusCode int    `json:"status"`
-	Message    string `json:"message"`
+// ProblemContentType is the media type of the error responses, from RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix makes the code of an error the URI of its problem type.
+const problemTypePrefix = "urn:accounts-transactions:problem:"
+
+// Problem is the body of every error response, an RFC 7807 problem details
+// object extended with the stable code of the error and the ID of the request,
+// to quote when reporting it.
+type Problem struct {
+	Type   string `json:"type" example:"urn:accounts-transactions:problem:ACCOUNT_NOT_FOUND"`
+	Title  string `json:"title" example:"The account was not found"`
+	Status int    `json:"status" example:"404"`
+	Detail string `json:"detail" example:"Account not found"`
+	// Instance is the path of the request that failed.
+	Instance string `json:"instance" example:"/accounts/42"`
+	// Code identifies the error. Clients should branch on it rather than on
+	// the detail, which may be reworded.
+	Code      string `json:"code" example:"ACCOUNT_NOT_FOUND" enums:"ACCOUNT_BALANCE_NOT_ZERO,ACCOUNT_NOT_ACTIVE,ACCOUNT_NOT_FOUND,API_CLIENT_NOT_FOUND,API_KEY_REQUIRED,CONFLICT,CREDIT_LIMIT_EXCEEDED,DUPLICATE_DOCUMENT,DUPLICATE_OPERATION_TYPE,FORBIDDEN,IDEMPOTENCY_KEY_IN_USE,IDEMPOTENCY_KEY_REUSED,INSTALLMENT_NOT_FOUND,INSTALLMENT_PLAN_NOT_FOUND,INSUFFICIENT_SCOPE,INTERNAL_ERROR,INVALID_API_KEY,INVALID_DOCUMENT_NUMBER,INVALID_IDEMPOTENCY_KEY,INVALID_INSTALLMENTS,INVALID_OPERATION_TYPE,INVALID_PARAMETER,INVALID_PERIOD,INVALID_REQUEST_BODY,INVALID_REVERSAL_AMOUNT,INVALID_STATUS_TRANSITION,NOT_FOUND,OPERATION_TYPE_NOT_ACTIVE,REVERSAL_NOT_ALLOWED,STATEMENT_NOT_FOUND,TRANSACTION_ALREADY_REVERSED,TRANSACTION_NOT_FOUND,UNAUTHORIZED,UNPROCESSABLE_ENTITY,VALIDATION_FAILED,WEBHOOK_NOT_FOUND"`
+	RequestID string `json:"request_id,omitempty" example:"5f0c6d9e2b7a41c38e1d0f6a9b2c4e71"`
+	// Violations lists the invalid fields of the request body, if any.
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// FieldViolation is a field of the request body that failed a validation rule.
+type FieldViolation struct {
+	// Field is the path of the field in the request body, such as
+	// event_types[0].
+	Field   string `json:"field" example:"amount"`
+	Rule    string `json:"rule" example:"gte"`
+	Param   string `json:"param,omitempty" example:"0"`
+	Message string `json:"message" example:"amount must be greater than or equal to 0"`
 }
 
 type CustomError interface {
 	StatusCode() int
 }
 
-func HandleError(w http.ResponseWriter, err error) {
+// CodedError is a CustomError that also carries a machine readable code, so
+// clients can tell apart failures that share a status code.
+type CodedError interface {
+	CustomError
+	Code() internalErrors.Code
+}
 
-	w.Header().Set("Content-Type", "application/json")
+// HandleError answers the request with the problem details of err. Errors
+// that are not CustomErrors are unexpected, and their message is not sent to
+// the client.
+func HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := Problem{
+		Status:    http.StatusInternalServerError,
+		Detail:    internalErrors.InternalError.Title(),
+		Instance:  r.URL.Path,
+		Code:      string(internalErrors.InternalError),
+		RequestID: logging.RequestID(r.Context()),
+	}
 
 	var customErr CustomError
 	if errors.As(err, &customErr) {
-		w.WriteHeader(customErr.StatusCode())
-		_ = json.NewEncoder(w).Encode(ErrorResponse{
-			StatusCode: customErr.StatusCode(),
-			Message:    err.Error(),
-		})
-		return
+		problem.Status = customErr.StatusCode()
+		problem.Detail = err.Error()
 	}
 
-	w.WriteHeader(http.StatusInternalServerError)
-	_ = json.NewEncoder(w).Encode(ErrorResponse{
-		StatusCode: http.StatusInternalServerError,
-		Message:    err.Error(),
-	})
+	var validationErr internalErrors.ValidationError
+	if errors.As(err, &validationErr) {
+		for _, violation := range validationErr.Violations {
+			problem.Violations = append(problem.Violations, FieldViolation(violation))
+		}
+	}
+
+	var codedErr CodedError
+	if errors.As(err, &codedErr) {
+		problem.Code = string(codedErr.Code())
+	}
+	problem.Type = problemTypePrefix + problem.Code
+	problem.Title = internalErrors.Code(problem.Code).Title()
 
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
 }
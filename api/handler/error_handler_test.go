@@ -1,15 +1,29 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
 	"github.com/stretchr/testify/assert"
 )
 
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) Problem {
+	assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+
+	var problem Problem
+	if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
 func TestHandleError_CustomError(t *testing.T) {
 	customErr := internalErrors.ValidationError{
 		Message: "Invalid request",
@@ -17,16 +31,63 @@ func TestHandleError_CustomError(t *testing.T) {
 
 	rr := httptest.NewRecorder()
 
-	HandleError(rr, customErr)
+	HandleError(rr, httptest.NewRequest("POST", "/accounts", http.NoBody), customErr)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	problem := decodeProblem(t, rr)
+	assert.Equal(t, "VALIDATION_FAILED", problem.Code)
+	assert.Equal(t, "Invalid request", problem.Detail)
+}
+
+func TestHandleError_CreditLimitExceededError(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	HandleError(rr, httptest.NewRequest("POST", "/transactions", http.NoBody), internalErrors.NewCreditLimitExceededError("Transaction exceeds the available credit limit"))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, "CREDIT_LIMIT_EXCEEDED", decodeProblem(t, rr).Code)
 }
 
 func TestHandleError_GenericError(t *testing.T) {
-	genericErr := errors.New("something went wrong")
+	genericErr := errors.New("dial tcp 10.0.0.5:3306: connection refused")
 	rr := httptest.NewRecorder()
 
-	HandleError(rr, genericErr)
+	HandleError(rr, httptest.NewRequest("GET", "/accounts/1", http.NoBody), genericErr)
 
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	problem := decodeProblem(t, rr)
+	assert.Equal(t, "INTERNAL_ERROR", problem.Code)
+	assert.NotContains(t, problem.Detail, "10.0.0.5")
+}
+
+func TestHandleError_Problem(t *testing.T) {
+	req := httptest.NewRequest("GET", "/accounts/42", http.NoBody)
+	req = req.WithContext(logging.WithRequestID(req.Context(), "5f0c6d9e"))
+	rr := httptest.NewRecorder()
+
+	HandleError(rr, req, internalErrors.NewNotFoundError(internalErrors.AccountNotFound, "Account not found"))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, Problem{
+		Type:      "urn:accounts-transactions:problem:ACCOUNT_NOT_FOUND",
+		Title:     "The account was not found",
+		Status:    http.StatusNotFound,
+		Detail:    "Account not found",
+		Instance:  "/accounts/42",
+		Code:      "ACCOUNT_NOT_FOUND",
+		RequestID: "5f0c6d9e",
+	}, decodeProblem(t, rr))
+}
+
+// The catalog of codes is published in the OpenAPI documentation through the
+// enums of Problem.Code, which must list every code.
+func TestProblem_CodeCatalog(t *testing.T) {
+	field, _ := reflect.TypeOf(Problem{}).FieldByName("Code")
+
+	var codes []string
+	for _, code := range internalErrors.Codes() {
+		codes = append(codes, string(code))
+	}
+
+	assert.Equal(t, strings.Join(codes, ","), field.Tag.Get("enums"))
 }
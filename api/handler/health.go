@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	api "github.com/gmerten/accounts_transactions/api/dto"
+	"github.com/gmerten/accounts_transactions/api/mapper"
+	"github.com/gmerten/accounts_transactions/internal/service"
+)
+
+type healthHandler struct {
+	healthService service.HealthService
+}
+
+type HealthHandler interface {
+	HandleLiveness(w http.ResponseWriter, r *http.Request)
+	HandleReadiness(w http.ResponseWriter, r *http.Request)
+}
+
+func NewHealthHandler(healthService service.HealthService) HealthHandler {
+	return &healthHandler{healthService}
+}
+
+// HandleLiveness
+// @Summary Liveness probe
+// @Description This endpoint answers as long as the process is serving requests, without checking its dependencies
+// @Tags health
+// @Produce json
+// @Success 200 {object} api.HealthResponse
+// @Router /healthz [get]
+func (h *healthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(api.HealthResponse{Status: "ok"})
+}
+
+// HandleReadiness
+// @Summary Readiness probe
+// @Description This endpoint checks that the database is reachable and that its schema is at the latest migration. It answers 503 with the failing checks otherwise
+// @Tags health
+// @Produce json
+// @Success 200 {object} api.HealthResponse
+// @Failure 503 {object} api.HealthResponse
+// @Router /readyz [get]
+func (h *healthHandler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	readiness := h.healthService.Readiness(r.Context())
+
+	if readiness.Ready() {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(w).Encode(mapper.ToHealthResponse(readiness))
+}
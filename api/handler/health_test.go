@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	mockService := new(MockHealthService)
+	handler := NewHealthHandler(mockService)
+
+	req, err := http.NewRequest("GET", "/healthz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleLiveness(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+
+	mockService.AssertNotCalled(t, "Readiness")
+}
+
+func TestHealthHandler_ReadinessReady(t *testing.T) {
+	mockService := new(MockHealthService)
+	handler := NewHealthHandler(mockService)
+
+	mockService.On("Readiness").Return(&model.Readiness{Checks: []model.HealthCheck{
+		{Name: "database"},
+		{Name: "migrations"},
+	}})
+
+	req, err := http.NewRequest("GET", "/readyz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleReadiness(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.HealthResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, "ok", response.Status)
+	assert.Equal(t, map[string]string{"database": "ok", "migrations": "ok"}, response.Checks)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHealthHandler_ReadinessNotReady(t *testing.T) {
+	mockService := new(MockHealthService)
+	handler := NewHealthHandler(mockService)
+
+	mockService.On("Readiness").Return(&model.Readiness{Checks: []model.HealthCheck{
+		{Name: "database"},
+		{Name: "migrations", Error: "schema is at version 14, expected 15"},
+	}})
+
+	req, err := http.NewRequest("GET", "/readyz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleReadiness(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	var response dto.HealthResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, "unavailable", response.Status)
+	assert.Equal(t, "schema is at version 14, expected 15", response.Checks["migrations"])
+
+	mockService.AssertExpectations(t)
+}
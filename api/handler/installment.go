@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gmerten/accounts_transactions/api/mapper"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type installmentHandler struct {
+	installmentService service.InstallmentService
+}
+
+type InstallmentHandler interface {
+	HandleGetInstallmentPlan(w http.ResponseWriter, r *http.Request)
+	HandleGetInstallment(w http.ResponseWriter, r *http.Request)
+}
+
+func NewInstallmentHandler(installmentService service.InstallmentService) InstallmentHandler {
+	return &installmentHandler{installmentService}
+}
+
+// HandleGetInstallmentPlan
+// @Summary Get the installment plan of a purchase
+// @Description This endpoint lists the installments of an installment purchase, with the due date and status of each one
+// @Tags transactions
+// @Produce json
+// @Param transactionID path uint true "Transaction ID"
+// @Success 200 {object} api.InstallmentPlanResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /transactions/{transactionID}/installments [get]
+func (i *installmentHandler) HandleGetInstallmentPlan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	transactionID, err := strconv.ParseInt(chi.URLParam(r, "transactionID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Transaction ID"))
+		return
+	}
+
+	plan, err := i.installmentService.GetInstallmentPlan(r.Context(), transactionID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("transactionID", transactionID).WithError(err).Error("Error getting installment plan")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting installment plan"))
+		return
+	}
+
+	response := mapper.ToInstallmentPlanResponse(plan)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetInstallment
+// @Summary Get an installment of a purchase
+// @Description This endpoint gets one installment of an installment purchase by its number, starting at 1
+// @Tags transactions
+// @Produce json
+// @Param transactionID path uint true "Transaction ID"
+// @Param number path uint true "Installment number"
+// @Success 200 {object} api.InstallmentResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /transactions/{transactionID}/installments/{number} [get]
+func (i *installmentHandler) HandleGetInstallment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	transactionID, err := strconv.ParseInt(chi.URLParam(r, "transactionID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Transaction ID"))
+		return
+	}
+
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil || number < 1 {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid installment number"))
+		return
+	}
+
+	installment, err := i.installmentService.GetInstallment(r.Context(), transactionID, number)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("transactionID", transactionID).WithError(err).Error("Error getting installment")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting installment"))
+		return
+	}
+
+	response := mapper.ToInstallmentResponse(installment)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInstallmentHandler_GetInstallmentPlanSuccess(t *testing.T) {
+	mockService := new(MockInstallmentService)
+	handler := NewInstallmentHandler(mockService)
+
+	transaction := &model.Transaction{
+		ID:              5,
+		AccountID:       1,
+		Amount:          model.MustParseMoney("-100.00"),
+		Balance:         model.MustParseMoney("-100.00"),
+		OperationType:   model.InstallmentPurchase,
+		TransactionDate: time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+	}
+	plan := model.NewInstallmentPlan(transaction, model.NewInstallments(transaction, 3), transaction.TransactionDate)
+
+	mockService.On("GetInstallmentPlan", int64(5)).Return(plan, nil)
+
+	req, err := http.NewRequest("GET", "/transactions/5/installments", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "5")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetInstallmentPlan(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.InstallmentPlanResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, int64(5), response.TransactionID)
+	assert.Equal(t, model.MustParseMoney("100.00"), response.TotalAmount)
+	assert.Equal(t, 3, response.InstallmentCount)
+	assert.Equal(t, model.MustParseMoney("33.34"), response.Installments[0].Amount)
+	assert.Equal(t, "scheduled", response.Installments[0].Status)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestInstallmentHandler_GetInstallmentPlanCustomError(t *testing.T) {
+	mockService := new(MockInstallmentService)
+	handler := NewInstallmentHandler(mockService)
+
+	mockService.On("GetInstallmentPlan", int64(5)).Return(nil, internalErrors.NewNotFoundError(internalErrors.InstallmentPlanNotFound, "Transaction has no installment plan"))
+
+	req, err := http.NewRequest("GET", "/transactions/5/installments", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "5")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetInstallmentPlan(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestInstallmentHandler_GetInstallmentPlanInternalServerError(t *testing.T) {
+	mockService := new(MockInstallmentService)
+	handler := NewInstallmentHandler(mockService)
+
+	mockService.On("GetInstallmentPlan", int64(5)).Return(nil, errors.New("db error"))
+
+	req, err := http.NewRequest("GET", "/transactions/5/installments", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "5")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetInstallmentPlan(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestInstallmentHandler_GetInstallmentSuccess(t *testing.T) {
+	mockService := new(MockInstallmentService)
+	handler := NewInstallmentHandler(mockService)
+
+	installment := &model.Installment{
+		TransactionID: 5,
+		Number:        2,
+		Amount:        model.MustParseMoney("33.33"),
+		DueDate:       time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC),
+		Status:        model.InstallmentPaid,
+	}
+
+	mockService.On("GetInstallment", int64(5), 2).Return(installment, nil)
+
+	req, err := http.NewRequest("GET", "/transactions/5/installments/2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "5")
+	routeCtx.URLParams.Add("number", "2")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetInstallment(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.InstallmentResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, 2, response.Number)
+	assert.Equal(t, "paid", response.Status)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestInstallmentHandler_GetInstallmentInvalidNumberError(t *testing.T) {
+	mockService := new(MockInstallmentService)
+	handler := NewInstallmentHandler(mockService)
+
+	req, err := http.NewRequest("GET", "/transactions/5/installments/0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "5")
+	routeCtx.URLParams.Add("number", "0")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetInstallment(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertNotCalled(t, "GetInstallment", mock.Anything, mock.Anything)
+}
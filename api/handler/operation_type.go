@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	api "github.com/gmerten/accounts_transactions/api/dto"
+	"github.com/gmerten/accounts_transactions/api/mapper"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/service"
+)
+
+type operationTypeHandler struct {
+	operationTypeService service.OperationTypeService
+}
+
+type OperationTypeHandler interface {
+	HandleListOperationTypes(w http.ResponseWriter, r *http.Request)
+	HandleCreateOperationType(w http.ResponseWriter, r *http.Request)
+}
+
+func NewOperationTypeHandler(operationTypeService service.OperationTypeService) OperationTypeHandler {
+	return &operationTypeHandler{operationTypeService}
+}
+
+// HandleListOperationTypes
+// @Summary List the operation types
+// @Description This endpoint lists every operation type of the catalog, active or not
+// @Tags admin
+// @Produce json
+// @Success 200 {array} api.OperationTypeResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/operation-types [get]
+func (o *operationTypeHandler) HandleListOperationTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	operationTypes, err := o.operationTypeService.ListOperationTypes(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error listing operation types")
+		HandleError(w, r, internalErrors.NewUnknownError("Error listing operation types"))
+		return
+	}
+
+	response := mapper.ToOperationTypesResponse(operationTypes)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleCreateOperationType
+// @Summary Creates a new operation type
+// @Description This endpoint adds an operation type to the catalog. Its sign decides whether amounts are debited or credited
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param operationType body api.CreateOperationTypeRequest true "Request body"
+// @Success 201 {object} api.OperationTypeResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/operation-types [post]
+func (o *operationTypeHandler) HandleCreateOperationType(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var requestBody api.CreateOperationTypeRequest
+
+	err := decodeAndValidate(r, &requestBody)
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
+		return
+	}
+
+	operationType := mapper.ToOperationType(requestBody)
+
+	operationType, err = o.operationTypeService.CreateOperationType(r.Context(), operationType)
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error creating operation type")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error creating operation type"))
+		return
+	}
+
+	response := mapper.ToOperationTypeResponse(operationType)
+
+	w.WriteHeader(http.StatusCreated)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
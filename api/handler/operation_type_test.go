@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationTypeHandler_ListOperationTypesSuccess(t *testing.T) {
+	mockService := new(MockOperationTypeService)
+	handler := NewOperationTypeHandler(mockService)
+
+	mockService.On("ListOperationTypes").Return(model.BuiltInOperationTypes, nil)
+
+	req, err := http.NewRequest("GET", "/admin/operation-types", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListOperationTypes(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var returnedOperationTypes []dto.OperationTypeResponse
+	_ = json.NewDecoder(rr.Body).Decode(&returnedOperationTypes)
+
+	assert.Len(t, returnedOperationTypes, 4)
+	assert.Equal(t, "credit", returnedOperationTypes[3].Sign)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestOperationTypeHandler_ListOperationTypesError(t *testing.T) {
+	mockService := new(MockOperationTypeService)
+	handler := NewOperationTypeHandler(mockService)
+
+	mockService.On("ListOperationTypes").Return(nil, errors.New("generic error"))
+
+	req, err := http.NewRequest("GET", "/admin/operation-types", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListOperationTypes(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestOperationTypeHandler_CreateOperationTypeSuccess(t *testing.T) {
+	mockService := new(MockOperationTypeService)
+	handler := NewOperationTypeHandler(mockService)
+
+	operationType := &model.OperationTypeDefinition{
+		Description: "Refund",
+		Sign:        model.Credit,
+		Active:      true,
+	}
+
+	createdOperationType := &model.OperationTypeDefinition{
+		ID:          5,
+		Description: "Refund",
+		Sign:        model.Credit,
+		Active:      true,
+	}
+
+	mockService.On("CreateOperationType", operationType).Return(createdOperationType, nil)
+
+	createOperationTypeJSON, _ := json.Marshal(dto.CreateOperationTypeRequest{
+		Description: "Refund",
+		Sign:        "credit",
+	})
+
+	req, err := http.NewRequest("POST", "/admin/operation-types", bytes.NewBuffer(createOperationTypeJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateOperationType(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var returnedOperationType dto.OperationTypeResponse
+	_ = json.NewDecoder(rr.Body).Decode(&returnedOperationType)
+
+	assert.Equal(t, int64(5), returnedOperationType.ID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestOperationTypeHandler_CreateOperationTypeInvalidRequest(t *testing.T) {
+	requests := []string{
+		`"invalid json"`,
+		`{"description": "Refund", "sign": "positive"}`,
+		`{"sign": "credit"}`,
+	}
+
+	for _, body := range requests {
+		mockService := new(MockOperationTypeService)
+		handler := NewOperationTypeHandler(mockService)
+
+		req, err := http.NewRequest("POST", "/admin/operation-types", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+
+		handler.HandleCreateOperationType(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestOperationTypeHandler_CreateOperationTypeCustomError(t *testing.T) {
+	mockService := new(MockOperationTypeService)
+	handler := NewOperationTypeHandler(mockService)
+
+	operationType := &model.OperationTypeDefinition{
+		ID:          1,
+		Description: "Purchase",
+		Sign:        model.Debit,
+		Active:      false,
+	}
+
+	mockService.On("CreateOperationType", operationType).Return(nil, internalErrors.NewConflictError(internalErrors.DuplicateOperationType, "operation type already exists"))
+
+	req, err := http.NewRequest("POST", "/admin/operation-types", bytes.NewBufferString(`{"operation_type_id": 1, "description": "Purchase", "sign": "debit", "active": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateOperationType(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	mockService.AssertExpectations(t)
+}
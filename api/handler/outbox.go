@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	api "github.com/gmerten/accounts_transactions/api/dto"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/service"
+)
+
+type outboxHandler struct {
+	outboxRelay service.OutboxRelay
+}
+
+type OutboxHandler interface {
+	HandleRedriveOutboxMessages(w http.ResponseWriter, r *http.Request)
+}
+
+func NewOutboxHandler(outboxRelay service.OutboxRelay) OutboxHandler {
+	return &outboxHandler{outboxRelay}
+}
+
+// HandleRedriveOutboxMessages
+// @Summary Redrive the dead lettered events
+// @Description This endpoint queues the dead lettered events of the outbox again, with a fresh set of attempts. The later events of their accounts are held back until then
+// @Tags admin
+// @Produce json
+// @Success 200 {object} api.RedriveOutboxMessagesResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /admin/outbox/dead-letters/redrive [post]
+func (h *outboxHandler) HandleRedriveOutboxMessages(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	redriven, err := h.outboxRelay.RedriveDeadLetters(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error redriving outbox messages")
+		HandleError(w, r, internalErrors.NewUnknownError("Error redriving outbox messages"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(api.RedriveOutboxMessagesResponse{Redriven: redriven})
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxHandler_RedriveOutboxMessages(t *testing.T) {
+	mockRelay := new(MockOutboxRelay)
+	handler := NewOutboxHandler(mockRelay)
+
+	mockRelay.On("RedriveDeadLetters").Return(int64(2), nil)
+
+	req, err := http.NewRequest("POST", "/admin/outbox/dead-letters/redrive", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleRedriveOutboxMessages(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.RedriveOutboxMessagesResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, int64(2), response.Redriven)
+}
+
+func TestOutboxHandler_RedriveOutboxMessagesInternalServerError(t *testing.T) {
+	mockRelay := new(MockOutboxRelay)
+	handler := NewOutboxHandler(mockRelay)
+
+	mockRelay.On("RedriveDeadLetters").Return(int64(0), errors.New("db error"))
+
+	req, err := http.NewRequest("POST", "/admin/outbox/dead-letters/redrive", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleRedriveOutboxMessages(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
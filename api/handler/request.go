@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/go-playground/validator/v10"
+)
+
+// decodeAndValidate decodes the JSON body of r into body and validates it.
+// The body must hold a single JSON object with only the fields of body. The
+// returned ValidationError lists the offending fields when they can be told.
+func decodeAndValidate(r *http.Request, body interface{}) error {
+	return decode(r, body, false)
+}
+
+// decodeOptionalAndValidate is like decodeAndValidate, but an empty body is
+// accepted and leaves body unchanged.
+func decodeOptionalAndValidate(r *http.Request, body interface{}) error {
+	return decode(r, body, true)
+}
+
+// maxRequestBytes bounds the request bodies read into memory.
+const maxRequestBytes = 1 << 20
+
+func decode(r *http.Request, body interface{}, optional bool) error {
+	data, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequestBytes))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Request body must have at most 1 MiB")
+	}
+	if err != nil {
+		return internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Request body could not be read")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(body)
+	if errors.Is(err, io.EOF) {
+		if optional {
+			return nil
+		}
+		return internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Request body is empty")
+	}
+	if err != nil {
+		return toDecodeError(err, data, body)
+	}
+
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Request body must hold a single JSON object")
+	}
+
+	var validationErrs validator.ValidationErrors
+	if err = validate.Struct(body); errors.As(err, &validationErrs) {
+		return internalErrors.NewFieldValidationError(internalErrors.ValidationFailed, "Request body has invalid fields", toFieldViolations(validationErrs))
+	}
+	return err
+}
+
+// toDecodeError describes why data could not be decoded into body, naming the
+// field at fault when there is one.
+func toDecodeError(err error, data []byte, body interface{}) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Type == moneyType {
+		field := typeErr.Field
+		if field == "" {
+			field = invalidMoneyField(data, body)
+		}
+		if field != "" {
+			return internalErrors.NewFieldValidationError(internalErrors.InvalidRequestBody, "Request body has invalid fields", []internalErrors.FieldViolation{{
+				Field:   field,
+				Rule:    "money",
+				Message: field + " must be a decimal amount with at most 2 fraction digits",
+			}})
+		}
+	}
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		expected := jsonType(typeErr.Type.Kind())
+		return internalErrors.NewFieldValidationError(internalErrors.InvalidRequestBody, "Request body has invalid fields", []internalErrors.FieldViolation{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   expected,
+			Message: typeErr.Field + " must be " + withArticle(expected) + ", got " + withArticle(typeErr.Value),
+		}})
+	}
+
+	// The decoder reports unknown fields with a plain error only.
+	if field, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		field = strings.Trim(field, `"`)
+		return internalErrors.NewFieldValidationError(internalErrors.InvalidRequestBody, "Request body has unknown fields", []internalErrors.FieldViolation{{
+			Field:   field,
+			Rule:    "unknown",
+			Message: field + " is not a known field",
+		}})
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Request body is not valid JSON")
+	}
+	// Errors of the types that decode themselves are not shown, as they may
+	// quote the request or name internal types.
+	return internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Request body has invalid values")
+}
+
+var moneyType = reflect.TypeOf(model.Money(0))
+
+// invalidMoneyField returns the JSON name of the amount of body that data
+// holds an invalid value for. Not every decoder names the field when a type
+// that decodes itself fails, so the amounts are decoded again one by one.
+func invalidMoneyField(data []byte, body interface{}) string {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return ""
+	}
+
+	bodyType := reflect.TypeOf(body).Elem()
+	for i := 0; i < bodyType.NumField(); i++ {
+		field := bodyType.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType != moneyType {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		for key, value := range values {
+			var money model.Money
+			if strings.EqualFold(key, name) && money.UnmarshalJSON(value) != nil {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// jsonType names the JSON type that decodes into values of the kind.
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+func withArticle(noun string) string {
+	if strings.IndexAny(noun, "aeiou") == 0 {
+		return "an " + noun
+	}
+	return "a " + noun
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeAndValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		code       internalErrors.Code
+		detail     string
+		violations []internalErrors.FieldViolation
+	}{
+		{
+			name:   "empty",
+			body:   "",
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body is empty",
+		},
+		{
+			name:   "too large",
+			body:   `{"account_id": 1, "amount": 10, "operation_type_id": 1}` + strings.Repeat(" ", 1<<20),
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body must have at most 1 MiB",
+		},
+		{
+			name:   "malformed",
+			body:   `{"account_id": 1,`,
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body is not valid JSON",
+		},
+		{
+			name:   "trailing data",
+			body:   `{"account_id": 1, "amount": 10, "operation_type_id": 1} {"account_id": 2}`,
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body must hold a single JSON object",
+		},
+		{
+			name:   "unknown field",
+			body:   `{"account_id": 1, "amount": 10, "operation_type_id": 1, "ammount": 10}`,
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body has unknown fields",
+			violations: []internalErrors.FieldViolation{
+				{Field: "ammount", Rule: "unknown", Message: "ammount is not a known field"},
+			},
+		},
+		{
+			name:   "wrong type",
+			body:   `{"account_id": "1", "amount": 10, "operation_type_id": 1}`,
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body has invalid fields",
+			violations: []internalErrors.FieldViolation{
+				{Field: "account_id", Rule: "type", Param: "number", Message: "account_id must be a number, got a string"},
+			},
+		},
+		{
+			name:   "invalid amount",
+			body:   `{"account_id": 1, "amount": "ten", "operation_type_id": 1}`,
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body has invalid fields",
+			violations: []internalErrors.FieldViolation{
+				{Field: "amount", Rule: "money", Message: "amount must be a decimal amount with at most 2 fraction digits"},
+			},
+		},
+		{
+			name:   "amount with more than 2 fraction digits",
+			body:   `{"account_id": 1, "amount": 12.345, "operation_type_id": 1}`,
+			code:   internalErrors.InvalidRequestBody,
+			detail: "Request body has invalid fields",
+			violations: []internalErrors.FieldViolation{
+				{Field: "amount", Rule: "money", Message: "amount must be a decimal amount with at most 2 fraction digits"},
+			},
+		},
+		{
+			name:   "amount too large",
+			body:   `{"account_id": 1, "amount": 100000000.00, "operation_type_id": 1}`,
+			code:   internalErrors.ValidationFailed,
+			detail: "Request body has invalid fields",
+			violations: []internalErrors.FieldViolation{
+				{Field: "amount", Rule: "money", Message: "amount must be between -99999999.99 and 99999999.99"},
+			},
+		},
+		{
+			name:   "failed rules",
+			body:   `{"amount": -10, "operation_type_id": 1, "installments": 60}`,
+			code:   internalErrors.ValidationFailed,
+			detail: "Request body has invalid fields",
+			violations: []internalErrors.FieldViolation{
+				{Field: "account_id", Rule: "required", Message: "account_id is required"},
+				{Field: "amount", Rule: "gte", Param: "0", Message: "amount must be greater than or equal to 0"},
+				{Field: "installments", Rule: "lte", Param: "48", Message: "installments must be less than or equal to 48"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requestBody dto.CreateTransactionRequest
+
+			err := decodeAndValidate(httptest.NewRequest("POST", "/transactions", strings.NewReader(test.body)), &requestBody)
+
+			var validationErr internalErrors.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, test.code, validationErr.Code())
+			assert.Equal(t, test.detail, validationErr.Message)
+			assert.Equal(t, test.violations, validationErr.Violations)
+		})
+	}
+}
+
+func TestDecodeAndValidate_ReadableMessages(t *testing.T) {
+	var requestBody dto.CreateWebhookRequest
+
+	err := decodeAndValidate(httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{"url": "not a url", "event_types": ["account.closed"], "secret": "short"}`)), &requestBody)
+
+	var validationErr internalErrors.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []internalErrors.FieldViolation{
+		{Field: "url", Rule: "url", Message: "url must be a valid URL"},
+		{Field: "event_types[0]", Rule: "oneof", Param: "account.created transaction.posted", Message: "event_types[0] must be one of account.created, transaction.posted"},
+		{Field: "secret", Rule: "min", Param: "16", Message: "secret must have at least 16 characters"},
+	}, validationErr.Violations)
+}
+
+func TestDecodeAndValidate_HidesDecoderErrors(t *testing.T) {
+	var requestBody struct {
+		At time.Time `json:"at"`
+	}
+
+	err := decodeAndValidate(httptest.NewRequest("POST", "/", strings.NewReader(`{"at": "secret-value"}`)), &requestBody)
+
+	var validationErr internalErrors.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, internalErrors.InvalidRequestBody, validationErr.Code())
+	assert.Equal(t, "Request body has invalid values", validationErr.Message)
+	assert.Empty(t, validationErr.Violations)
+}
+
+func TestDecodeAndValidate_Valid(t *testing.T) {
+	var requestBody dto.UpdateAccountStatusRequest
+
+	err := decodeAndValidate(httptest.NewRequest("PATCH", "/accounts/1/status", strings.NewReader("{\"status\": \"blocked\"}\n")), &requestBody)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "blocked", requestBody.Status)
+}
+
+func TestDecodeOptionalAndValidate(t *testing.T) {
+	var requestBody dto.CreateReversalRequest
+
+	assert.NoError(t, decodeOptionalAndValidate(httptest.NewRequest("POST", "/transactions/1/reversals", strings.NewReader("")), &requestBody))
+	assert.Nil(t, requestBody.Amount)
+
+	err := decodeOptionalAndValidate(httptest.NewRequest("POST", "/transactions/1/reversals", strings.NewReader(`{"amount": 0}`)), &requestBody)
+
+	var validationErr internalErrors.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "amount", validationErr.Violations[0].Field)
+	assert.Equal(t, "gt", validationErr.Violations[0].Rule)
+}
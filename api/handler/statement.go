@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gmerten/accounts_transactions/api/mapper"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type statementHandler struct {
+	statementService service.StatementService
+}
+
+type StatementHandler interface {
+	HandleListStatements(w http.ResponseWriter, r *http.Request)
+	HandleGetStatement(w http.ResponseWriter, r *http.Request)
+}
+
+func NewStatementHandler(statementService service.StatementService) StatementHandler {
+	return &statementHandler{statementService}
+}
+
+// HandleListStatements
+// @Summary List the statements of an account
+// @Description This endpoint lists the closed statements of an account, newest first. Cycles are closed hourly in the background
+// @Tags accounts
+// @Produce json
+// @Param accountID path uint true "Account ID"
+// @Success 200 {object} api.ListStatementsResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /accounts/{accountID}/statements [get]
+func (s *statementHandler) HandleListStatements(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "accountID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Account ID"))
+		return
+	}
+
+	statements, err := s.statementService.ListStatements(r.Context(), accountID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("accountID", accountID).WithError(err).Error("Error listing statements")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error listing statements"))
+		return
+	}
+
+	response := mapper.ToListStatementsResponse(statements)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetStatement
+// @Summary Get a statement of an account
+// @Description This endpoint gets the statement of the cycle closing in the given month, with its transactions. The current cycle is returned open and may still change
+// @Tags accounts
+// @Produce json
+// @Param accountID path uint true "Account ID"
+// @Param period path string true "Closing month, as YYYY-MM"
+// @Success 200 {object} api.StatementDetailResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /accounts/{accountID}/statements/{period} [get]
+func (s *statementHandler) HandleGetStatement(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "accountID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Account ID"))
+		return
+	}
+
+	statement, err := s.statementService.GetStatement(r.Context(), accountID, chi.URLParam(r, "period"))
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("accountID", accountID).WithError(err).Error("Error getting statement")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting statement"))
+		return
+	}
+
+	response := mapper.ToStatementDetailResponse(statement)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
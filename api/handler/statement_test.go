@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestStatement() *model.Statement {
+	account := &model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	closedAt := time.Date(2024, time.September, 26, 0, 0, 0, 0, time.UTC)
+	transactions := []model.Transaction{
+		{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-250.50"), OperationType: model.Purchase, TransactionDate: time.Date(2024, time.September, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, AccountID: 1, Amount: model.MustParseMoney("100.00"), OperationType: model.Payment, TransactionDate: time.Date(2024, time.September, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	statement := model.NewStatement(account, time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC), model.MustParseMoney("-100.00"), transactions, nil)
+	statement.ClosedAt = &closedAt
+	return statement
+}
+
+func TestStatementHandler_ListStatementsSuccess(t *testing.T) {
+	mockService := new(MockStatementService)
+	handler := NewStatementHandler(mockService)
+
+	mockService.On("ListStatements", int64(1)).Return([]model.Statement{*newTestStatement()}, nil)
+
+	req, err := http.NewRequest("GET", "/accounts/1/statements", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListStatements(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.ListStatementsResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Len(t, response.Statements, 1)
+	assert.Equal(t, "2024-09", response.Statements[0].Period)
+	assert.Equal(t, model.MustParseMoney("-250.50"), response.Statements[0].ClosingBalance)
+	assert.Equal(t, model.MustParseMoney("37.58"), response.Statements[0].MinimumPayment)
+	assert.True(t, response.Statements[0].Closed)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_ListStatementsInternalServerError(t *testing.T) {
+	mockService := new(MockStatementService)
+	handler := NewStatementHandler(mockService)
+
+	mockService.On("ListStatements", int64(1)).Return(nil, errors.New("db error"))
+
+	req, err := http.NewRequest("GET", "/accounts/1/statements", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListStatements(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_GetStatementSuccess(t *testing.T) {
+	mockService := new(MockStatementService)
+	handler := NewStatementHandler(mockService)
+
+	mockService.On("GetStatement", int64(1), "2024-09").Return(newTestStatement(), nil)
+
+	req, err := http.NewRequest("GET", "/accounts/1/statements/2024-09", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	routeCtx.URLParams.Add("period", "2024-09")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetStatement(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.StatementDetailResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, "2024-09", response.Period)
+	assert.Equal(t, model.MustParseMoney("250.50"), response.TotalDebits)
+	assert.Equal(t, model.MustParseMoney("100.00"), response.TotalCredits)
+	assert.Len(t, response.Transactions, 2)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_GetStatementCustomError(t *testing.T) {
+	mockService := new(MockStatementService)
+	handler := NewStatementHandler(mockService)
+
+	mockService.On("GetStatement", int64(1), "2030-01").Return(nil, internalErrors.NewNotFoundError(internalErrors.StatementNotFound, "Statement not found"))
+
+	req, err := http.NewRequest("GET", "/accounts/1/statements/2030-01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	routeCtx.URLParams.Add("period", "2030-01")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetStatement(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_GetStatementInvalidAccountIDError(t *testing.T) {
+	mockService := new(MockStatementService)
+	handler := NewStatementHandler(mockService)
+
+	req, err := http.NewRequest("GET", "/accounts/abc/statements/2024-09", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "abc")
+	routeCtx.URLParams.Add("period", "2024-09")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetStatement(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertNotCalled(t, "GetStatement", mock.Anything, mock.Anything)
+}
@@ -3,27 +3,40 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gmerten/accounts_transactions/api/dto"
 	"github.com/gmerten/accounts_transactions/api/mapper"
 	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
 	"github.com/gmerten/accounts_transactions/internal/service"
-	"github.com/go-playground/validator/v10"
-	log "github.com/sirupsen/logrus"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultTransactionsPageSize = 20
+	maxTransactionsPageSize     = 100
 )
 
 type transactionHandler struct {
-	transactionService service.TransactionService
-	accountService     service.AccountService
+	transactionService   service.TransactionService
+	accountService       service.AccountService
+	operationTypeService service.OperationTypeService
 }
 
 type TransactionHandler interface {
 	HandleCreateTransaction(w http.ResponseWriter, r *http.Request)
+	HandleListTransactions(w http.ResponseWriter, r *http.Request)
+	HandleCreateReversal(w http.ResponseWriter, r *http.Request)
 }
 
-func NewTransactionHandler(transactionService service.TransactionService, accountService service.AccountService) TransactionHandler {
+func NewTransactionHandler(transactionService service.TransactionService, accountService service.AccountService, operationTypeService service.OperationTypeService) TransactionHandler {
 	return &transactionHandler{transactionService,
-		accountService}
+		accountService,
+		operationTypeService}
 }
 
 // HandleCreateTransaction
@@ -33,46 +46,65 @@ func NewTransactionHandler(transactionService service.TransactionService, accoun
 // @Accept json
 // @Produce json
 // @Param transaction body api.CreateTransactionRequest true "Request body"
+// @Param Idempotency-Key header string false "Makes retries of this request safe to send"
 // @Success 200 {object} api.CreateTransactionResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
 // @Router /transactions [post]
 func (t *transactionHandler) HandleCreateTransaction(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var requestBody api.CreateTransactionRequest
 
-	err := json.NewDecoder(r.Body).Decode(&requestBody)
+	err := decodeAndValidate(r, &requestBody)
 	if err != nil {
-		log.WithError(err).Error("Error decoding request body")
-		HandleError(w, internalErrors.NewValidationError("Invalid Request Body"))
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
 		return
 	}
 
-	err = validator.New().Struct(requestBody)
+	operationType, err := t.operationTypeService.GetActiveOperationType(r.Context(), model.OperationType(requestBody.OperationTypeID))
 	if err != nil {
-		log.WithError(err).Error("Error validating request body")
-		HandleError(w, internalErrors.NewValidationError("Invalid request body"))
+		logging.FromContext(r.Context()).WithField("operationTypeID", requestBody.OperationTypeID).WithError(err).Error("Error getting operation type")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting operation type"))
 		return
 	}
 
-	transaction := mapper.ToTransaction(requestBody)
+	transaction := mapper.ToTransaction(requestBody, operationType)
 
-	_, err = t.accountService.GetAccountById(transaction.AccountID)
+	account, err := t.accountService.GetAccountById(r.Context(), transaction.AccountID)
 
 	if err != nil {
-		log.WithError(err).Error("Error getting account")
+		logging.FromContext(r.Context()).WithError(err).Error("Error getting account")
 		_, ok := err.(CustomError)
 		if ok {
-			HandleError(w, err)
+			HandleError(w, r, err)
 			return
 		}
-		HandleError(w, internalErrors.NewUnknownError("Error getting account"))
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting account"))
 		return
 	}
 
-	transaction, err = t.transactionService.CreateTransaction(transaction)
+	if !account.IsActive() {
+		logging.FromContext(r.Context()).WithField("accountID", account.ID).WithField("status", account.Status).Error("Posting to an account that is not active")
+		HandleError(w, r, internalErrors.NewAccountNotActiveError("Account is "+string(account.Status)))
+		return
+	}
+
+	transaction, err = t.transactionService.CreateTransaction(r.Context(), transaction, requestBody.Installments)
 
 	if err != nil {
-		log.WithField("accountID", requestBody.AccountID).WithError(err).Error("Error creating transaction")
-		HandleError(w, internalErrors.NewUnknownError("Fail creating transaction"))
+		logging.FromContext(r.Context()).WithField("accountID", requestBody.AccountID).WithError(err).Error("Error creating transaction")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Fail creating transaction"))
 		return
 	}
 
@@ -83,3 +115,204 @@ func (t *transactionHandler) HandleCreateTransaction(w http.ResponseWriter, r *h
 	_ = json.NewEncoder(w).Encode(response)
 
 }
+
+// HandleListTransactions
+// @Summary List the transactions of an account
+// @Description This endpoint lists the transactions of an account, with optional filters and cursor pagination
+// @Tags transactions
+// @Produce json
+// @Param accountID path uint true "Account ID"
+// @Param operation_type_id query []int false "Operation type IDs" collectionFormat(multi)
+// @Param from query string false "Start date, RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "End date, RFC 3339 or YYYY-MM-DD (inclusive)"
+// @Param min_amount query number false "Minimum absolute amount"
+// @Param max_amount query number false "Maximum absolute amount"
+// @Param sort query string false "Sort by transaction date" Enums(asc, desc) default(desc)
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param cursor query string false "The next_cursor returned by the previous page"
+// @Success 200 {object} api.ListTransactionsResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /accounts/{accountID}/transactions [get]
+func (t *transactionHandler) HandleListTransactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "accountID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Account ID"))
+		return
+	}
+
+	filter, err := parseTransactionFilter(r.URL.Query())
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error parsing transaction filter")
+		HandleError(w, r, err)
+		return
+	}
+	filter.AccountID = accountID
+
+	_, err = t.accountService.GetAccountById(r.Context(), accountID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error getting account")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting account"))
+		return
+	}
+
+	page, err := t.transactionService.ListTransactions(r.Context(), filter)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("accountID", accountID).WithError(err).Error("Error listing transactions")
+		HandleError(w, r, internalErrors.NewUnknownError("Error listing transactions"))
+		return
+	}
+
+	response := mapper.ToListTransactionsResponse(page)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleCreateReversal
+// @Summary Reverses a transaction
+// @Description This endpoint posts a compensating transaction for the original one. Without an amount, everything still unreversed is reversed
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param transactionID path uint true "Transaction ID"
+// @Param reversal body api.CreateReversalRequest false "Request body"
+// @Param Idempotency-Key header string false "Makes retries of this request safe to send"
+// @Success 201 {object} api.ReversalResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /transactions/{transactionID}/reversals [post]
+func (t *transactionHandler) HandleCreateReversal(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	transactionID, err := strconv.ParseInt(chi.URLParam(r, "transactionID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Transaction ID"))
+		return
+	}
+
+	var requestBody api.CreateReversalRequest
+
+	err = decodeOptionalAndValidate(r, &requestBody)
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
+		return
+	}
+
+	reversal, original, err := t.transactionService.ReverseTransaction(r.Context(), transactionID, requestBody.Amount)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("transactionID", transactionID).WithError(err).Error("Error reversing transaction")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Fail reversing transaction"))
+		return
+	}
+
+	response := mapper.ToReversalResponse(reversal, original)
+
+	w.WriteHeader(http.StatusCreated)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func parseTransactionFilter(query url.Values) (model.TransactionFilter, error) {
+	filter := model.TransactionFilter{
+		Sort:  model.SortDescending,
+		Limit: defaultTransactionsPageSize,
+	}
+
+	for _, value := range query["operation_type_id"] {
+		operationTypeID, err := strconv.Atoi(value)
+		if err != nil || operationTypeID < 1 {
+			return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid operation_type_id")
+		}
+		filter.OperationTypes = append(filter.OperationTypes, model.OperationType(operationTypeID))
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, err := parseFilterDate(value, false)
+		if err != nil {
+			return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid from date")
+		}
+		filter.From = &from
+	}
+
+	if value := query.Get("to"); value != "" {
+		to, err := parseFilterDate(value, true)
+		if err != nil {
+			return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid to date")
+		}
+		filter.To = &to
+	}
+
+	if value := query.Get("min_amount"); value != "" {
+		minAmount, err := model.ParseMoney(value)
+		if err != nil || minAmount < 0 {
+			return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid min_amount")
+		}
+		filter.MinAmount = &minAmount
+	}
+
+	if value := query.Get("max_amount"); value != "" {
+		maxAmount, err := model.ParseMoney(value)
+		if err != nil || maxAmount < 0 {
+			return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid max_amount")
+		}
+		filter.MaxAmount = &maxAmount
+	}
+
+	switch value := query.Get("sort"); value {
+	case "":
+	case string(model.SortAscending), string(model.SortDescending):
+		filter.Sort = model.SortOrder(value)
+	default:
+		return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid sort, expected asc or desc")
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxTransactionsPageSize {
+			return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid limit, expected a value between 1 and 100")
+		}
+		filter.Limit = limit
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := mapper.DecodeTransactionCursor(value)
+		if err != nil {
+			return filter, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid cursor")
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+// parseFilterDate accepts an RFC 3339 timestamp or a plain date. A plain date
+// used as an upper bound covers the whole day.
+func parseFilterDate(value string, endOfDay bool) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return date, nil
+}
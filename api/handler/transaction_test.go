@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/gmerten/accounts_transactions/api/mapper"
 	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
 	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,30 +22,33 @@ import (
 func TestTransactionHandler_CreateTransactionSuccess(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockAccountService := new(MockAccountService)
-	handler := NewTransactionHandler(mockTransactionService, mockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
 
 	createTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1,
+		Amount:          model.MustParseMoney("1.00"),
 		OperationTypeID: 1,
 	}
 
 	account := &model.Account{
 		ID:             1,
 		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
 	}
 
 	transaction := &model.Transaction{
 		ID:              1,
 		AccountID:       1,
-		Amount:          1,
+		Amount:          model.MustParseMoney("1.00"),
 		TransactionDate: time.Now(),
 		OperationType:   model.Purchase,
 	}
 
+	mockOperationTypeService.On("GetActiveOperationType", model.Purchase).Return(&model.BuiltInOperationTypes[0], nil)
 	mockAccountService.On("GetAccountById", int64(1)).Return(account, nil)
 
-	mockTransactionService.On("CreateTransaction", mock.AnythingOfType("*model.Transaction")).Return(transaction, nil)
+	mockTransactionService.On("CreateTransaction", mock.AnythingOfType("*model.Transaction"), 0).Return(transaction, nil)
 
 	createTransactionJSON, _ := json.Marshal(createTransactionRequest)
 
@@ -67,12 +72,14 @@ func TestTransactionHandler_CreateTransactionSuccess(t *testing.T) {
 
 	mockTransactionService.AssertExpectations(t)
 	mockAccountService.AssertExpectations(t)
+	mockOperationTypeService.AssertExpectations(t)
 }
 
 func TestTransactionHandler_CreateTransactionInvalidJSONError(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockAccountService := new(MockAccountService)
-	handler := NewTransactionHandler(mockTransactionService, mockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
 
 	createTransactionJSON, _ := json.Marshal("invalid json")
 
@@ -92,14 +99,18 @@ func TestTransactionHandler_CreateTransactionInvalidJSONError(t *testing.T) {
 func TestTransactionHandler_CreateTransactionInvalidOperationTypeError(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockAccountService := new(MockAccountService)
-	handler := NewTransactionHandler(mockTransactionService, mockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
 
 	createTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1,
+		Amount:          model.MustParseMoney("1.00"),
 		OperationTypeID: 6,
 	}
 
+	mockOperationTypeService.On("GetActiveOperationType", model.OperationType(6)).
+		Return(nil, internalErrors.NewValidationError(internalErrors.InvalidOperationType, "Invalid operation type"))
+
 	createTransactionJSON, _ := json.Marshal(createTransactionRequest)
 
 	req, err := http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
@@ -114,6 +125,31 @@ func TestTransactionHandler_CreateTransactionInvalidOperationTypeError(t *testin
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 
+	mockTransactionService.AssertExpectations(t)
+	mockAccountService.AssertExpectations(t)
+	mockOperationTypeService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_CreateTransactionInvalidAmountPrecisionError(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	createTransactionJSON := []byte(`{"account_id": 1, "amount": 10.005, "operation_type_id": 1}`)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateTransaction(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
 	mockTransactionService.AssertExpectations(t)
 	mockAccountService.AssertExpectations(t)
 }
@@ -121,15 +157,17 @@ func TestTransactionHandler_CreateTransactionInvalidOperationTypeError(t *testin
 func TestTransactionHandler_CreateTransactionAccountCustomError(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockAccountService := new(MockAccountService)
-	handler := NewTransactionHandler(mockTransactionService, mockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
 
 	createTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1,
+		Amount:          model.MustParseMoney("1.00"),
 		OperationTypeID: 1,
 	}
 
-	mockAccountService.On("GetAccountById", int64(1)).Return(nil, internalErrors.NewNotFoundError("account not found"))
+	mockOperationTypeService.On("GetActiveOperationType", model.Purchase).Return(&model.BuiltInOperationTypes[0], nil)
+	mockAccountService.On("GetAccountById", int64(1)).Return(nil, internalErrors.NewNotFoundError(internalErrors.AccountNotFound, "account not found"))
 
 	createTransactionJSON, _ := json.Marshal(createTransactionRequest)
 
@@ -146,19 +184,22 @@ func TestTransactionHandler_CreateTransactionAccountCustomError(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, rr.Code)
 
 	mockAccountService.AssertExpectations(t)
+	mockOperationTypeService.AssertExpectations(t)
 }
 
 func TestTransactionHandler_CreateTransactionAccountGenericError(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockAccountService := new(MockAccountService)
-	handler := NewTransactionHandler(mockTransactionService, mockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
 
 	createTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1,
+		Amount:          model.MustParseMoney("1.00"),
 		OperationTypeID: 1,
 	}
 
+	mockOperationTypeService.On("GetActiveOperationType", model.Purchase).Return(&model.BuiltInOperationTypes[0], nil)
 	mockAccountService.On("GetAccountById", int64(1)).Return(nil, errors.New("generic error"))
 
 	createTransactionJSON, _ := json.Marshal(createTransactionRequest)
@@ -176,27 +217,31 @@ func TestTransactionHandler_CreateTransactionAccountGenericError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 
 	mockAccountService.AssertExpectations(t)
+	mockOperationTypeService.AssertExpectations(t)
 }
 
 func TestTransactionHandler_CreateTransactionError(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockAccountService := new(MockAccountService)
-	handler := NewTransactionHandler(mockTransactionService, mockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
 
 	createTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1,
+		Amount:          model.MustParseMoney("1.00"),
 		OperationTypeID: 1,
 	}
 
 	account := &model.Account{
 		ID:             1,
 		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
 	}
 
+	mockOperationTypeService.On("GetActiveOperationType", model.Purchase).Return(&model.BuiltInOperationTypes[0], nil)
 	mockAccountService.On("GetAccountById", int64(1)).Return(account, nil)
 
-	mockTransactionService.On("CreateTransaction", mock.AnythingOfType("*model.Transaction")).Return(nil, errors.New("error creating account"))
+	mockTransactionService.On("CreateTransaction", mock.AnythingOfType("*model.Transaction"), 0).Return(nil, errors.New("error creating account"))
 
 	createTransactionJSON, _ := json.Marshal(createTransactionRequest)
 
@@ -214,26 +259,379 @@ func TestTransactionHandler_CreateTransactionError(t *testing.T) {
 
 	mockTransactionService.AssertExpectations(t)
 	mockAccountService.AssertExpectations(t)
+	mockOperationTypeService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_CreateTransactionCreditLimitExceededError(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	createTransactionRequest := dto.CreateTransactionRequest{
+		AccountID:       1,
+		Amount:          model.MustParseMoney("1.00"),
+		OperationTypeID: 1,
+	}
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
+	}
+
+	mockOperationTypeService.On("GetActiveOperationType", model.Purchase).Return(&model.BuiltInOperationTypes[0], nil)
+	mockAccountService.On("GetAccountById", int64(1)).Return(account, nil)
+
+	mockTransactionService.On("CreateTransaction", mock.AnythingOfType("*model.Transaction"), 0).
+		Return(nil, internalErrors.NewCreditLimitExceededError("Transaction exceeds the available credit limit"))
+
+	createTransactionJSON, _ := json.Marshal(createTransactionRequest)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateTransaction(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	mockTransactionService.AssertExpectations(t)
+	mockAccountService.AssertExpectations(t)
+	mockOperationTypeService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_CreateTransactionAccountNotActiveError(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	createTransactionRequest := dto.CreateTransactionRequest{
+		AccountID:       1,
+		Amount:          model.MustParseMoney("1.00"),
+		OperationTypeID: 1,
+	}
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Status:         model.AccountClosed,
+	}
+
+	mockOperationTypeService.On("GetActiveOperationType", model.Purchase).Return(&model.BuiltInOperationTypes[0], nil)
+	mockAccountService.On("GetAccountById", int64(1)).Return(account, nil)
+
+	createTransactionJSON, _ := json.Marshal(createTransactionRequest)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateTransaction(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	assert.Equal(t, string(internalErrors.AccountNotActive), decodeProblem(t, rr).Code)
+
+	mockTransactionService.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	mockAccountService.AssertExpectations(t)
 }
 
 func TestTransactionHandler_TransactionMapper(t *testing.T) {
 
 	createPurchaseTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1000,
+		Amount:          model.MustParseMoney("1000.00"),
 		OperationTypeID: 1,
 	}
 
 	createPaymentTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1000,
+		Amount:          model.MustParseMoney("1000.00"),
 		OperationTypeID: 4,
 	}
 
-	paymentTransaction := mapper.ToTransaction(createPaymentTransactionRequest)
-	purchaseTransaction := mapper.ToTransaction(createPurchaseTransactionRequest)
+	paymentTransaction := mapper.ToTransaction(createPaymentTransactionRequest, &model.BuiltInOperationTypes[3])
+	purchaseTransaction := mapper.ToTransaction(createPurchaseTransactionRequest, &model.BuiltInOperationTypes[0])
 
 	assert.Equal(t, -createPurchaseTransactionRequest.Amount, purchaseTransaction.Amount)
 	assert.Equal(t, createPaymentTransactionRequest.Amount, paymentTransaction.Amount)
 
 }
+
+func TestTransactionHandler_ListTransactionsSuccess(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
+	}
+
+	date := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+	minAmount := model.MustParseMoney("10.00")
+	expectedFilter := model.TransactionFilter{
+		AccountID:      1,
+		OperationTypes: []model.OperationType{model.Purchase, model.Payment},
+		MinAmount:      &minAmount,
+		Sort:           model.SortAscending,
+		Limit:          1,
+	}
+
+	page := &model.TransactionPage{
+		Transactions: []model.Transaction{
+			{ID: 7, AccountID: 1, Amount: model.MustParseMoney("-10.00"), TransactionDate: date, OperationType: model.Purchase},
+		},
+		Next: &model.TransactionCursor{TransactionDate: date, ID: 7},
+	}
+
+	mockAccountService.On("GetAccountById", int64(1)).Return(account, nil)
+	mockTransactionService.On("ListTransactions", expectedFilter).Return(page, nil)
+
+	req, err := http.NewRequest("GET", "/accounts/1/transactions?operation_type_id=1&operation_type_id=4&min_amount=10&sort=asc&limit=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListTransactions(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.ListTransactionsResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Len(t, response.Transactions, 1)
+	assert.Equal(t, int64(7), response.Transactions[0].TransactionID)
+
+	cursor, err := mapper.DecodeTransactionCursor(response.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), cursor.ID)
+	assert.True(t, date.Equal(cursor.TransactionDate))
+
+	mockTransactionService.AssertExpectations(t)
+	mockAccountService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_ListTransactionsInvalidFilterError(t *testing.T) {
+	queries := []string{
+		"operation_type_id=abc",
+		"from=yesterday",
+		"to=2024-13-01",
+		"min_amount=1.001",
+		"max_amount=-5",
+		"sort=random",
+		"limit=0",
+		"limit=101",
+		"cursor=not-a-cursor",
+	}
+
+	for _, query := range queries {
+		mockTransactionService := new(MockTransactionService)
+		mockAccountService := new(MockAccountService)
+		mockOperationTypeService := new(MockOperationTypeService)
+		handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+		req, err := http.NewRequest("GET", "/accounts/1/transactions?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("accountID", "1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+		rr := httptest.NewRecorder()
+
+		handler.HandleListTransactions(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+
+		mockTransactionService.AssertExpectations(t)
+		mockAccountService.AssertExpectations(t)
+	}
+}
+
+func TestTransactionHandler_ListTransactionsAccountCustomError(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	mockAccountService.On("GetAccountById", int64(1)).Return(nil, internalErrors.NewNotFoundError(internalErrors.AccountNotFound, "account not found"))
+
+	req, err := http.NewRequest("GET", "/accounts/1/transactions", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListTransactions(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockAccountService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_ListTransactionsError(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
+	}
+
+	mockAccountService.On("GetAccountById", int64(1)).Return(account, nil)
+	mockTransactionService.On("ListTransactions", mock.Anything).Return(nil, errors.New("error listing transactions"))
+
+	req, err := http.NewRequest("GET", "/accounts/1/transactions", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("accountID", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListTransactions(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	mockTransactionService.AssertExpectations(t)
+	mockAccountService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_CreateReversalSuccess(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	originalID := int64(7)
+	original := &model.Transaction{
+		ID:             originalID,
+		AccountID:      1,
+		Amount:         model.MustParseMoney("-100.00"),
+		Balance:        model.MustParseMoney("-60.00"),
+		ReversedAmount: model.MustParseMoney("40.00"),
+		Status:         model.PartiallyReversed,
+		OperationType:  model.Purchase,
+	}
+	reversal := &model.Transaction{
+		ID:            8,
+		AccountID:     1,
+		Amount:        model.MustParseMoney("40.00"),
+		Status:        model.Posted,
+		ReversalOfID:  &originalID,
+		OperationType: model.Purchase,
+	}
+
+	amount := model.MustParseMoney("40.00")
+	mockTransactionService.On("ReverseTransaction", int64(7), &amount).Return(reversal, original, nil)
+
+	req, err := http.NewRequest("POST", "/transactions/7/reversals", bytes.NewBufferString(`{"amount": 40}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "7")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateReversal(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var response dto.ReversalResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, int64(8), response.Reversal.TransactionID)
+	assert.Equal(t, originalID, *response.Reversal.ReversalOfTransactionID)
+	assert.Equal(t, "partially_reversed", response.Original.Status)
+	assert.Equal(t, model.MustParseMoney("40.00"), response.Original.ReversedAmount)
+
+	mockTransactionService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_CreateReversalWithoutBody(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	mockTransactionService.On("ReverseTransaction", int64(7), (*model.Money)(nil)).
+		Return(nil, nil, internalErrors.NewUnprocessableEntityError(internalErrors.TransactionAlreadyReversed, "Transaction is already reversed"))
+
+	req, err := http.NewRequest("POST", "/transactions/7/reversals", bytes.NewBuffer(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "7")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateReversal(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	mockTransactionService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_CreateReversalInvalidAmountError(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	mockAccountService := new(MockAccountService)
+	mockOperationTypeService := new(MockOperationTypeService)
+	handler := NewTransactionHandler(mockTransactionService, mockAccountService, mockOperationTypeService)
+
+	req, err := http.NewRequest("POST", "/transactions/7/reversals", bytes.NewBufferString(`{"amount": -5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("transactionID", "7")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateReversal(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockTransactionService.AssertNotCalled(t, "ReverseTransaction", mock.Anything, mock.Anything)
+}
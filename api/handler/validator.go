@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/go-playground/validator/v10"
+)
+
+// validate is shared by the handlers, so custom validations are registered
+// once and struct metadata is cached across requests.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	_ = v.RegisterValidation("document", validateDocumentNumber)
+	_ = v.RegisterValidation("money", validateMoney)
+	v.RegisterTagNameFunc(jsonFieldName)
+	return v
+}
+
+// validateDocumentNumber backs the document tag, which accepts CPFs and CNPJs
+// with valid check digits, with or without punctuation.
+func validateDocumentNumber(field validator.FieldLevel) bool {
+	_, _, err := model.ParseDocumentNumber(field.Field().String())
+	return err == nil
+}
+
+// validateMoney backs the money tag, which accepts the amounts the database can
+// store, up to 99999999.99 either way.
+func validateMoney(field validator.FieldLevel) bool {
+	return model.Money(field.Field().Int()).InRange()
+}
+
+// jsonFieldName names the fields in validation errors as they are named in the
+// request body.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// toFieldViolations describes every failed validation of a request body with
+// the path of its field, such as event_types[0], and a readable message.
+func toFieldViolations(errs validator.ValidationErrors) []internalErrors.FieldViolation {
+	violations := make([]internalErrors.FieldViolation, 0, len(errs))
+	for _, fieldErr := range errs {
+		field := fieldErr.Namespace()
+		if _, path, found := strings.Cut(field, "."); found {
+			field = path
+		}
+
+		violations = append(violations, internalErrors.FieldViolation{
+			Field:   field,
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: field + " " + violationMessage(fieldErr),
+		})
+	}
+	return violations
+}
+
+func violationMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "document":
+		return "must be a valid CPF or CNPJ"
+	case "money":
+		return "must be between " + (-model.MaxMoney).String() + " and " + model.MaxMoney.String()
+	case "url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "min":
+		if unit := lengthUnit(fieldErr.Kind()); unit != "" {
+			return "must have at least " + param + " " + unit
+		}
+		return "must be at least " + param
+	case "max":
+		if unit := lengthUnit(fieldErr.Kind()); unit != "" {
+			return "must have at most " + param + " " + unit
+		}
+		return "must be at most " + param
+	}
+
+	if param != "" {
+		return fmt.Sprintf("must satisfy %s=%s", fieldErr.Tag(), param)
+	}
+	return "must satisfy " + fieldErr.Tag()
+}
+
+// lengthUnit is what min and max count for fields of the kind: the characters
+// of strings and the items of lists. They bound the value of other fields.
+func lengthUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	default:
+		return ""
+	}
+}
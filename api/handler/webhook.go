@@ -0,0 +1,317 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	api "github.com/gmerten/accounts_transactions/api/dto"
+	"github.com/gmerten/accounts_transactions/api/mapper"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultDeliveriesPageSize = 50
+	maxDeliveriesPageSize     = 200
+)
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+type WebhookHandler interface {
+	HandleCreateWebhook(w http.ResponseWriter, r *http.Request)
+	HandleListWebhooks(w http.ResponseWriter, r *http.Request)
+	HandleGetWebhook(w http.ResponseWriter, r *http.Request)
+	HandleUpdateWebhook(w http.ResponseWriter, r *http.Request)
+	HandleDeleteWebhook(w http.ResponseWriter, r *http.Request)
+	HandleListWebhookDeliveries(w http.ResponseWriter, r *http.Request)
+	HandleRedriveWebhookDeliveries(w http.ResponseWriter, r *http.Request)
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return &webhookHandler{webhookService}
+}
+
+// HandleCreateWebhook
+// @Summary Subscribes a webhook endpoint
+// @Description This endpoint registers an HTTP endpoint that receives the events of the given types, or of every type when none is given. Deliveries are signed with HMAC-SHA256 in the X-Webhook-Signature header, and the secret is only returned here
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body api.CreateWebhookRequest true "Request body"
+// @Success 201 {object} api.CreateWebhookResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /webhooks [post]
+func (h *webhookHandler) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var requestBody api.CreateWebhookRequest
+
+	err := decodeAndValidate(r, &requestBody)
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
+		return
+	}
+
+	subscription, err := h.webhookService.CreateWebhook(r.Context(), mapper.ToWebhookSubscription(requestBody))
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error creating webhook")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error creating webhook"))
+		return
+	}
+
+	response := mapper.ToCreateWebhookResponse(subscription)
+
+	w.WriteHeader(http.StatusCreated)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleListWebhooks
+// @Summary List the webhooks
+// @Description This endpoint lists every webhook subscription, active or not
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} api.ListWebhooksResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /webhooks [get]
+func (h *webhookHandler) HandleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	subscriptions, err := h.webhookService.ListWebhooks(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error listing webhooks")
+		HandleError(w, r, internalErrors.NewUnknownError("Error listing webhooks"))
+		return
+	}
+
+	response := mapper.ToListWebhooksResponse(subscriptions)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetWebhook
+// @Summary Get a webhook
+// @Tags webhooks
+// @Produce json
+// @Param webhookID path uint true "Webhook ID"
+// @Success 200 {object} api.WebhookResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{webhookID} [get]
+func (h *webhookHandler) HandleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Webhook ID"))
+		return
+	}
+
+	subscription, err := h.webhookService.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("webhookID", webhookID).WithError(err).Error("Error getting webhook")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error getting webhook"))
+		return
+	}
+
+	response := mapper.ToWebhookResponse(subscription)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleUpdateWebhook
+// @Summary Updates a webhook
+// @Description This endpoint replaces the URL, event types and active flag of a webhook. Inactive webhooks get no new deliveries, and their pending deliveries are dead lettered
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhookID path uint true "Webhook ID"
+// @Param webhook body api.UpdateWebhookRequest true "Request body"
+// @Success 200 {object} api.WebhookResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{webhookID} [put]
+func (h *webhookHandler) HandleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Webhook ID"))
+		return
+	}
+
+	var requestBody api.UpdateWebhookRequest
+
+	err = decodeAndValidate(r, &requestBody)
+	if err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("Error validating request body")
+		HandleError(w, r, err)
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateWebhook(r.Context(), mapper.ToUpdatedWebhookSubscription(webhookID, requestBody))
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("webhookID", webhookID).WithError(err).Error("Error updating webhook")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error updating webhook"))
+		return
+	}
+
+	response := mapper.ToWebhookResponse(subscription)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleDeleteWebhook
+// @Summary Deletes a webhook
+// @Description This endpoint deletes a webhook along with its deliveries
+// @Tags webhooks
+// @Param webhookID path uint true "Webhook ID"
+// @Success 204
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{webhookID} [delete]
+func (h *webhookHandler) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Webhook ID"))
+		return
+	}
+
+	err = h.webhookService.DeleteWebhook(r.Context(), webhookID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("webhookID", webhookID).WithError(err).Error("Error deleting webhook")
+		w.Header().Set("Content-Type", "application/json")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error deleting webhook"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListWebhookDeliveries
+// @Summary List the deliveries of a webhook
+// @Description This endpoint is the delivery log of a webhook, newest first, with the attempts, last response status and last error of each delivery. Filter by dead_lettered to see the dead letter store
+// @Tags webhooks
+// @Produce json
+// @Param webhookID path uint true "Webhook ID"
+// @Param status query string false "Delivery status" Enums(pending, succeeded, dead_lettered)
+// @Param limit query int false "Maximum number of deliveries" minimum(1) maximum(200) default(50)
+// @Success 200 {object} api.ListWebhookDeliveriesResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{webhookID}/deliveries [get]
+func (h *webhookHandler) HandleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Webhook ID"))
+		return
+	}
+
+	status := model.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", model.DeliveryPending, model.DeliverySucceeded, model.DeliveryDeadLettered:
+	default:
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid status, expected pending, succeeded or dead_lettered"))
+		return
+	}
+
+	limit := defaultDeliveriesPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveriesPageSize {
+			HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid limit, expected a value between 1 and 200"))
+			return
+		}
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), webhookID, status, limit)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("webhookID", webhookID).WithError(err).Error("Error listing webhook deliveries")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error listing webhook deliveries"))
+		return
+	}
+
+	response := mapper.ToListWebhookDeliveriesResponse(deliveries)
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleRedriveWebhookDeliveries
+// @Summary Redrive the dead letters of a webhook
+// @Description This endpoint queues the dead lettered deliveries of a webhook again, with a fresh set of attempts
+// @Tags webhooks
+// @Produce json
+// @Param webhookID path uint true "Webhook ID"
+// @Success 200 {object} api.RedriveWebhookDeliveriesResponse
+// @Failure default {object} api.Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{webhookID}/dead-letters/redrive [post]
+func (h *webhookHandler) HandleRedriveWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidParameter, "Invalid Webhook ID"))
+		return
+	}
+
+	redriven, err := h.webhookService.RedriveDeadLetters(r.Context(), webhookID)
+	if err != nil {
+		logging.FromContext(r.Context()).WithField("webhookID", webhookID).WithError(err).Error("Error redriving webhook deliveries")
+		_, ok := err.(CustomError)
+		if ok {
+			HandleError(w, r, err)
+			return
+		}
+		HandleError(w, r, internalErrors.NewUnknownError("Error redriving webhook deliveries"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(api.RedriveWebhookDeliveriesResponse{Redriven: redriven})
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/gmerten/accounts_transactions/api/dto"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func withWebhookID(req *http.Request, webhookID string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("webhookID", webhookID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
+
+func TestWebhookHandler_CreateWebhookSuccess(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	mockService.On("CreateWebhook", mock.MatchedBy(func(subscription *model.WebhookSubscription) bool {
+		return subscription.URL == "https://example.com/hooks" && subscription.Active &&
+			len(subscription.EventTypes) == 1 && subscription.EventTypes[0] == model.TransactionPostedEvent
+	})).Return(&model.WebhookSubscription{
+		ID:         1,
+		URL:        "https://example.com/hooks",
+		Secret:     "generated-secret",
+		EventTypes: model.EventTypes{model.TransactionPostedEvent},
+		Active:     true,
+	}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"url":         "https://example.com/hooks",
+		"event_types": []string{"transaction.posted"},
+	})
+	req, err := http.NewRequest("POST", "/webhooks", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleCreateWebhook(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var response dto.CreateWebhookResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, int64(1), response.ID)
+	assert.Equal(t, "generated-secret", response.Secret)
+	assert.Equal(t, []string{"transaction.posted"}, response.EventTypes)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWebhookHandler_CreateWebhookValidationError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing url", body: `{"event_types":["transaction.posted"]}`},
+		{name: "invalid url", body: `{"url":"not a url"}`},
+		{name: "unknown event type", body: `{"url":"https://example.com/hooks","event_types":["account.deleted"]}`},
+		{name: "short secret", body: `{"url":"https://example.com/hooks","secret":"short"}`},
+		{name: "malformed body", body: `{"url":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			handler := NewWebhookHandler(mockService)
+
+			req, err := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.HandleCreateWebhook(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "CreateWebhook", mock.Anything)
+		})
+	}
+}
+
+func TestWebhookHandler_ListWebhooksSuccess(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	mockService.On("ListWebhooks").Return([]model.WebhookSubscription{
+		{ID: 1, URL: "https://example.com/hooks", Secret: "secret", Active: true},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/webhooks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListWebhooks(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), `"secret"`)
+
+	var response dto.ListWebhooksResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Len(t, response.Webhooks, 1)
+	assert.Equal(t, []string{}, response.Webhooks[0].EventTypes)
+}
+
+func TestWebhookHandler_GetWebhookNotFound(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	mockService.On("GetWebhook", int64(2)).Return(nil, internalErrors.NewNotFoundError(internalErrors.WebhookNotFound, "Webhook not found"))
+
+	req, err := http.NewRequest("GET", "/webhooks/2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withWebhookID(req, "2")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleGetWebhook(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestWebhookHandler_UpdateWebhookSuccess(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	updated := &model.WebhookSubscription{ID: 1, URL: "https://example.com/new", Active: false}
+	mockService.On("UpdateWebhook", mock.MatchedBy(func(subscription *model.WebhookSubscription) bool {
+		return subscription.ID == 1 && subscription.URL == "https://example.com/new" && !subscription.Active
+	})).Return(updated, nil)
+
+	req, err := http.NewRequest("PUT", "/webhooks/1", bytes.NewBufferString(`{"url":"https://example.com/new","active":false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withWebhookID(req, "1")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleUpdateWebhook(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.WebhookResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, "https://example.com/new", response.URL)
+	assert.False(t, response.Active)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWebhookHandler_UpdateWebhookMissingActive(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	req, err := http.NewRequest("PUT", "/webhooks/1", bytes.NewBufferString(`{"url":"https://example.com/new"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withWebhookID(req, "1")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleUpdateWebhook(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "UpdateWebhook", mock.Anything)
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	mockService.On("DeleteWebhook", int64(1)).Return(nil)
+
+	req, err := http.NewRequest("DELETE", "/webhooks/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withWebhookID(req, "1")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleDeleteWebhook(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
+
+func TestWebhookHandler_ListWebhookDeliveriesSuccess(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	nextAttemptAt := time.Date(2024, time.September, 2, 10, 0, 20, 0, time.UTC)
+	mockService.On("ListDeliveries", int64(1), model.DeliveryPending, 10).Return([]model.WebhookDelivery{
+		{ID: 3, SubscriptionID: 1, OutboxMessageID: 9, EventType: model.TransactionPostedEvent, Status: model.DeliveryPending, Attempts: 2, NextAttemptAt: nextAttemptAt, LastStatusCode: 503, LastError: "unexpected status 503"},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/webhooks/1/deliveries?status=pending&limit=10", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withWebhookID(req, "1")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleListWebhookDeliveries(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.ListWebhookDeliveriesResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Len(t, response.Deliveries, 1)
+	assert.Equal(t, int64(9), response.Deliveries[0].EventID)
+	assert.Equal(t, 503, response.Deliveries[0].LastStatusCode)
+	assert.True(t, nextAttemptAt.Equal(*response.Deliveries[0].NextAttemptAt))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWebhookHandler_ListWebhookDeliveriesValidationError(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "unknown status", query: "?status=failed"},
+		{name: "limit too large", query: "?limit=201"},
+		{name: "limit not a number", query: "?limit=ten"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			handler := NewWebhookHandler(mockService)
+
+			req, err := http.NewRequest("GET", "/webhooks/1/deliveries"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = withWebhookID(req, "1")
+
+			rr := httptest.NewRecorder()
+
+			handler.HandleListWebhookDeliveries(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "ListDeliveries", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWebhookHandler_RedriveWebhookDeliveries(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	mockService.On("RedriveDeadLetters", int64(1)).Return(int64(2), nil)
+
+	req, err := http.NewRequest("POST", "/webhooks/1/dead-letters/redrive", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withWebhookID(req, "1")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleRedriveWebhookDeliveries(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response dto.RedriveWebhookDeliveriesResponse
+	_ = json.NewDecoder(rr.Body).Decode(&response)
+
+	assert.Equal(t, int64(2), response.Redriven)
+}
+
+func TestWebhookHandler_RedriveWebhookDeliveriesInternalServerError(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	mockService.On("RedriveDeadLetters", int64(1)).Return(int64(0), errors.New("db error"))
+
+	req, err := http.NewRequest("POST", "/webhooks/1/dead-letters/redrive", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withWebhookID(req, "1")
+
+	rr := httptest.NewRecorder()
+
+	handler.HandleRedriveWebhookDeliveries(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
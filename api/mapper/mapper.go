@@ -1,6 +1,10 @@
 package mapper
 
 import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	api "github.com/gmerten/accounts_transactions/api/dto"
@@ -9,15 +13,26 @@ import (
 
 func ToCreateAccountResponse(account *model.Account) api.CreateAccountResponse {
 	return api.CreateAccountResponse{
-		DocumentNumber: account.DocumentNumber,
-		ID:             account.ID,
+		DocumentNumber:       account.DocumentNumber,
+		DocumentType:         string(account.DocumentType),
+		ID:                   account.ID,
+		AvailableCreditLimit: account.AvailableCreditLimit,
+		Status:               string(account.Status),
+		ClosingDay:           account.ClosingDay,
+		DueDay:               account.DueDay,
 	}
 }
 
 func ToGetAccountResponse(account *model.Account) api.GetAccountResponse {
 	return api.GetAccountResponse{
-		DocumentNumber: account.DocumentNumber,
-		ID:             account.ID,
+		DocumentNumber:       account.DocumentNumber,
+		DocumentType:         string(account.DocumentType),
+		ID:                   account.ID,
+		Balance:              account.Balance,
+		AvailableCreditLimit: account.AvailableCreditLimit,
+		Status:               string(account.Status),
+		ClosingDay:           account.ClosingDay,
+		DueDay:               account.DueDay,
 	}
 }
 
@@ -26,34 +41,333 @@ func ToCreateTransactionResponse(transaction *model.Transaction) api.CreateTrans
 		TransactionID:   transaction.ID,
 		AccountID:       transaction.AccountID,
 		Amount:          transaction.Amount,
+		Balance:         transaction.Balance,
 		OperationTypeID: uint(transaction.OperationType),
 	}
 }
 
-func ToAccount(request api.CreateAccountRequest) *model.Account {
-	return &model.Account{
-		DocumentNumber: request.DocumentNumber,
+func ToTransactionResponse(transaction *model.Transaction) api.TransactionResponse {
+	return api.TransactionResponse{
+		TransactionID:           transaction.ID,
+		AccountID:               transaction.AccountID,
+		Amount:                  transaction.Amount,
+		Balance:                 transaction.Balance,
+		OperationTypeID:         uint(transaction.OperationType),
+		TransactionDate:         transaction.TransactionDate,
+		Status:                  string(transaction.Status),
+		ReversedAmount:          transaction.ReversedAmount,
+		ReversalOfTransactionID: transaction.ReversalOfID,
 	}
 }
 
-func ToTransaction(request api.CreateTransactionRequest) *model.Transaction {
+func ToReversalResponse(reversal *model.Transaction, original *model.Transaction) api.ReversalResponse {
+	return api.ReversalResponse{
+		Reversal: ToTransactionResponse(reversal),
+		Original: ToTransactionResponse(original),
+	}
+}
+
+func ToListTransactionsResponse(page *model.TransactionPage) api.ListTransactionsResponse {
+	transactions := make([]api.TransactionResponse, 0, len(page.Transactions))
+	for _, transaction := range page.Transactions {
+		transactions = append(transactions, ToTransactionResponse(&transaction))
+	}
+
+	response := api.ListTransactionsResponse{Transactions: transactions}
+	if page.Next != nil {
+		response.NextCursor = EncodeTransactionCursor(*page.Next)
+	}
+	return response
+}
+
+// EncodeTransactionCursor turns a cursor into the opaque token handed to clients.
+func EncodeTransactionCursor(cursor model.TransactionCursor) string {
+	raw := cursor.TransactionDate.Format(time.RFC3339Nano) + "|" + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(token string) (*model.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	date, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, errors.New("malformed transaction cursor")
+	}
+
+	transactionDate, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		return nil, err
+	}
 
-	operationType := model.OperationType(request.OperationTypeID)
-	amount := normalizeAmount(operationType, request.Amount)
+	transactionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransactionCursor{TransactionDate: transactionDate, ID: transactionID}, nil
+}
 
+func ToAccount(request api.CreateAccountRequest) *model.Account {
+	return &model.Account{
+		DocumentNumber:       request.DocumentNumber,
+		AvailableCreditLimit: request.AvailableCreditLimit,
+		ClosingDay:           request.ClosingDay,
+		DueDay:               request.DueDay,
+	}
+}
+
+func ToTransaction(request api.CreateTransactionRequest, operationType *model.OperationTypeDefinition) *model.Transaction {
 	return &model.Transaction{
-		OperationType:   operationType,
-		Amount:          amount,
+		OperationType:   operationType.ID,
+		Amount:          operationType.SignedAmount(request.Amount),
 		AccountID:       request.AccountID,
 		TransactionDate: time.Now(),
 	}
 }
 
-func normalizeAmount(operationType model.OperationType, amount float64) float64 {
-	switch operationType {
-	case model.Payment:
-		return amount
-	default:
-		return -amount
+func ToOperationType(request api.CreateOperationTypeRequest) *model.OperationTypeDefinition {
+	active := true
+	if request.Active != nil {
+		active = *request.Active
+	}
+
+	return &model.OperationTypeDefinition{
+		ID:          model.OperationType(request.ID),
+		Description: request.Description,
+		Sign:        model.OperationSign(request.Sign),
+		Active:      active,
+	}
+}
+
+func ToOperationTypeResponse(operationType *model.OperationTypeDefinition) api.OperationTypeResponse {
+	return api.OperationTypeResponse{
+		ID:          int64(operationType.ID),
+		Description: operationType.Description,
+		Sign:        string(operationType.Sign),
+		Active:      operationType.Active,
+	}
+}
+
+func ToOperationTypesResponse(operationTypes []model.OperationTypeDefinition) []api.OperationTypeResponse {
+	response := make([]api.OperationTypeResponse, 0, len(operationTypes))
+	for i := range operationTypes {
+		response = append(response, ToOperationTypeResponse(&operationTypes[i]))
+	}
+	return response
+}
+
+func ToInstallmentResponse(installment *model.Installment) api.InstallmentResponse {
+	return api.InstallmentResponse{
+		Number:  installment.Number,
+		Amount:  installment.Amount,
+		DueDate: installment.DueDate,
+		Status:  string(installment.Status),
+	}
+}
+
+func ToInstallmentPlanResponse(plan *model.InstallmentPlan) api.InstallmentPlanResponse {
+	installments := make([]api.InstallmentResponse, 0, len(plan.Installments))
+	for _, installment := range plan.Installments {
+		installments = append(installments, ToInstallmentResponse(&installment))
+	}
+
+	return api.InstallmentPlanResponse{
+		TransactionID:    plan.Transaction.ID,
+		AccountID:        plan.Transaction.AccountID,
+		TotalAmount:      plan.Transaction.Amount.Abs(),
+		InstallmentCount: len(plan.Installments),
+		Installments:     installments,
+	}
+}
+
+func ToStatementResponse(statement *model.Statement) api.StatementResponse {
+	return api.StatementResponse{
+		AccountID:      statement.AccountID,
+		Period:         statement.Period,
+		PeriodStart:    statement.PeriodStart,
+		PeriodEnd:      statement.PeriodEnd,
+		DueDate:        statement.DueDate,
+		OpeningBalance: statement.OpeningBalance,
+		TotalDebits:    statement.TotalDebits,
+		TotalCredits:   statement.TotalCredits,
+		ClosingBalance: statement.ClosingBalance,
+		MinimumPayment: statement.MinimumPayment,
+		Closed:         statement.IsClosed(),
+		ClosedAt:       statement.ClosedAt,
+	}
+}
+
+func ToListStatementsResponse(statements []model.Statement) api.ListStatementsResponse {
+	response := make([]api.StatementResponse, 0, len(statements))
+	for i := range statements {
+		response = append(response, ToStatementResponse(&statements[i]))
+	}
+	return api.ListStatementsResponse{Statements: response}
+}
+
+func ToStatementDetailResponse(statement *model.Statement) api.StatementDetailResponse {
+	transactions := make([]api.TransactionResponse, 0, len(statement.Transactions))
+	for i := range statement.Transactions {
+		transactions = append(transactions, ToTransactionResponse(&statement.Transactions[i]))
+	}
+
+	installments := make([]api.StatementInstallmentResponse, 0, len(statement.Installments))
+	for _, installment := range statement.Installments {
+		installments = append(installments, api.StatementInstallmentResponse{
+			TransactionID: installment.TransactionID,
+			Number:        installment.Number,
+			Amount:        installment.Amount,
+			DueDate:       installment.DueDate,
+		})
+	}
+
+	return api.StatementDetailResponse{
+		StatementResponse: ToStatementResponse(statement),
+		Transactions:      transactions,
+		Installments:      installments,
+	}
+}
+
+func ToWebhookSubscription(request api.CreateWebhookRequest) *model.WebhookSubscription {
+	return &model.WebhookSubscription{
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: toEventTypes(request.EventTypes),
+		Active:     request.Active == nil || *request.Active,
+	}
+}
+
+func ToUpdatedWebhookSubscription(id int64, request api.UpdateWebhookRequest) *model.WebhookSubscription {
+	return &model.WebhookSubscription{
+		ID:         id,
+		URL:        request.URL,
+		EventTypes: toEventTypes(request.EventTypes),
+		Active:     *request.Active,
+	}
+}
+
+func toEventTypes(values []string) model.EventTypes {
+	var eventTypes model.EventTypes
+	for _, value := range values {
+		eventTypes = append(eventTypes, model.EventType(value))
+	}
+	return eventTypes
+}
+
+func ToWebhookResponse(subscription *model.WebhookSubscription) api.WebhookResponse {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	return api.WebhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: eventTypes,
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+func ToCreateWebhookResponse(subscription *model.WebhookSubscription) api.CreateWebhookResponse {
+	return api.CreateWebhookResponse{
+		WebhookResponse: ToWebhookResponse(subscription),
+		Secret:          subscription.Secret,
+	}
+}
+
+func ToListWebhooksResponse(subscriptions []model.WebhookSubscription) api.ListWebhooksResponse {
+	webhooks := make([]api.WebhookResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		webhooks = append(webhooks, ToWebhookResponse(&subscriptions[i]))
+	}
+	return api.ListWebhooksResponse{Webhooks: webhooks}
+}
+
+func ToWebhookDeliveryResponse(delivery *model.WebhookDelivery) api.WebhookDeliveryResponse {
+	response := api.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.OutboxMessageID,
+		EventType:      string(delivery.EventType),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == model.DeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return response
+}
+
+func ToListWebhookDeliveriesResponse(deliveries []model.WebhookDelivery) api.ListWebhookDeliveriesResponse {
+	response := make([]api.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		response = append(response, ToWebhookDeliveryResponse(&deliveries[i]))
+	}
+	return api.ListWebhookDeliveriesResponse{Deliveries: response}
+}
+
+func ToHealthResponse(readiness *model.Readiness) api.HealthResponse {
+	response := api.HealthResponse{Status: "ok", Checks: make(map[string]string, len(readiness.Checks))}
+	if !readiness.Ready() {
+		response.Status = "unavailable"
+	}
+	for _, check := range readiness.Checks {
+		if check.Error == "" {
+			response.Checks[check.Name] = "ok"
+		} else {
+			response.Checks[check.Name] = check.Error
+		}
+	}
+	return response
+}
+
+func ToAPIClient(request api.CreateAPIClientRequest) *model.APIClient {
+	scopes := make(model.Scopes, 0, len(request.Scopes))
+	for _, scope := range request.Scopes {
+		scopes = append(scopes, model.Scope(scope))
+	}
+
+	return &model.APIClient{
+		Name:   request.Name,
+		Scopes: scopes,
+	}
+}
+
+func ToAPIClientResponse(client *model.APIClient) api.APIClientResponse {
+	scopes := make([]string, 0, len(client.Scopes))
+	for _, scope := range client.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return api.APIClientResponse{
+		ID:        client.ID,
+		KeyPrefix: client.KeyPrefix,
+		Name:      client.Name,
+		Scopes:    scopes,
+		Active:    client.Active,
+		CreatedAt: client.CreatedAt,
+		RotatedAt: client.RotatedAt,
+	}
+}
+
+func ToAPIClientKeyResponse(client *model.APIClient, key string) api.APIClientKeyResponse {
+	return api.APIClientKeyResponse{
+		APIClientResponse: ToAPIClientResponse(client),
+		Key:               key,
+	}
+}
+
+func ToListAPIClientsResponse(clients []model.APIClient) api.ListAPIClientsResponse {
+	response := make([]api.APIClientResponse, 0, len(clients))
+	for i := range clients {
+		response = append(response, ToAPIClientResponse(&clients[i]))
 	}
+	return api.ListAPIClientsResponse{Clients: response}
 }
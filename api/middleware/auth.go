@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	api "github.com/gmerten/accounts_transactions/api/handler"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	AuthorizationHeader   = "Authorization"
+	WWWAuthenticateHeader = "WWW-Authenticate"
+	bearerScheme          = "Bearer"
+)
+
+type apiClientKey struct{}
+
+// Authorize returns a middleware for each scope, which authenticates the API
+// key sent as a bearer token in the Authorization header and rejects clients
+// that were not granted the scope. The key itself is never logged.
+func Authorize(apiClientService service.APIClientService) func(scope model.Scope) func(http.Handler) http.Handler {
+	return func(scope model.Scope) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header := r.Header.Get(AuthorizationHeader)
+				if header == "" {
+					unauthorized(w, r, internalErrors.NewUnauthorizedError(internalErrors.APIKeyRequired, "Authorization header with a Bearer API key is required"))
+					return
+				}
+
+				scheme, key, found := strings.Cut(header, " ")
+				if !found || !strings.EqualFold(scheme, bearerScheme) || key == "" {
+					unauthorized(w, r, internalErrors.NewUnauthorizedError(internalErrors.InvalidAPIKey, "Authorization header must be Bearer followed by the API key"))
+					return
+				}
+
+				client, err := apiClientService.Authenticate(r.Context(), strings.TrimSpace(key))
+				if err != nil {
+					_, ok := err.(api.CustomError)
+					if ok {
+						unauthorized(w, r, err)
+						return
+					}
+					api.HandleError(w, r, internalErrors.NewUnknownError("Error authenticating API key"))
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), apiClientKey{}, client)
+				logging.AddField(ctx, "apiClientID", client.ID)
+				trace.SpanFromContext(ctx).SetAttributes(tracing.APIClientIDKey.Int64(client.ID))
+
+				if !client.HasScope(scope) {
+					logging.FromContext(ctx).WithField("scope", scope).Warn("API client lacks the scope of the route")
+					api.HandleError(w, r, internalErrors.NewForbiddenError(internalErrors.InsufficientScope, "API key lacks the "+string(scope)+" scope"))
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		}
+	}
+}
+
+// APIClientFromContext returns the client authenticated by Authorize.
+func APIClientFromContext(ctx context.Context) (*model.APIClient, bool) {
+	client, ok := ctx.Value(apiClientKey{}).(*model.APIClient)
+	return client, ok
+}
+
+// unauthorized answers with err and, for 401 errors, the challenge of the
+// bearer scheme.
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	if customErr, ok := err.(api.CustomError); ok && customErr.StatusCode() == http.StatusUnauthorized {
+		w.Header().Set(WWWAuthenticateHeader, bearerScheme)
+	}
+	api.HandleError(w, r, err)
+}
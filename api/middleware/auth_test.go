@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/gmerten/accounts_transactions/api/handler"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIKey = "ak_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestAuthorize(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	mockService.On("Authenticate", testAPIKey).Return(&model.APIClient{ID: 7, Scopes: model.Scopes{model.ScopeAccountsRead}, Active: true}, nil)
+	mockService.On("Authenticate", "ak_unknown").Return(nil, internalErrors.NewUnauthorizedError(internalErrors.InvalidAPIKey, "Invalid API key"))
+	mockService.On("Authenticate", "ak_failing").Return(nil, errors.New("database error"))
+
+	authorize := Authorize(mockService)
+
+	tests := []struct {
+		name          string
+		scope         model.Scope
+		header        string
+		wantStatus    int
+		wantCode      string
+		wantChallenge bool
+	}{
+		{name: "granted scope", scope: model.ScopeAccountsRead, header: "Bearer " + testAPIKey, wantStatus: http.StatusOK},
+		{name: "case insensitive scheme", scope: model.ScopeAccountsRead, header: "bearer " + testAPIKey, wantStatus: http.StatusOK},
+		{name: "missing header", scope: model.ScopeAccountsRead, wantStatus: http.StatusUnauthorized, wantCode: "API_KEY_REQUIRED", wantChallenge: true},
+		{name: "other scheme", scope: model.ScopeAccountsRead, header: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized, wantCode: "INVALID_API_KEY", wantChallenge: true},
+		{name: "unknown key", scope: model.ScopeAccountsRead, header: "Bearer ak_unknown", wantStatus: http.StatusUnauthorized, wantCode: "INVALID_API_KEY", wantChallenge: true},
+		{name: "missing scope", scope: model.ScopeAccountsWrite, header: "Bearer " + testAPIKey, wantStatus: http.StatusForbidden, wantCode: "INSUFFICIENT_SCOPE"},
+		{name: "lookup error", scope: model.ScopeAccountsRead, header: "Bearer ak_failing", wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var client *model.APIClient
+			handler := authorize(tt.scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				client, _ = APIClientFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req, err := http.NewRequest("GET", "/accounts/1", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set(AuthorizationHeader, tt.header)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantChallenge, rr.Header().Get(WWWAuthenticateHeader) == "Bearer")
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, int64(7), client.ID)
+				return
+			}
+
+			var problem api.Problem
+			_ = json.NewDecoder(rr.Body).Decode(&problem)
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.Nil(t, client)
+		})
+	}
+}
+
+func TestAuthorize_AdminHasEveryScope(t *testing.T) {
+	mockService := new(MockAPIClientService)
+	mockService.On("Authenticate", testAPIKey).Return(&model.APIClient{ID: 1, Scopes: model.Scopes{model.ScopeAdmin}, Active: true}, nil)
+
+	for _, scope := range []model.Scope{model.ScopeAccountsRead, model.ScopeAccountsWrite, model.ScopeTransactionsWrite, model.ScopeAdmin} {
+		handler := Authorize(mockService)(scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		req, err := http.NewRequest("GET", "/admin/api-clients", http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(AuthorizationHeader, "Bearer "+testAPIKey)
+
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code, scope)
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyService struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyService) Begin(ctx context.Context, key string, scope string, requestHash string) (*model.IdempotencyKey, error) {
+	args := m.Called(key, scope, requestHash)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.IdempotencyKey), err
+}
+
+func (m *MockIdempotencyService) Complete(ctx context.Context, key string, scope string, statusCode int, responseBody []byte) error {
+	args := m.Called(key, scope, statusCode, responseBody)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) Release(ctx context.Context, key string, scope string) error {
+	args := m.Called(key, scope)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) PurgeExpired(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockAPIClientService struct {
+	mock.Mock
+}
+
+func (m *MockAPIClientService) IssueClient(ctx context.Context, client *model.APIClient) (*model.APIClient, string, error) {
+	args := m.Called(client)
+	res := args.Get(0)
+	err := args.Error(2)
+
+	if err != nil {
+		return nil, "", err
+	}
+	return res.(*model.APIClient), args.String(1), err
+}
+
+func (m *MockAPIClientService) GetClient(ctx context.Context, id int64) (*model.APIClient, error) {
+	args := m.Called(id)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
+
+func (m *MockAPIClientService) ListClients(ctx context.Context) ([]model.APIClient, error) {
+	args := m.Called()
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.APIClient), err
+}
+
+func (m *MockAPIClientService) SetClientActive(ctx context.Context, id int64, active bool) (*model.APIClient, error) {
+	args := m.Called(id, active)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
+
+func (m *MockAPIClientService) RotateKey(ctx context.Context, id int64) (*model.APIClient, string, error) {
+	args := m.Called(id)
+	res := args.Get(0)
+	err := args.Error(2)
+
+	if err != nil {
+		return nil, "", err
+	}
+	return res.(*model.APIClient), args.String(1), err
+}
+
+func (m *MockAPIClientService) RevokeClient(ctx context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAPIClientService) Authenticate(ctx context.Context, key string) (*model.APIClient, error) {
+	args := m.Called(key)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	api "github.com/gmerten/accounts_transactions/api/handler"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/service"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// Idempotency makes the wrapped routes safe to retry. The first response to a
+// request carrying an Idempotency-Key is stored and replayed for retries with
+// the same key and payload, while reusing the key for another payload is
+// rejected. Server errors and panics are not stored, so they can be retried.
+func Idempotency(idempotencyService service.IdempotencyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				api.HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidIdempotencyKey, "Idempotency-Key must have at most 255 characters"))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				api.HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Request body must have at most 1 MiB"))
+				return
+			}
+			if err != nil {
+				logging.FromContext(r.Context()).WithError(err).Error("Error reading request body")
+				api.HandleError(w, r, internalErrors.NewValidationError(internalErrors.InvalidRequestBody, "Invalid request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Keys are scoped to the client too, so a client never gets the
+			// response stored for another one.
+			scope := r.Method + " " + r.URL.Path
+			if client, ok := APIClientFromContext(r.Context()); ok {
+				scope = "client " + strconv.FormatInt(client.ID, 10) + " " + scope
+			}
+
+			idempotencyKey, err := idempotencyService.Begin(r.Context(), key, scope, hashRequestBody(body))
+			if err != nil {
+				_, ok := err.(api.CustomError)
+				if ok {
+					api.HandleError(w, r, err)
+					return
+				}
+				api.HandleError(w, r, internalErrors.NewUnknownError("Error checking Idempotency-Key"))
+				return
+			}
+
+			if idempotencyKey.Completed {
+				contentType := "application/json"
+				if idempotencyKey.StatusCode >= http.StatusBadRequest {
+					contentType = api.ProblemContentType
+				}
+				w.Header().Set("Content-Type", contentType)
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(idempotencyKey.StatusCode)
+				_, _ = w.Write(idempotencyKey.ResponseBody)
+				return
+			}
+
+			// The outcome is saved even if the client went away meanwhile,
+			// otherwise the key stays in use and its retries are rejected.
+			ctx := context.WithoutCancel(r.Context())
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					releaseIdempotencyKey(ctx, idempotencyService, key, scope)
+					panic(recovered)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.statusCode >= http.StatusInternalServerError {
+				releaseIdempotencyKey(ctx, idempotencyService, key, scope)
+				return
+			}
+
+			if err := idempotencyService.Complete(ctx, key, scope, recorder.statusCode, recorder.body.Bytes()); err != nil {
+				logging.FromContext(ctx).WithError(err).Warn("Idempotency-Key stays in use until its lease ends, the response could not be saved")
+			}
+		})
+	}
+}
+
+// releaseIdempotencyKey frees the key of a request that failed, so it can be
+// retried right away.
+func releaseIdempotencyKey(ctx context.Context, idempotencyService service.IdempotencyService, key string, scope string) {
+	if err := idempotencyService.Release(ctx, key, scope); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Idempotency-Key stays in use until its lease ends, it could not be released")
+	}
+}
+
+// hashRequestBody fingerprints the payload. JSON bodies are hashed in a
+// canonical form, so formatting and key order do not make retries differ.
+func hashRequestBody(body []byte) string {
+	var payload interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err == nil {
+		if canonical, err := json.Marshal(payload); err == nil {
+			body = canonical
+		}
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,324 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestHandler(statusCode int, body string, calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestIdempotency_WithoutKey(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{}`, &calls))
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"amount": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, 1, calls)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_FirstRequestStored(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{"transaction_id":1}`, &calls))
+
+	mockService.On("Begin", "key-1", "POST /transactions", hashRequestBody([]byte(`{"amount":1,"account_id":1}`))).
+		Return(&model.IdempotencyKey{Key: "key-1"}, nil)
+	mockService.On("Complete", "key-1", "POST /transactions", http.StatusCreated, []byte(`{"transaction_id":1}`)).
+		Return(nil)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"account_id": 1, "amount": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `{"transaction_id":1}`, rr.Body.String())
+	assert.Empty(t, rr.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, calls)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_ScopedToClient(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{"transaction_id":1}`, &calls))
+
+	mockService.On("Begin", "key-1", "client 7 POST /transactions", mock.Anything).
+		Return(&model.IdempotencyKey{Key: "key-1"}, nil)
+	mockService.On("Complete", "key-1", "client 7 POST /transactions", http.StatusCreated, mock.Anything).
+		Return(nil)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"account_id": 1, "amount": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), apiClientKey{}, &model.APIClient{ID: 7}))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{}`, &calls))
+
+	mockService.On("Begin", "key-1", "POST /transactions", mock.Anything).Return(&model.IdempotencyKey{
+		Key:          "key-1",
+		Completed:    true,
+		StatusCode:   http.StatusCreated,
+		ResponseBody: []byte(`{"transaction_id":1}`),
+	}, nil)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"amount": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `{"transaction_id":1}`, rr.Body.String())
+	assert.Equal(t, "true", rr.Header().Get(IdempotentReplayedHeader))
+	assert.Zero(t, calls)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_CustomError(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{}`, &calls))
+
+	mockService.On("Begin", "key-1", "POST /transactions", mock.Anything).
+		Return(nil, internalErrors.NewUnprocessableEntityError(internalErrors.IdempotencyKeyReused, "different payload"))
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"amount": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Zero(t, calls)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_GenericError(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{}`, &calls))
+
+	mockService.On("Begin", "key-1", "POST /transactions", mock.Anything).Return(nil, errors.New("generic error"))
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"amount": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Zero(t, calls)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusInternalServerError, `{}`, &calls))
+
+	mockService.On("Begin", "key-1", "POST /transactions", mock.Anything).Return(&model.IdempotencyKey{Key: "key-1"}, nil)
+	mockService.On("Release", "key-1", "POST /transactions").Return(nil)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"amount": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, 1, calls)
+
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	handler := Idempotency(mockService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	mockService.On("Begin", "key-1", "POST /transactions", mock.Anything).Return(&model.IdempotencyKey{Key: "key-1"}, nil)
+	mockService.On("Release", "key-1", "POST /transactions").Return(nil)
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"amount": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	assert.PanicsWithValue(t, "boom", func() { handler.ServeHTTP(rr, req) })
+
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotency_CompleteErrorKeepsResponse(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{"id": 1}`, &calls))
+
+	mockService.On("Begin", "key-1", "POST /transactions", mock.Anything).Return(&model.IdempotencyKey{Key: "key-1"}, nil)
+	mockService.On("Complete", "key-1", "POST /transactions", http.StatusCreated, mock.Anything).Return(errors.New("database is down"))
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{"amount": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `{"id": 1}`, rr.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{}`, &calls))
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewBufferString(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, string(bytes.Repeat([]byte("k"), 256)))
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Zero(t, calls)
+}
+
+type cancelCheckingIdempotencyService struct {
+	*MockIdempotencyService
+	completeErr error
+}
+
+func (s *cancelCheckingIdempotencyService) Complete(ctx context.Context, key string, scope string, statusCode int, responseBody []byte) error {
+	s.completeErr = ctx.Err()
+	return s.MockIdempotencyService.Complete(ctx, key, scope, statusCode, responseBody)
+}
+
+func TestIdempotency_CompletedAfterClientGone(t *testing.T) {
+	mockService := &cancelCheckingIdempotencyService{MockIdempotencyService: new(MockIdempotencyService)}
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := Idempotency(mockService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	mockService.On("Begin", "key-1", "POST /transactions", mock.Anything).Return(&model.IdempotencyKey{Key: "key-1"}, nil)
+	mockService.On("Complete", "key-1", "POST /transactions", http.StatusCreated, mock.Anything).Return(nil)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "/transactions", bytes.NewBufferString(`{"amount": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.NoError(t, mockService.completeErr)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	calls := 0
+	handler := Idempotency(mockService)(newTestHandler(http.StatusCreated, `{}`, &calls))
+
+	req, err := http.NewRequest("POST", "/transactions", bytes.NewReader(bytes.Repeat([]byte(" "), maxIdempotentRequestBytes+1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), string(internalErrors.InvalidRequestBody))
+	assert.Zero(t, calls)
+
+	mockService.AssertNotCalled(t, "Begin", mock.Anything, mock.Anything, mock.Anything)
+}
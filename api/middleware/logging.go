@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID that ties together the log lines of a
+// request. It is taken from the request when the caller sends one and is
+// always returned in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from callers.
+const maxRequestIDLength = 128
+
+// RequestLogger gives every request an ID, propagated from the X-Request-ID
+// header when it has a valid one, and a logger carrying it, along with the
+// trace ID when the request is traced. Once the request is served, it logs a
+// single access line with the route, status, latency and size of the response,
+// and the fields the handlers and services added, such as the account.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		entry := log.WithField("requestID", requestID)
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			entry = entry.WithField("traceID", spanContext.TraceID().String())
+		}
+		ctx := logging.NewContext(logging.WithRequestID(r.Context(), requestID), entry)
+
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := responseStatus(ww)
+		access := logging.FromContext(ctx).WithFields(log.Fields{
+			"method":    r.Method,
+			"route":     routePattern(r),
+			"path":      r.URL.Path,
+			"status":    status,
+			"latencyMs": float64(time.Since(start).Microseconds()) / 1000,
+			"bytes":     ww.BytesWritten(),
+		})
+		if status >= http.StatusInternalServerError {
+			access.Error("Request completed")
+			return
+		}
+		access.Info("Request completed")
+	})
+}
+
+// validRequestID accepts IDs of printable ASCII characters without spaces, so
+// a caller cannot forge log lines through the header.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogger(t *testing.T) {
+	hook := logTest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	var requestID string
+	router := chi.NewRouter()
+	router.Use(RequestLogger)
+	router.Get("/logging-test/{accountID}", func(w http.ResponseWriter, r *http.Request) {
+		requestID = logging.RequestID(r.Context())
+		logging.AddField(r.Context(), "accountID", int64(42))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"propagated", "upstream-42", "upstream-42"},
+		{"missing", "", ""},
+		{"with spaces", "forged\nline", ""},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hook.Reset()
+
+			req, err := http.NewRequest("GET", "/logging-test/42", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.header != "" {
+				req.Header.Set(RequestIDHeader, test.header)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			returned := rr.Header().Get(RequestIDHeader)
+			if test.want != "" {
+				assert.Equal(t, test.want, returned)
+			} else {
+				assert.Regexp(t, "^[0-9a-f]{32}$", returned)
+			}
+			assert.Equal(t, returned, requestID)
+
+			require.Len(t, hook.Entries, 1)
+			entry := hook.LastEntry()
+			assert.Equal(t, log.ErrorLevel, entry.Level)
+			assert.Equal(t, "Request completed", entry.Message)
+			assert.Equal(t, returned, entry.Data["requestID"])
+			assert.Equal(t, int64(42), entry.Data["accountID"])
+			assert.Equal(t, "/logging-test/{accountID}", entry.Data["route"])
+			assert.Equal(t, http.StatusServiceUnavailable, entry.Data["status"])
+			assert.Equal(t, 2, entry.Data["bytes"])
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/metrics"
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Metrics records the count and latency of every request, labelled with the
+// route pattern it matched, such as /accounts/{accountID}, rather than its
+// path, so each route is a single series.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		metrics.ObserveRequest(r.Method, routePattern(r), responseStatus(ww), time.Since(start))
+	})
+}
+
+// routePattern returns the pattern of the route the request matched, once it
+// has been routed.
+func routePattern(r *http.Request) string {
+	if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+		if pattern := routeContext.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return metrics.UnmatchedRoute
+}
+
+// responseStatus returns the status written to ww, which is 200 when the
+// handler wrote the body without calling WriteHeader.
+func responseStatus(ww chiMiddleware.WrapResponseWriter) int {
+	if status := ww.Status(); status != 0 {
+		return status
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrapeMetrics() string {
+	rr := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	return rr.Body.String()
+}
+
+func TestMetrics_RoutePattern(t *testing.T) {
+	calls := 0
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Method("GET", "/metrics-test/{accountID}", newTestHandler(http.StatusAccepted, `{}`, &calls))
+
+	req, err := http.NewRequest("GET", "/metrics-test/42", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, scrapeMetrics(), `http_requests_total{method="GET",route="/metrics-test/{accountID}",status="202"} 1`)
+}
+
+func TestMetrics_UnmatchedRoute(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Get("/known", func(w http.ResponseWriter, r *http.Request) {})
+
+	req, err := http.NewRequest("DELETE", "/unknown/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, scrapeMetrics(), `http_requests_total{method="DELETE",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, scrapeMetrics(), "/unknown/path")
+}
+
+func TestMetrics_ImplicitStatus(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Get("/metrics-implicit", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	req, err := http.NewRequest("GET", "/metrics-implicit", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, scrapeMetrics(), `http_request_duration_seconds_count{method="GET",route="/metrics-implicit",status="200"} 1`)
+}
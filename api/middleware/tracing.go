@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Tracing starts the server span of every request, continuing the trace of
+// its traceparent header, and hands the span to the handlers through the
+// request context. The span is named after the route pattern, like the
+// request metrics.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.StartRequest(r)
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		tracing.EndRequest(span, r.Method, routePattern(r), responseStatus(ww))
+	})
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing_SpanPerRoute(t *testing.T) {
+	exporter := tracing.InMemory()
+
+	var handlerSpan trace.SpanContext
+	router := chi.NewRouter()
+	router.Use(Tracing)
+	router.Get("/tracing-test/{accountID}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	req, err := http.NewRequest("GET", "/tracing-test/42", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /tracing-test/{accountID}", spans[0].Name)
+	assert.Equal(t, spans[0].SpanContext.SpanID(), handlerSpan.SpanID())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handlerSpan.TraceID().String())
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/service"
+)
+
+const apiClientsUsage = "usage: api-clients issue NAME SCOPE... | api-clients list"
+
+var scopes = []model.Scope{model.ScopeAccountsRead, model.ScopeAccountsWrite, model.ScopeTransactionsWrite, model.ScopeAdmin}
+
+// runAPIClients runs the api-clients subcommand and returns the exit status.
+// issue registers a client with the given scopes and prints its key, which is
+// how the first admin key is made, and list prints the clients without their
+// keys.
+func runAPIClients(apiClientService service.APIClientService, args []string, out io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(out, apiClientsUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "issue":
+		if len(args) < 3 {
+			fmt.Fprintln(out, apiClientsUsage)
+			return 2
+		}
+
+		client := &model.APIClient{Name: args[1]}
+		for _, scope := range args[2:] {
+			if !slices.Contains(scopes, model.Scope(scope)) {
+				fmt.Fprintf(out, "unknown scope %q, expected accounts:read, accounts:write, transactions:write or admin\n", scope)
+				return 2
+			}
+			client.Scopes = append(client.Scopes, model.Scope(scope))
+		}
+
+		client, key, err := apiClientService.IssueClient(context.Background(), client)
+		if err != nil {
+			fmt.Fprintf(out, "api-clients issue failed: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "issued API client %d, its key is only shown once:\n%s\n", client.ID, key)
+	case "list":
+		if len(args) != 1 {
+			fmt.Fprintln(out, apiClientsUsage)
+			return 2
+		}
+
+		clients, err := apiClientService.ListClients(context.Background())
+		if err != nil {
+			fmt.Fprintf(out, "api-clients list failed: %v\n", err)
+			return 1
+		}
+
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tNAME\tKEY PREFIX\tSCOPES\tACTIVE")
+		for _, client := range clients {
+			names := make([]string, 0, len(client.Scopes))
+			for _, scope := range client.Scopes {
+				names = append(names, string(scope))
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%t\n", client.ID, client.Name, client.KeyPrefix, strings.Join(names, ","), client.Active)
+		}
+		_ = writer.Flush()
+	default:
+		fmt.Fprintln(out, apiClientsUsage)
+		return 2
+	}
+
+	return 0
+}
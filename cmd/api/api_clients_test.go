@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/apikey"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAPIClients(t *testing.T) {
+	apiClientService := service.NewAPIClientService(repository.NewAPIClientRepository(setupTestDB()))
+
+	var out bytes.Buffer
+	assert.Equal(t, 0, runAPIClients(apiClientService, []string{"issue", "ops", "admin"}, &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "issued API client 1, its key is only shown once:", lines[0])
+	assert.True(t, apikey.LooksValid(lines[1]))
+
+	out.Reset()
+	assert.Equal(t, 0, runAPIClients(apiClientService, []string{"list"}, &out))
+	assert.Contains(t, out.String(), "ops")
+	assert.Contains(t, out.String(), apikey.Prefix(lines[1]))
+	assert.NotContains(t, out.String(), lines[1])
+}
+
+func TestRunAPIClientsUsage(t *testing.T) {
+	var out bytes.Buffer
+
+	assert.Equal(t, 2, runAPIClients(nil, nil, &out))
+	assert.Equal(t, 2, runAPIClients(nil, []string{"issue", "ops"}, &out))
+	assert.Equal(t, 2, runAPIClients(nil, []string{"issue", "ops", "root"}, &out))
+	assert.Equal(t, 2, runAPIClients(nil, []string{"list", "all"}, &out))
+	assert.Equal(t, 2, runAPIClients(nil, []string{"revoke"}, &out))
+	assert.Contains(t, out.String(), apiClientsUsage)
+}
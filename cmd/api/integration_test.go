@@ -4,19 +4,33 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	dto "github.com/gmerten/accounts_transactions/api/dto"
 	api "github.com/gmerten/accounts_transactions/api/handler"
+	apiMiddleware "github.com/gmerten/accounts_transactions/api/middleware"
+	"github.com/gmerten/accounts_transactions/internal/apikey"
+	"github.com/gmerten/accounts_transactions/internal/metrics"
+	"github.com/gmerten/accounts_transactions/internal/migration"
 	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/publisher"
 	"github.com/gmerten/accounts_transactions/internal/repository"
 	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"github.com/gmerten/accounts_transactions/internal/webhook"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	log "github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -26,7 +40,7 @@ func TestE2E_CreateAccountAndTransaction(t *testing.T) {
 	router := setupTest()
 
 	createAccountRequest := dto.CreateAccountRequest{
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	createAccountJSON, _ := json.Marshal(createAccountRequest)
@@ -65,7 +79,7 @@ func TestE2E_CreateAccountAndTransaction(t *testing.T) {
 
 	createTransactionRequest := dto.CreateTransactionRequest{
 		AccountID:       1,
-		Amount:          1000,
+		Amount:          model.MustParseMoney("1000.00"),
 		OperationTypeID: 4,
 	}
 
@@ -86,35 +100,1423 @@ func TestE2E_CreateAccountAndTransaction(t *testing.T) {
 	assert.Equal(t, returnedAccount.ID, returnedTransaction.AccountID)
 	assert.Equal(t, createTransactionRequest.Amount, returnedTransaction.Amount)
 
+	rGetAccount = httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+accountIDParam, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetAccount, req)
+
+	var returnedBalance dto.GetAccountResponse
+	_ = json.NewDecoder(rGetAccount.Body).Decode(&returnedBalance)
+
+	assert.Equal(t, http.StatusOK, rGetAccount.Code)
+	assert.Equal(t, createTransactionRequest.Amount, returnedBalance.Balance)
+
+}
+
+func TestE2E_PaymentDischargesPurchases(t *testing.T) {
+
+	router := setupTest()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "87654321937"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	assert.Equal(t, http.StatusCreated, rCreateAccount.Code)
+
+	requests := []dto.CreateTransactionRequest{
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("50.00"), OperationTypeID: 1},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("25.00"), OperationTypeID: 3},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("60.00"), OperationTypeID: 4},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("40.00"), OperationTypeID: 4},
+	}
+	expectedBalances := []model.Money{
+		model.MustParseMoney("-50.00"),
+		model.MustParseMoney("-25.00"),
+		model.MustParseMoney("0.00"),
+		model.MustParseMoney("25.00"),
+	}
+
+	for i, createTransactionRequest := range requests {
+		createTransactionJSON, _ := json.Marshal(createTransactionRequest)
+		rCreateTransaction := httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCreateTransaction, req)
+
+		var returnedTransaction dto.CreateTransactionResponse
+		_ = json.NewDecoder(rCreateTransaction.Body).Decode(&returnedTransaction)
+
+		assert.Equal(t, http.StatusCreated, rCreateTransaction.Code)
+		assert.Equal(t, expectedBalances[i], returnedTransaction.Balance)
+	}
+
+	rGetAccount := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(returnedAccount.ID, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetAccount, req)
+
+	var account dto.GetAccountResponse
+	_ = json.NewDecoder(rGetAccount.Body).Decode(&account)
+
+	assert.Equal(t, model.MustParseMoney("25.00"), account.Balance)
+
+	rListTransactions := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(returnedAccount.ID, 10)+"/transactions?limit=3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rListTransactions, req)
+
+	var firstPage dto.ListTransactionsResponse
+	_ = json.NewDecoder(rListTransactions.Body).Decode(&firstPage)
+
+	assert.Equal(t, http.StatusOK, rListTransactions.Code)
+	assert.Len(t, firstPage.Transactions, 3)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	rListTransactions = httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(returnedAccount.ID, 10)+"/transactions?limit=3&cursor="+firstPage.NextCursor, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rListTransactions, req)
+
+	var secondPage dto.ListTransactionsResponse
+	_ = json.NewDecoder(rListTransactions.Body).Decode(&secondPage)
+
+	assert.Equal(t, http.StatusOK, rListTransactions.Code)
+	assert.Len(t, secondPage.Transactions, 1)
+	assert.Empty(t, secondPage.NextCursor)
+	assert.Equal(t, model.MustParseMoney("-50.00"), secondPage.Transactions[0].Amount)
+}
+
+func TestE2E_IdempotentTransactionRetry(t *testing.T) {
+
+	router := setupTest()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "11223344517"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	createTransactionJSON, _ := json.Marshal(dto.CreateTransactionRequest{
+		AccountID:       returnedAccount.ID,
+		Amount:          model.MustParseMoney("10.00"),
+		OperationTypeID: 1,
+	})
+
+	var responses []*httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		rCreateTransaction := httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Idempotency-Key", "retry-key")
+
+		router.ServeHTTP(rCreateTransaction, req)
+		responses = append(responses, rCreateTransaction)
+	}
+
+	assert.Equal(t, http.StatusCreated, responses[0].Code)
+	assert.Equal(t, http.StatusCreated, responses[1].Code)
+	assert.Equal(t, responses[0].Body.String(), responses[1].Body.String())
+	assert.Equal(t, "true", responses[1].Header().Get("Idempotent-Replayed"))
+
+	rGetAccount := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(returnedAccount.ID, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetAccount, req)
+
+	var account dto.GetAccountResponse
+	_ = json.NewDecoder(rGetAccount.Body).Decode(&account)
+
+	assert.Equal(t, model.MustParseMoney("-10.00"), account.Balance)
+
+	otherTransactionJSON, _ := json.Marshal(dto.CreateTransactionRequest{
+		AccountID:       returnedAccount.ID,
+		Amount:          model.MustParseMoney("20.00"),
+		OperationTypeID: 1,
+	})
+
+	rCreateTransaction := httptest.NewRecorder()
+
+	req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(otherTransactionJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Idempotency-Key", "retry-key")
+
+	router.ServeHTTP(rCreateTransaction, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rCreateTransaction.Code)
+}
+
+func TestE2E_CreditLimit(t *testing.T) {
+
+	router := setupTest()
+
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"document_number": "55667788950", "available_credit_limit": 100}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	assert.Equal(t, http.StatusCreated, rCreateAccount.Code)
+
+	requests := []dto.CreateTransactionRequest{
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("80.00"), OperationTypeID: 1},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("20.01"), OperationTypeID: 3},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("30.00"), OperationTypeID: 4},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("50.00"), OperationTypeID: 3},
+	}
+	expectedStatuses := []int{http.StatusCreated, http.StatusUnprocessableEntity, http.StatusCreated, http.StatusCreated}
+
+	for i, createTransactionRequest := range requests {
+		createTransactionJSON, _ := json.Marshal(createTransactionRequest)
+		rCreateTransaction := httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCreateTransaction, req)
+
+		assert.Equal(t, expectedStatuses[i], rCreateTransaction.Code)
+	}
+
+	rGetAccount := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(returnedAccount.ID, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetAccount, req)
+
+	var account dto.GetAccountResponse
+	_ = json.NewDecoder(rGetAccount.Body).Decode(&account)
+
+	assert.Equal(t, model.MustParseMoney("0.00"), *account.AvailableCreditLimit)
+	assert.Equal(t, model.MustParseMoney("-100.00"), account.Balance)
+}
+
+func TestE2E_ReverseTransaction(t *testing.T) {
+
+	router := setupTest()
+
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"document_number": "99887766593", "available_credit_limit": 500}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	assert.Equal(t, http.StatusCreated, rCreateAccount.Code)
+
+	createTransactionJSON, _ := json.Marshal(dto.CreateTransactionRequest{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("120.00"), OperationTypeID: 1})
+	rCreateTransaction := httptest.NewRecorder()
+
+	req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateTransaction, req)
+
+	var purchase dto.CreateTransactionResponse
+	_ = json.NewDecoder(rCreateTransaction.Body).Decode(&purchase)
+
+	assert.Equal(t, http.StatusCreated, rCreateTransaction.Code)
+
+	reversalsURL := "/transactions/" + strconv.FormatInt(purchase.TransactionID, 10) + "/reversals"
+	bodies := []string{`{"amount": 20}`, `{"amount": 100.01}`, ``, `{}`}
+	expectedStatuses := []int{http.StatusCreated, http.StatusUnprocessableEntity, http.StatusCreated, http.StatusUnprocessableEntity}
+
+	var reversals []dto.ReversalResponse
+	for i, body := range bodies {
+		rCreateReversal := httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", reversalsURL, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCreateReversal, req)
+
+		assert.Equal(t, expectedStatuses[i], rCreateReversal.Code)
+
+		if rCreateReversal.Code == http.StatusCreated {
+			var reversal dto.ReversalResponse
+			_ = json.NewDecoder(rCreateReversal.Body).Decode(&reversal)
+			reversals = append(reversals, reversal)
+		}
+	}
+
+	assert.Len(t, reversals, 2)
+	assert.Equal(t, model.MustParseMoney("20.00"), reversals[0].Reversal.Amount)
+	assert.Equal(t, purchase.TransactionID, *reversals[0].Reversal.ReversalOfTransactionID)
+	assert.Equal(t, "partially_reversed", reversals[0].Original.Status)
+	assert.Equal(t, model.MustParseMoney("100.00"), reversals[1].Reversal.Amount)
+	assert.Equal(t, "reversed", reversals[1].Original.Status)
+	assert.Zero(t, reversals[1].Original.Balance)
+
+	rReverseReversal := httptest.NewRecorder()
+
+	req, err = http.NewRequest("POST", "/transactions/"+strconv.FormatInt(reversals[0].Reversal.TransactionID, 10)+"/reversals", bytes.NewBuffer(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rReverseReversal, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rReverseReversal.Code)
+
+	rGetAccount := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(returnedAccount.ID, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetAccount, req)
+
+	var account dto.GetAccountResponse
+	_ = json.NewDecoder(rGetAccount.Body).Decode(&account)
+
+	assert.Equal(t, model.MustParseMoney("500.00"), *account.AvailableCreditLimit)
+	assert.Equal(t, model.MustParseMoney("0.00"), account.Balance)
+}
+
+func TestE2E_DocumentNumberNormalization(t *testing.T) {
+
+	router := setupTest()
+
+	documentNumbers := []string{"529.982.247-25", "52998224725", "11.222.333/0001-81"}
+	expectedStatuses := []int{http.StatusCreated, http.StatusConflict, http.StatusCreated}
+
+	var accounts []dto.CreateAccountResponse
+	for i, documentNumber := range documentNumbers {
+		createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: documentNumber})
+		rCreateAccount := httptest.NewRecorder()
+
+		req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCreateAccount, req)
+
+		assert.Equal(t, expectedStatuses[i], rCreateAccount.Code, documentNumber)
+
+		if rCreateAccount.Code == http.StatusCreated {
+			var returnedAccount dto.CreateAccountResponse
+			_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+			accounts = append(accounts, returnedAccount)
+			continue
+		}
+
+		var problem api.Problem
+		_ = json.NewDecoder(rCreateAccount.Body).Decode(&problem)
+
+		assert.Equal(t, api.ProblemContentType, rCreateAccount.Header().Get("Content-Type"))
+		assert.Equal(t, "DUPLICATE_DOCUMENT", problem.Code)
+		assert.Equal(t, "/accounts", problem.Instance)
+		assert.Equal(t, rCreateAccount.Header().Get(apiMiddleware.RequestIDHeader), problem.RequestID)
+	}
+
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, "52998224725", accounts[0].DocumentNumber)
+	assert.Equal(t, "cpf", accounts[0].DocumentType)
+	assert.Equal(t, "11222333000181", accounts[1].DocumentNumber)
+	assert.Equal(t, "cnpj", accounts[1].DocumentType)
+}
+
+func TestE2E_AccountLifecycle(t *testing.T) {
+
+	router := setupTest()
+
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"document_number": "44332211049"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	assert.Equal(t, http.StatusCreated, rCreateAccount.Code)
+	assert.Equal(t, "active", returnedAccount.Status)
+
+	statusURL := "/accounts/" + strconv.FormatInt(returnedAccount.ID, 10) + "/status"
+	accountID := returnedAccount.ID
+
+	steps := []struct {
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{"POST", "/transactions", `{"account_id": ` + strconv.FormatInt(accountID, 10) + `, "amount": 25, "operation_type_id": 1}`, http.StatusCreated},
+		{"PATCH", statusURL, `{"status": "blocked"}`, http.StatusOK},
+		{"POST", "/transactions", `{"account_id": ` + strconv.FormatInt(accountID, 10) + `, "amount": 25, "operation_type_id": 4}`, http.StatusUnprocessableEntity},
+		{"PATCH", statusURL, `{"status": "closed"}`, http.StatusUnprocessableEntity},
+		{"PATCH", statusURL, `{"status": "active"}`, http.StatusOK},
+		{"POST", "/transactions", `{"account_id": ` + strconv.FormatInt(accountID, 10) + `, "amount": 25, "operation_type_id": 4}`, http.StatusCreated},
+		{"PATCH", statusURL, `{"status": "closed"}`, http.StatusOK},
+		{"PATCH", statusURL, `{"status": "active"}`, http.StatusUnprocessableEntity},
+		{"POST", "/transactions", `{"account_id": ` + strconv.FormatInt(accountID, 10) + `, "amount": 5, "operation_type_id": 1}`, http.StatusUnprocessableEntity},
+	}
+
+	for i, step := range steps {
+		rStep := httptest.NewRecorder()
+
+		req, err = http.NewRequest(step.method, step.url, bytes.NewBufferString(step.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rStep, req)
+
+		assert.Equal(t, step.expectedStatus, rStep.Code, "step %d", i)
+	}
+
+	rGetAccount := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(accountID, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetAccount, req)
+
+	var account dto.GetAccountResponse
+	_ = json.NewDecoder(rGetAccount.Body).Decode(&account)
+
+	assert.Equal(t, "closed", account.Status)
+	assert.Equal(t, model.MustParseMoney("0.00"), account.Balance)
+}
+
+func TestE2E_InstallmentPurchase(t *testing.T) {
+
+	router := setupTest()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "11144477735"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	requests := []dto.CreateTransactionRequest{
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("100.00"), OperationTypeID: 2, Installments: 3},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("100.00"), OperationTypeID: 1, Installments: 3},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("40.00"), OperationTypeID: 4},
+	}
+	expectedStatuses := []int{http.StatusCreated, http.StatusBadRequest, http.StatusCreated}
+
+	var purchase dto.CreateTransactionResponse
+	for i, createTransactionRequest := range requests {
+		createTransactionJSON, _ := json.Marshal(createTransactionRequest)
+		rCreateTransaction := httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCreateTransaction, req)
+
+		assert.Equal(t, expectedStatuses[i], rCreateTransaction.Code)
+
+		if i == 0 {
+			_ = json.NewDecoder(rCreateTransaction.Body).Decode(&purchase)
+		}
+	}
+
+	installmentsURL := "/transactions/" + strconv.FormatInt(purchase.TransactionID, 10) + "/installments"
+	rGetPlan := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", installmentsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetPlan, req)
+
+	var plan dto.InstallmentPlanResponse
+	_ = json.NewDecoder(rGetPlan.Body).Decode(&plan)
+
+	assert.Equal(t, http.StatusOK, rGetPlan.Code)
+	assert.Equal(t, 3, plan.InstallmentCount)
+	assert.Equal(t, model.MustParseMoney("100.00"), plan.TotalAmount)
+	assert.Equal(t, model.MustParseMoney("33.34"), plan.Installments[0].Amount)
+	assert.Equal(t, "paid", plan.Installments[0].Status)
+	assert.Equal(t, "scheduled", plan.Installments[1].Status)
+	assert.True(t, plan.Installments[0].DueDate.After(time.Now()))
+
+	rGetInstallment := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", installmentsURL+"/3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetInstallment, req)
+
+	var installment dto.InstallmentResponse
+	_ = json.NewDecoder(rGetInstallment.Body).Decode(&installment)
+
+	assert.Equal(t, http.StatusOK, rGetInstallment.Code)
+	assert.Equal(t, 3, installment.Number)
+	assert.Equal(t, model.MustParseMoney("33.33"), installment.Amount)
+
+	rGetMissingInstallment := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", installmentsURL+"/4", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetMissingInstallment, req)
+
+	assert.Equal(t, http.StatusNotFound, rGetMissingInstallment.Code)
+}
+
+func TestE2E_Statements(t *testing.T) {
+
+	router := setupTest()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "52998224725", ClosingDay: 10, DueDay: 20})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	assert.Equal(t, http.StatusCreated, rCreateAccount.Code)
+	assert.Equal(t, 10, returnedAccount.ClosingDay)
+	assert.Equal(t, 20, returnedAccount.DueDay)
+
+	requests := []dto.CreateTransactionRequest{
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("250.00"), OperationTypeID: 1},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("50.00"), OperationTypeID: 4},
+	}
+	for _, createTransactionRequest := range requests {
+		createTransactionJSON, _ := json.Marshal(createTransactionRequest)
+		rCreateTransaction := httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCreateTransaction, req)
+
+		assert.Equal(t, http.StatusCreated, rCreateTransaction.Code)
+	}
+
+	statementsURL := "/accounts/" + strconv.FormatInt(returnedAccount.ID, 10) + "/statements"
+	rListStatements := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", statementsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rListStatements, req)
+
+	var list dto.ListStatementsResponse
+	_ = json.NewDecoder(rListStatements.Body).Decode(&list)
+
+	assert.Equal(t, http.StatusOK, rListStatements.Code)
+	assert.Empty(t, list.Statements)
+
+	account := model.Account{ClosingDay: 10, DueDay: 20}
+	current := account.PeriodAt(time.Now())
+	rGetStatement := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", statementsURL+"/"+model.FormatPeriod(current), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rGetStatement, req)
+
+	var statement dto.StatementDetailResponse
+	_ = json.NewDecoder(rGetStatement.Body).Decode(&statement)
+
+	assert.Equal(t, http.StatusOK, rGetStatement.Code)
+	assert.False(t, statement.Closed)
+	assert.Equal(t, model.MustParseMoney("250.00"), statement.TotalDebits)
+	assert.Equal(t, model.MustParseMoney("50.00"), statement.TotalCredits)
+	assert.Equal(t, model.MustParseMoney("-200.00"), statement.ClosingBalance)
+	assert.Equal(t, model.MustParseMoney("30.00"), statement.MinimumPayment)
+	assert.Len(t, statement.Transactions, 2)
+
+	for path, expectedStatus := range map[string]int{
+		"/" + model.FormatPeriod(current.AddDate(0, 1, 0)): http.StatusNotFound,
+		"/2024-13": http.StatusBadRequest,
+	} {
+		rGetOtherStatement := httptest.NewRecorder()
+
+		req, err = http.NewRequest("GET", statementsURL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rGetOtherStatement, req)
+
+		assert.Equal(t, expectedStatus, rGetOtherStatement.Code)
+	}
+}
+
+func TestE2E_OutboxEvents(t *testing.T) {
+
+	db := setupTestDB()
+	router := setupTestRouter(db)
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "44332211049"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	requests := []dto.CreateTransactionRequest{
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("80.00"), OperationTypeID: 1},
+		{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("30.00"), OperationTypeID: 4},
+	}
+	for _, createTransactionRequest := range requests {
+		createTransactionJSON, _ := json.Marshal(createTransactionRequest)
+
+		req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var events []model.Event
+	failNext := true
+	recorder := publisher.PublisherFunc(func(ctx context.Context, message *model.OutboxMessage) error {
+		if failNext {
+			failNext = false
+			return errors.New("broker unavailable")
+		}
+		event, err := message.Event()
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+
+	relay := service.NewOutboxRelay(repository.NewOutboxRepository(db), recorder, 8)
+
+	published, err := relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+
+	// The account whose event failed only has that event retried, and its
+	// later events follow once it is published.
+	published, err = relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	published, err = relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	assert.Len(t, events, 3)
+	assert.Equal(t, returnedAccount.ID, events[0].(*model.AccountCreated).AccountID)
+	assert.Equal(t, model.MustParseMoney("-80.00"), events[1].(*model.TransactionPosted).Amount)
+	assert.Equal(t, model.MustParseMoney("30.00"), events[2].(*model.TransactionPosted).Amount)
+
+	published, err = relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+}
+
+func TestE2E_Webhooks(t *testing.T) {
+
+	db := setupTestDB()
+	router := setupTestRouter(db)
+
+	type received struct {
+		eventType string
+		verified  bool
+		envelope  webhook.Envelope
+	}
+
+	var deliveries []received
+	failNext := true
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failNext {
+			failNext = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+
+		var envelope webhook.Envelope
+		_ = json.Unmarshal(body, &envelope)
+
+		deliveries = append(deliveries, received{
+			eventType: r.Header.Get(webhook.EventHeader),
+			verified:  webhook.Verify("a-shared-secret-for-tests", r.Header.Get(webhook.TimestampHeader), body, r.Header.Get(webhook.SignatureHeader)),
+			envelope:  envelope,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	rCreateWebhook := httptest.NewRecorder()
+
+	createWebhookJSON, _ := json.Marshal(dto.CreateWebhookRequest{
+		URL:        receiver.URL,
+		EventTypes: []string{"account.created"},
+		Secret:     "a-shared-secret-for-tests",
+	})
+	req, err := http.NewRequest("POST", "/webhooks", bytes.NewBuffer(createWebhookJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateWebhook, req)
+
+	var createdWebhook dto.CreateWebhookResponse
+	_ = json.NewDecoder(rCreateWebhook.Body).Decode(&createdWebhook)
+
+	assert.Equal(t, http.StatusCreated, rCreateWebhook.Code)
+	assert.Equal(t, "a-shared-secret-for-tests", createdWebhook.Secret)
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "71428793860"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err = http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	createTransactionJSON, _ := json.Marshal(dto.CreateTransactionRequest{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("20.00"), OperationTypeID: 1})
+
+	req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	webhookRepository := repository.NewWebhookRepository(db)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(db)
+	dispatcher := service.NewWebhookDispatcher(webhookRepository, webhookDeliveryRepository, webhook.NewHTTPSender(time.Second), 1)
+
+	relay := service.NewOutboxRelay(repository.NewOutboxRepository(db), dispatcher, 8)
+
+	published, err := relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	delivered, err := dispatcher.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	rListDeadLetters := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/webhooks/"+strconv.FormatInt(createdWebhook.ID, 10)+"/deliveries?status=dead_lettered", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rListDeadLetters, req)
+
+	var deadLetters dto.ListWebhookDeliveriesResponse
+	_ = json.NewDecoder(rListDeadLetters.Body).Decode(&deadLetters)
+
+	assert.Len(t, deadLetters.Deliveries, 1)
+	assert.Equal(t, "account.created", deadLetters.Deliveries[0].EventType)
+	assert.Equal(t, 1, deadLetters.Deliveries[0].Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, deadLetters.Deliveries[0].LastStatusCode)
+
+	rRedrive := httptest.NewRecorder()
+
+	req, err = http.NewRequest("POST", "/webhooks/"+strconv.FormatInt(createdWebhook.ID, 10)+"/dead-letters/redrive", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rRedrive, req)
+
+	var redriven dto.RedriveWebhookDeliveriesResponse
+	_ = json.NewDecoder(rRedrive.Body).Decode(&redriven)
+
+	assert.Equal(t, int64(1), redriven.Redriven)
+
+	delivered, err = dispatcher.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, "account.created", deliveries[0].eventType)
+	assert.True(t, deliveries[0].verified)
+	assert.Equal(t, model.AccountCreatedEvent, deliveries[0].envelope.Type)
+
+	rListDeliveries := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/webhooks/"+strconv.FormatInt(createdWebhook.ID, 10)+"/deliveries", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rListDeliveries, req)
+
+	var deliveryLog dto.ListWebhookDeliveriesResponse
+	_ = json.NewDecoder(rListDeliveries.Body).Decode(&deliveryLog)
+
+	assert.Len(t, deliveryLog.Deliveries, 1)
+	assert.Equal(t, "succeeded", deliveryLog.Deliveries[0].Status)
+	assert.NotNil(t, deliveryLog.Deliveries[0].DeliveredAt)
+}
+
+func TestE2E_OperationTypeCatalog(t *testing.T) {
+
+	router := setupTest()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "99887766593"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	createdOperationTypes := make([]dto.OperationTypeResponse, 0, 2)
+	for _, body := range []string{
+		`{"description": "Refund", "sign": "credit"}`,
+		`{"description": "Retired fee", "sign": "debit", "active": false}`,
+	} {
+		rCreateOperationType := httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", "/admin/operation-types", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCreateOperationType, req)
+
+		var createdOperationType dto.OperationTypeResponse
+		_ = json.NewDecoder(rCreateOperationType.Body).Decode(&createdOperationType)
+
+		assert.Equal(t, http.StatusCreated, rCreateOperationType.Code)
+		createdOperationTypes = append(createdOperationTypes, createdOperationType)
+	}
+
+	rListOperationTypes := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/admin/operation-types", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rListOperationTypes, req)
+
+	var operationTypes []dto.OperationTypeResponse
+	_ = json.NewDecoder(rListOperationTypes.Body).Decode(&operationTypes)
+
+	assert.Len(t, operationTypes, 6)
+
+	refundJSON, _ := json.Marshal(dto.CreateTransactionRequest{
+		AccountID:       returnedAccount.ID,
+		Amount:          model.MustParseMoney("15.00"),
+		OperationTypeID: uint(createdOperationTypes[0].ID),
+	})
+	rCreateTransaction := httptest.NewRecorder()
+
+	req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(refundJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateTransaction, req)
+
+	var returnedTransaction dto.CreateTransactionResponse
+	_ = json.NewDecoder(rCreateTransaction.Body).Decode(&returnedTransaction)
+
+	assert.Equal(t, http.StatusCreated, rCreateTransaction.Code)
+	assert.Equal(t, model.MustParseMoney("15.00"), returnedTransaction.Amount)
+
+	feeJSON, _ := json.Marshal(dto.CreateTransactionRequest{
+		AccountID:       returnedAccount.ID,
+		Amount:          model.MustParseMoney("15.00"),
+		OperationTypeID: uint(createdOperationTypes[1].ID),
+	})
+	rCreateTransaction = httptest.NewRecorder()
+
+	req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(feeJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateTransaction, req)
+
+	assert.Equal(t, http.StatusBadRequest, rCreateTransaction.Code)
+}
+
+func TestE2E_HealthChecks(t *testing.T) {
+
+	db := setupTestDB()
+	router := setupTestRouter(db)
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		rCheck := httptest.NewRecorder()
+
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rCheck, req)
+
+		assert.Equal(t, http.StatusOK, rCheck.Code, path)
+	}
+
+	// Rolling back a migration leaves the schema behind the binary.
+	migrator, _ := migration.NewMigrator(db)
+	_, err := migrator.Down()
+	assert.NoError(t, err)
+
+	rNotReady := httptest.NewRecorder()
+
+	req, err := http.NewRequest("GET", "/readyz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rNotReady, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rNotReady.Code)
+
+	var returnedHealth dto.HealthResponse
+	_ = json.NewDecoder(rNotReady.Body).Decode(&returnedHealth)
+
+	assert.Equal(t, "unavailable", returnedHealth.Status)
+	assert.Equal(t, "ok", returnedHealth.Checks["database"])
+	assert.Equal(t, "schema is at version 18, expected 19", returnedHealth.Checks["migrations"])
+
+	// The liveness probe does not depend on the database.
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+
+	rAlive := httptest.NewRecorder()
+
+	req, err = http.NewRequest("GET", "/healthz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rAlive, req)
+
+	assert.Equal(t, http.StatusOK, rAlive.Code)
+}
+
+func TestE2E_Metrics(t *testing.T) {
+
+	router := setupTest()
+
+	scrape := func() map[string]float64 {
+		rMetrics := httptest.NewRecorder()
+
+		req, err := http.NewRequest("GET", "/metrics", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		router.ServeHTTP(rMetrics, req)
+
+		assert.Equal(t, http.StatusOK, rMetrics.Code)
+
+		samples := make(map[string]float64)
+		for _, line := range strings.Split(rMetrics.Body.String(), "\n") {
+			name, value, found := strings.Cut(line, " ")
+			if found && !strings.HasPrefix(line, "#") {
+				samples[name], _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		return samples
+	}
+
+	before := scrape()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "71428793860"})
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	after := scrape()
+
+	delta := func(name string) float64 {
+		return after[name] - before[name]
+	}
+
+	assert.Equal(t, float64(1), delta("accounts_transactions_accounts_created_total"))
+	assert.Equal(t, float64(1), delta(`accounts_transactions_conflicts_total{service="account"}`))
+	assert.Equal(t, float64(1), delta(`http_requests_total{method="POST",route="/accounts",status="201"}`))
+	assert.Equal(t, float64(1), delta(`http_requests_total{method="POST",route="/accounts",status="409"}`))
+	assert.Equal(t, float64(1), delta(`http_request_duration_seconds_count{method="GET",route="/metrics",status="200"}`))
+}
+
+func TestE2E_Tracing(t *testing.T) {
+
+	exporter := tracing.InMemory()
+	router := setupTest()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "52998224725"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	exporter.Reset()
+
+	createTransactionJSON, _ := json.Marshal(dto.CreateTransactionRequest{AccountID: returnedAccount.ID, Amount: model.MustParseMoney("50.00"), OperationTypeID: 1})
+	req, err = http.NewRequest("POST", "/transactions", bytes.NewBuffer(createTransactionJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	rCreateTransaction := httptest.NewRecorder()
+	router.ServeHTTP(rCreateTransaction, req)
+
+	assert.Equal(t, http.StatusCreated, rCreateTransaction.Code)
+
+	spans := make(map[string]tracetest.SpanStub)
+	var statements []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		if strings.HasPrefix(span.Name, "db.") {
+			statements = append(statements, span)
+			continue
+		}
+		spans[span.Name] = span
+	}
+
+	server, found := spans["POST /transactions"]
+	if !assert.True(t, found) {
+		return
+	}
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Contains(t, server.Attributes, attribute.Int("http.response.status_code", http.StatusCreated))
+
+	createTransaction := spans["TransactionService.CreateTransaction"]
+	assert.Equal(t, server.SpanContext.SpanID(), createTransaction.Parent.SpanID())
+	assert.Contains(t, createTransaction.Attributes, attribute.Int64("account.id", returnedAccount.ID))
+
+	var tables []string
+	for _, statement := range statements {
+		if statement.Parent.SpanID() == createTransaction.SpanContext.SpanID() {
+			tables = append(tables, statement.Name)
+		}
+	}
+	assert.Contains(t, tables, "db.query accounts")
+	assert.Contains(t, tables, "db.create transactions")
+}
+
+func TestE2E_RequestLogging(t *testing.T) {
+
+	hook := logTest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	router := setupTest()
+
+	createAccountJSON, _ := json.Marshal(dto.CreateAccountRequest{DocumentNumber: "15350946056"})
+	rCreateAccount := httptest.NewRecorder()
+
+	req, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(createAccountJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(rCreateAccount, req)
+
+	var returnedAccount dto.CreateAccountResponse
+	_ = json.NewDecoder(rCreateAccount.Body).Decode(&returnedAccount)
+
+	assert.Regexp(t, "^[0-9a-f]{32}$", rCreateAccount.Header().Get(apiMiddleware.RequestIDHeader))
+
+	accessLogs := func(requestID string) []*log.Entry {
+		var entries []*log.Entry
+		for _, entry := range hook.AllEntries() {
+			if entry.Data["requestID"] == requestID && entry.Message == "Request completed" {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	created := accessLogs(rCreateAccount.Header().Get(apiMiddleware.RequestIDHeader))
+	if assert.Len(t, created, 1) {
+		assert.Equal(t, returnedAccount.ID, created[0].Data["accountID"])
+		assert.Equal(t, http.StatusCreated, created[0].Data["status"])
+	}
+
+	req, err = http.NewRequest("GET", "/accounts/"+strconv.FormatInt(returnedAccount.ID, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(apiMiddleware.RequestIDHeader, "checkout-7f3a")
+
+	rGetAccount := httptest.NewRecorder()
+	router.ServeHTTP(rGetAccount, req)
+
+	assert.Equal(t, "checkout-7f3a", rGetAccount.Header().Get(apiMiddleware.RequestIDHeader))
+
+	fetched := accessLogs("checkout-7f3a")
+	if assert.Len(t, fetched, 1) {
+		assert.Equal(t, log.InfoLevel, fetched[0].Level)
+		assert.Equal(t, "GET", fetched[0].Data["method"])
+		assert.Equal(t, "/accounts/{accountID}", fetched[0].Data["route"])
+		assert.Equal(t, http.StatusOK, fetched[0].Data["status"])
+		assert.Equal(t, returnedAccount.ID, fetched[0].Data["accountID"])
+		assert.Equal(t, rGetAccount.Body.Len(), fetched[0].Data["bytes"])
+		assert.Contains(t, fetched[0].Data, "latencyMs")
+	}
+
+	req, err = http.NewRequest("GET", "/accounts/999999", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(apiMiddleware.RequestIDHeader, "missing-account")
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		if entry.Data["requestID"] == "missing-account" {
+			messages = append(messages, entry.Message)
+		}
+	}
+	assert.Equal(t, []string{"Error getting account", "Error getting account", "Request completed"}, messages)
+}
+
+func TestE2E_APIKeyAuthentication(t *testing.T) {
+
+	hook := logTest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	db := setupTestDB()
+	router := setupTestMux(db)
+	adminKey := issueTestKey(db, model.ScopeAdmin)
+
+	send := func(method string, path string, key string, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "" {
+			req.Header.Set(apiMiddleware.AuthorizationHeader, "Bearer "+key)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	problemCode := func(rr *httptest.ResponseRecorder) string {
+		var problem api.Problem
+		_ = json.NewDecoder(rr.Body).Decode(&problem)
+		return problem.Code
+	}
+
+	rAnonymous := send("GET", "/accounts/1", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rAnonymous.Code)
+	assert.Equal(t, "Bearer", rAnonymous.Header().Get(apiMiddleware.WWWAuthenticateHeader))
+	assert.Equal(t, "API_KEY_REQUIRED", problemCode(rAnonymous))
+
+	// Probes and metrics stay open.
+	assert.Equal(t, http.StatusOK, send("GET", "/healthz", "", "").Code)
+	assert.Equal(t, http.StatusOK, send("GET", "/metrics", "", "").Code)
+
+	rIssue := send("POST", "/admin/api-clients", adminKey, `{"name":"reports","scopes":["accounts:read"]}`)
+	assert.Equal(t, http.StatusCreated, rIssue.Code)
+
+	var issued dto.APIClientKeyResponse
+	_ = json.NewDecoder(rIssue.Body).Decode(&issued)
+	readerKey := issued.Key
+
+	assert.Equal(t, http.StatusNotFound, send("GET", "/accounts/999", readerKey, "").Code)
+
+	rWrite := send("POST", "/accounts", readerKey, `{"document_number":"12345678909"}`)
+	assert.Equal(t, http.StatusForbidden, rWrite.Code)
+	assert.Equal(t, "INSUFFICIENT_SCOPE", problemCode(rWrite))
+	assert.Equal(t, http.StatusForbidden, send("GET", "/admin/api-clients", readerKey, "").Code)
+
+	clientPath := "/admin/api-clients/" + strconv.FormatInt(issued.ID, 10)
+
+	rRotate := send("POST", clientPath+"/rotate", adminKey, "")
+	assert.Equal(t, http.StatusOK, rRotate.Code)
+
+	var rotated dto.APIClientKeyResponse
+	_ = json.NewDecoder(rRotate.Body).Decode(&rotated)
+	assert.NotEqual(t, readerKey, rotated.Key)
+
+	rOldKey := send("GET", "/accounts/999", readerKey, "")
+	assert.Equal(t, http.StatusUnauthorized, rOldKey.Code)
+	assert.Equal(t, "INVALID_API_KEY", problemCode(rOldKey))
+	assert.Equal(t, http.StatusNotFound, send("GET", "/accounts/999", rotated.Key, "").Code)
+
+	assert.Equal(t, http.StatusOK, send("POST", clientPath+"/disable", adminKey, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/accounts/999", rotated.Key, "").Code)
+
+	assert.Equal(t, http.StatusOK, send("POST", clientPath+"/enable", adminKey, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/accounts/999", rotated.Key, "").Code)
+
+	assert.Equal(t, http.StatusNoContent, send("DELETE", clientPath, adminKey, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/accounts/999", rotated.Key, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", clientPath, adminKey, "").Code)
+
+	// No log line carries a key, even of the requests that were rejected.
+	for _, entry := range hook.AllEntries() {
+		line, _ := entry.String()
+		for _, key := range []string{adminKey, readerKey, rotated.Key} {
+			assert.NotContains(t, line, key[len(apikey.Prefix(key)):])
+		}
+	}
+}
+
+func setupTest() http.Handler {
+	return setupTestRouter(setupTestDB())
 }
 
-func setupTest() *chi.Mux {
+func setupTestDB() *gorm.DB {
 
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect to database")
 	}
 
+	// Every connection to :memory: opens a new database, so keep a single one.
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
 	db.Exec("PRAGMA foreign_keys = ON")
 
-	if err = db.AutoMigrate(&model.Account{}, &model.Transaction{}); err != nil {
-		panic("failed to migrate database")
+	if err = db.Use(tracing.GormPlugin()); err != nil {
+		panic("failed to install the tracing plugin")
+	}
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		panic("failed to load migrations")
+	}
+	if _, err = migrator.Up(); err != nil {
+		panic("failed to migrate database: " + err.Error())
+	}
+
+	return db
+}
+
+// setupTestRouter serves the API, sending the requests that carry no API key
+// with the key of an admin client, so the tests that are not about
+// authentication can ignore it.
+func setupTestRouter(db *gorm.DB) http.Handler {
+	router := setupTestMux(db)
+	key := issueTestKey(db, model.ScopeAdmin)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiMiddleware.AuthorizationHeader) == "" {
+			r.Header.Set(apiMiddleware.AuthorizationHeader, "Bearer "+key)
+		}
+		router.ServeHTTP(w, r)
+	})
+}
+
+func issueTestKey(db *gorm.DB, scopes ...model.Scope) string {
+	apiClientService := service.NewAPIClientService(repository.NewAPIClientRepository(db))
+	_, key, err := apiClientService.IssueClient(context.Background(), &model.APIClient{Name: "test", Scopes: scopes})
+	if err != nil {
+		panic("failed to issue API key: " + err.Error())
 	}
+	return key
+}
+
+func setupTestMux(db *gorm.DB) *chi.Mux {
 
 	router := chi.NewRouter()
-	router.Use(middleware.Logger)
+	router.Use(apiMiddleware.Metrics)
+	router.Use(apiMiddleware.Tracing)
+	router.Use(apiMiddleware.RequestLogger)
+
+	transactor := repository.NewTransactor(db)
 
 	accountRepository := repository.NewAccountRepository(db)
-	accountService := service.NewAccountService(accountRepository)
+	accountService := service.NewAccountService(accountRepository, transactor)
 	accountHandler := api.NewAccountHandler(accountService)
 
+	operationTypeRepository := repository.NewOperationTypeRepository(db)
+	operationTypeService := service.NewOperationTypeService(operationTypeRepository)
+	operationTypeHandler := api.NewOperationTypeHandler(operationTypeService)
+
 	transactionRepository := repository.NewTransactionRepository(db)
-	transactionService := service.NewTransactionService(transactionRepository)
-	transactionHandler := api.NewTransactionHandler(transactionService, accountService)
+	transactionService := service.NewTransactionService(transactionRepository, transactor)
+	transactionHandler := api.NewTransactionHandler(transactionService, accountService, operationTypeService)
+
+	installmentRepository := repository.NewInstallmentRepository(db)
+	installmentService := service.NewInstallmentService(installmentRepository, transactionRepository)
+	installmentHandler := api.NewInstallmentHandler(installmentService)
+
+	statementRepository := repository.NewStatementRepository(db)
+	statementService := service.NewStatementService(statementRepository, accountRepository, transactionRepository, installmentRepository, transactor)
+	statementHandler := api.NewStatementHandler(statementService)
+
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository(db)
+	idempotencyService := service.NewIdempotencyService(idempotencyKeyRepository, time.Hour, time.Minute)
+	idempotency := apiMiddleware.Idempotency(idempotencyService)
+
+	webhookRepository := repository.NewWebhookRepository(db)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(db)
+	webhookService := service.NewWebhookService(webhookRepository, webhookDeliveryRepository)
+	webhookHandler := api.NewWebhookHandler(webhookService)
+
+	outboxHandler := api.NewOutboxHandler(service.NewOutboxRelay(repository.NewOutboxRepository(db), publisher.NewLogPublisher(), 8))
+
+	apiClientService := service.NewAPIClientService(repository.NewAPIClientRepository(db))
+	authorize := apiMiddleware.Authorize(apiClientService)
+	apiClientHandler := api.NewAPIClientHandler(apiClientService)
+
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}", accountHandler.HandleGetAccount)
+	router.With(authorize(model.ScopeAccountsWrite), idempotency).Post("/accounts", accountHandler.HandleCreateAccount)
+	router.With(authorize(model.ScopeAccountsWrite)).Patch("/accounts/{accountID}/status", accountHandler.HandleUpdateAccountStatus)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}/transactions", transactionHandler.HandleListTransactions)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}/statements", statementHandler.HandleListStatements)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}/statements/{period}", statementHandler.HandleGetStatement)
+	router.With(authorize(model.ScopeTransactionsWrite), idempotency).Post("/transactions", transactionHandler.HandleCreateTransaction)
+	router.With(authorize(model.ScopeTransactionsWrite), idempotency).Post("/transactions/{transactionID}/reversals", transactionHandler.HandleCreateReversal)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/transactions/{transactionID}/installments", installmentHandler.HandleGetInstallmentPlan)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/transactions/{transactionID}/installments/{number}", installmentHandler.HandleGetInstallment)
+	router.Group(func(router chi.Router) {
+		router.Use(authorize(model.ScopeAdmin))
+		router.Get("/admin/operation-types", operationTypeHandler.HandleListOperationTypes)
+		router.Post("/admin/operation-types", operationTypeHandler.HandleCreateOperationType)
+		router.Get("/admin/api-clients", apiClientHandler.HandleListAPIClients)
+		router.Post("/admin/api-clients", apiClientHandler.HandleCreateAPIClient)
+		router.Get("/admin/api-clients/{clientID}", apiClientHandler.HandleGetAPIClient)
+		router.Delete("/admin/api-clients/{clientID}", apiClientHandler.HandleRevokeAPIClient)
+		router.Post("/admin/api-clients/{clientID}/enable", apiClientHandler.HandleEnableAPIClient)
+		router.Post("/admin/api-clients/{clientID}/disable", apiClientHandler.HandleDisableAPIClient)
+		router.Post("/admin/api-clients/{clientID}/rotate", apiClientHandler.HandleRotateAPIClientKey)
+		router.Post("/admin/outbox/dead-letters/redrive", outboxHandler.HandleRedriveOutboxMessages)
+		router.Get("/webhooks", webhookHandler.HandleListWebhooks)
+		router.Post("/webhooks", webhookHandler.HandleCreateWebhook)
+		router.Get("/webhooks/{webhookID}", webhookHandler.HandleGetWebhook)
+		router.Put("/webhooks/{webhookID}", webhookHandler.HandleUpdateWebhook)
+		router.Delete("/webhooks/{webhookID}", webhookHandler.HandleDeleteWebhook)
+		router.Get("/webhooks/{webhookID}/deliveries", webhookHandler.HandleListWebhookDeliveries)
+		router.Post("/webhooks/{webhookID}/dead-letters/redrive", webhookHandler.HandleRedriveWebhookDeliveries)
+	})
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		panic("failed to load migrations")
+	}
+	healthService := service.NewHealthService(repository.NewHealthRepository(db), migrator)
+	healthHandler := api.NewHealthHandler(healthService)
 
-	router.Get("/accounts/{accountID}", accountHandler.HandleGetAccount)
-	router.Post("/accounts", accountHandler.HandleCreateAccount)
-	router.Post("/transactions", transactionHandler.HandleCreateTransaction)
+	router.Get("/healthz", healthHandler.HandleLiveness)
+	router.Get("/readyz", healthHandler.HandleReadiness)
+	router.Method(http.MethodGet, "/metrics", metrics.Handler())
 
 	return router
 }
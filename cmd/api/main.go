@@ -1,16 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	api "github.com/gmerten/accounts_transactions/api/handler"
+	"github.com/gmerten/accounts_transactions/api/middleware"
 	_ "github.com/gmerten/accounts_transactions/docs"
 	"github.com/gmerten/accounts_transactions/internal/config"
+	"github.com/gmerten/accounts_transactions/internal/lifecycle"
+	"github.com/gmerten/accounts_transactions/internal/metrics"
+	"github.com/gmerten/accounts_transactions/internal/migration"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/publisher"
 	"github.com/gmerten/accounts_transactions/internal/repository"
 	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"github.com/gmerten/accounts_transactions/internal/webhook"
 	"github.com/go-chi/chi/v5"
 	log "github.com/sirupsen/logrus"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"gorm.io/gorm"
 )
 
 // @title Accounts & Transactions API
@@ -18,28 +34,211 @@ import (
 // @description API to manage accounts and transactions
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description API key of the client, as "Bearer <key>"
 func main() {
 
-	log.SetLevel(log.InfoLevel)
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config.ConfigureLogging(cfg.Log)
 	log.SetReportCaller(true)
 
+	db, err := config.GetDBConnection(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		os.Exit(runMigrate(migrator, args[1:], os.Stdout))
+	}
+
+	apiClientService := service.NewAPIClientService(repository.NewAPIClientRepository(db))
+
+	if len(args) > 0 && args[0] == "api-clients" {
+		os.Exit(runAPIClients(apiClientService, args[1:], os.Stdout))
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = metrics.RegisterDB(sqlDB, cfg.Database.Name); err != nil {
+		log.Fatal(err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = db.Use(tracing.GormPlugin()); err != nil {
+		log.Fatal(err)
+	}
+
 	router := chi.NewRouter()
+	router.Use(middleware.Metrics)
+	router.Use(middleware.Tracing)
+	router.Use(middleware.RequestLogger)
+
+	workers := lifecycle.NewManager()
 
-	db := config.GetDBConnection()
+	authorize := middleware.Authorize(apiClientService)
+	apiClientHandler := api.NewAPIClientHandler(apiClientService)
+
+	transactor := repository.NewTransactor(db)
 
 	accountRepository := repository.NewAccountRepository(db)
-	accountService := service.NewAccountService(accountRepository)
+	accountService := service.NewAccountService(accountRepository, transactor)
 	accountHandler := api.NewAccountHandler(accountService)
 
+	operationTypeRepository := repository.NewOperationTypeRepository(db)
+	operationTypeService := service.NewOperationTypeService(operationTypeRepository)
+	operationTypeHandler := api.NewOperationTypeHandler(operationTypeService)
+
 	transactionRepository := repository.NewTransactionRepository(db)
-	transactionService := service.NewTransactionService(transactionRepository)
-	transactionHandler := api.NewTransactionHandler(transactionService, accountService)
+	transactionService := service.NewTransactionService(transactionRepository, transactor)
+	transactionHandler := api.NewTransactionHandler(transactionService, accountService, operationTypeService)
+
+	installmentRepository := repository.NewInstallmentRepository(db)
+	installmentService := service.NewInstallmentService(installmentRepository, transactionRepository)
+	installmentHandler := api.NewInstallmentHandler(installmentService)
+
+	statementRepository := repository.NewStatementRepository(db)
+	statementService := service.NewStatementService(statementRepository, accountRepository, transactionRepository, installmentRepository, transactor)
+	statementHandler := api.NewStatementHandler(statementService)
+
+	workers.Go("statement-close", lifecycle.Every(time.Hour, func(ctx context.Context) {
+		_, _ = statementService.CloseAllDueStatements(ctx)
+	}))
 
-	router.Get("/accounts/{accountID}", accountHandler.HandleGetAccount)
-	router.Post("/accounts", accountHandler.HandleCreateAccount)
-	router.Post("/transactions", transactionHandler.HandleCreateTransaction)
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository(db)
+	idempotencyService := service.NewIdempotencyService(idempotencyKeyRepository, cfg.Idempotency.KeyTTL, cfg.Idempotency.Lease)
+	idempotency := middleware.Idempotency(idempotencyService)
+
+	workers.Go("idempotency-key-purge", lifecycle.Every(time.Hour, func(ctx context.Context) {
+		_ = idempotencyService.PurgeExpired(ctx)
+	}))
+
+	webhookRepository := repository.NewWebhookRepository(db)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(db)
+	webhookService := service.NewWebhookService(webhookRepository, webhookDeliveryRepository)
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepository, webhookDeliveryRepository, webhook.NewHTTPSender(cfg.Webhook.Timeout), cfg.Webhook.MaxAttempts)
+	webhookHandler := api.NewWebhookHandler(webhookService)
+
+	outboxRepository := repository.NewOutboxRepository(db)
+	outboxRelay := service.NewOutboxRelay(outboxRepository, publisher.NewFanout(publisher.NewLogPublisher(), webhookDispatcher), cfg.Outbox.MaxAttempts)
+
+	workers.Go("outbox-relay", lifecycle.Every(cfg.Outbox.PollInterval, func(ctx context.Context) {
+		_, _ = outboxRelay.RelayPending(ctx)
+	}))
+	workers.Go("webhook-delivery", lifecycle.Every(cfg.Outbox.PollInterval, func(ctx context.Context) {
+		_, _ = webhookDispatcher.DeliverDue(ctx)
+	}))
+	outboxHandler := api.NewOutboxHandler(outboxRelay)
+
+	healthService := service.NewHealthService(repository.NewHealthRepository(db), migrator)
+	healthHandler := api.NewHealthHandler(healthService)
+
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}", accountHandler.HandleGetAccount)
+	router.With(authorize(model.ScopeAccountsWrite), idempotency).Post("/accounts", accountHandler.HandleCreateAccount)
+	router.With(authorize(model.ScopeAccountsWrite)).Patch("/accounts/{accountID}/status", accountHandler.HandleUpdateAccountStatus)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}/transactions", transactionHandler.HandleListTransactions)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}/statements", statementHandler.HandleListStatements)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/accounts/{accountID}/statements/{period}", statementHandler.HandleGetStatement)
+	router.With(authorize(model.ScopeTransactionsWrite), idempotency).Post("/transactions", transactionHandler.HandleCreateTransaction)
+	router.With(authorize(model.ScopeTransactionsWrite), idempotency).Post("/transactions/{transactionID}/reversals", transactionHandler.HandleCreateReversal)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/transactions/{transactionID}/installments", installmentHandler.HandleGetInstallmentPlan)
+	router.With(authorize(model.ScopeAccountsRead)).Get("/transactions/{transactionID}/installments/{number}", installmentHandler.HandleGetInstallment)
+	router.Group(func(router chi.Router) {
+		router.Use(authorize(model.ScopeAdmin))
+		router.Get("/admin/operation-types", operationTypeHandler.HandleListOperationTypes)
+		router.Post("/admin/operation-types", operationTypeHandler.HandleCreateOperationType)
+		router.Get("/admin/api-clients", apiClientHandler.HandleListAPIClients)
+		router.Post("/admin/api-clients", apiClientHandler.HandleCreateAPIClient)
+		router.Get("/admin/api-clients/{clientID}", apiClientHandler.HandleGetAPIClient)
+		router.Delete("/admin/api-clients/{clientID}", apiClientHandler.HandleRevokeAPIClient)
+		router.Post("/admin/api-clients/{clientID}/enable", apiClientHandler.HandleEnableAPIClient)
+		router.Post("/admin/api-clients/{clientID}/disable", apiClientHandler.HandleDisableAPIClient)
+		router.Post("/admin/api-clients/{clientID}/rotate", apiClientHandler.HandleRotateAPIClientKey)
+		router.Post("/admin/outbox/dead-letters/redrive", outboxHandler.HandleRedriveOutboxMessages)
+		router.Get("/webhooks", webhookHandler.HandleListWebhooks)
+		router.Post("/webhooks", webhookHandler.HandleCreateWebhook)
+		router.Get("/webhooks/{webhookID}", webhookHandler.HandleGetWebhook)
+		router.Put("/webhooks/{webhookID}", webhookHandler.HandleUpdateWebhook)
+		router.Delete("/webhooks/{webhookID}", webhookHandler.HandleDeleteWebhook)
+		router.Get("/webhooks/{webhookID}/deliveries", webhookHandler.HandleListWebhookDeliveries)
+		router.Post("/webhooks/{webhookID}/dead-letters/redrive", webhookHandler.HandleRedriveWebhookDeliveries)
+	})
+	router.Get("/healthz", healthHandler.HandleLiveness)
+	router.Get("/readyz", healthHandler.HandleReadiness)
+	router.Method(http.MethodGet, "/metrics", metrics.Handler())
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
 
-	log.Fatal(http.ListenAndServe(":8080", router))
+	server := &http.Server{
+		Addr:         cfg.Server.Address,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	stop, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		log.WithField("address", cfg.Server.Address).Info("Server started")
+		serverErrors <- server.ListenAndServe()
+	}()
+
+	status := 0
+	select {
+	case err = <-serverErrors:
+		log.WithError(err).Error("Server failed")
+		status = 1
+	case <-stop.Done():
+		log.Info("Shutting down")
+	}
+
+	os.Exit(max(status, shutdown(server, workers, shutdownTracing, db, cfg.Server.ShutdownTimeout)))
+}
+
+// shutdown stops accepting requests and waits for the in-flight ones, then
+// stops the background workers, flushes the pending spans and closes the
+// database, all within timeout. It returns the exit status of the process.
+func shutdown(server *http.Server, workers lifecycle.Manager, shutdownTracing func(context.Context) error, db *gorm.DB, timeout time.Duration) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	status := 0
+	if err := server.Shutdown(ctx); err != nil {
+		log.WithError(err).Error("Error draining requests")
+		status = 1
+	}
+	if err := workers.Shutdown(ctx); err != nil {
+		log.WithError(err).Error("Error stopping workers")
+		status = 1
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.WithError(err).Error("Error flushing traces")
+		status = 1
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
 
+	log.Info("Shutdown complete")
+	return status
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/migration"
+)
+
+const migrateUsage = "usage: migrate up|down|status"
+
+// runMigrate runs the migrate subcommand and returns the exit status. up
+// applies every pending migration, down rolls back the latest applied one and
+// status lists the migrations with when they were applied.
+func runMigrate(migrator migration.Migrator, args []string, out io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(out, migrateUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			fmt.Fprintf(out, "migrate up failed after %d migrations: %v\n", applied, err)
+			return 1
+		}
+		fmt.Fprintf(out, "applied %d migrations, schema is at version %d\n", applied, migrator.Latest())
+	case "down":
+		rolledBack, err := migrator.Down()
+		if errors.Is(err, migration.ErrNothingToRollBack) {
+			fmt.Fprintln(out, "no migration is applied")
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(out, "migrate down failed: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "rolled back migration %02d_%s\n", rolledBack.Version, rolledBack.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			fmt.Fprintf(out, "migrate status failed: %v\n", err)
+			return 1
+		}
+
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(writer, "%02d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		_ = writer.Flush()
+	default:
+		fmt.Fprintln(out, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/migration"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRunMigrate(t *testing.T) {
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	assert.Equal(t, 0, runMigrate(migrator, []string{"status"}, &out))
+	assert.Contains(t, out.String(), "01       create_accounts_and_transactions")
+	assert.Equal(t, migrator.Latest(), strings.Count(out.String(), "pending"))
+
+	out.Reset()
+	assert.Equal(t, 0, runMigrate(migrator, []string{"up"}, &out))
+	assert.Contains(t, out.String(), "applied 19 migrations, schema is at version 19")
+
+	out.Reset()
+	assert.Equal(t, 0, runMigrate(migrator, []string{"down"}, &out))
+	assert.Equal(t, "rolled back migration 19_backfill_account_balances\n", out.String())
+
+	out.Reset()
+	assert.Equal(t, 0, runMigrate(migrator, []string{"status"}, &out))
+	assert.Equal(t, 1, strings.Count(out.String(), "pending"))
+
+	out.Reset()
+	assert.Equal(t, 0, runMigrate(migrator, []string{"up"}, &out))
+	assert.Contains(t, out.String(), "applied 1 migrations")
+}
+
+func TestRunMigrateUsage(t *testing.T) {
+	var out bytes.Buffer
+
+	assert.Equal(t, 2, runMigrate(nil, nil, &out))
+	assert.Equal(t, 2, runMigrate(nil, []string{"sideways"}, &out))
+	assert.Equal(t, 2, runMigrate(nil, []string{"up", "now"}, &out))
+	assert.Contains(t, out.String(), migrateUsage)
+}
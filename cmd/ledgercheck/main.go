@@ -0,0 +1,57 @@
+// Command ledgercheck scans the ledger and reports every journal that does not
+// balance. It exits with status 1 when the ledger is unbalanced.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gmerten/accounts_transactions/internal/config"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+
+	cfg, _, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config.ConfigureLogging(cfg.Log)
+
+	db, err := config.GetDBConnection(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ledgerService := service.NewLedgerService(repository.NewLedgerRepository(db))
+
+	os.Exit(run(context.Background(), ledgerService, os.Stdout))
+}
+
+func run(ctx context.Context, ledgerService service.LedgerService, out io.Writer) int {
+	report, err := ledgerService.Verify(ctx)
+	if err != nil {
+		fmt.Fprintf(out, "ledger verification failed: %v\n", err)
+		return 2
+	}
+
+	for _, imbalance := range report.Imbalances {
+		fmt.Fprintf(out, "journal %d (transaction %d): %s\n", imbalance.JournalID, imbalance.TransactionID, imbalance.Reason)
+	}
+	fmt.Fprintf(out, "checked %d journals, %d unbalanced, ledger total %s\n", report.JournalsChecked, len(report.Imbalances), report.Total)
+
+	if !report.Balanced() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/ledger"
+	"github.com/gmerten/accounts_transactions/internal/migration"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/service"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRun(t *testing.T) {
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	// Foreign keys stay off, so journals can be written without their transactions.
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	ledgerRepository := repository.NewLedgerRepository(db)
+	ledgerService := service.NewLedgerService(ledgerRepository)
+
+	err = ledgerRepository.CreateJournal(context.Background(), ledger.NewTransactionJournal(&model.Transaction{
+		ID:              1,
+		AccountID:       1,
+		Amount:          model.MustParseMoney("-25.00"),
+		OperationType:   model.Purchase,
+		TransactionDate: time.Now(),
+	}))
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.Equal(t, 0, run(context.Background(), ledgerService, &out))
+	assert.Equal(t, "checked 1 journals, 0 unbalanced, ledger total 0.00\n", out.String())
+
+	err = ledgerRepository.CreateJournal(context.Background(), &ledger.Journal{TransactionID: 2, PostedAt: time.Now(), Entries: []ledger.Entry{
+		{LedgerAccount: ledger.CustomerAccount(1), Amount: model.MustParseMoney("10.00")},
+		{LedgerAccount: ledger.Cash, Amount: model.MustParseMoney("-9.00")},
+	}})
+	assert.NoError(t, err)
+
+	out.Reset()
+	assert.Equal(t, 1, run(context.Background(), ledgerService, &out))
+	assert.Contains(t, out.String(), "journal 2 (transaction 2): journal is unbalanced: entries sum to 1.00\n")
+	assert.Contains(t, out.String(), "checked 2 journals, 1 unbalanced, ledger total 1.00\n")
+}
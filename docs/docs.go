@@ -17,6 +17,11 @@ const docTemplate = `{
     "paths": {
         "/accounts": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "description": "This endpoint creates a new account",
                 "consumes": [
                     "application/json"
@@ -37,6 +42,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.CreateAccountRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries of this request safe to send",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -45,12 +56,23 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.CreateAccountResponse"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
                     }
                 }
             }
         },
         "/accounts/{accountID}": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "description": "This endpoint get a account by id",
                 "consumes": [
                     "application/json"
@@ -77,13 +99,111 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.GetAccountResponse"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
                     }
                 }
             }
         },
-        "/transactions": {
-            "post": {
-                "description": "This endpoint creates a new transaction",
+        "/accounts/{accountID}/statements": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists the closed statements of an account, newest first. Cycles are closed hourly in the background",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "List the statements of an account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "accountID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListStatementsResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{accountID}/statements/{period}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint gets the statement of the cycle closing in the given month, with its transactions. The current cycle is returned open and may still change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Get a statement of an account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "accountID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Closing month, as YYYY-MM",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StatementDetailResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{accountID}/status": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint blocks, reactivates or closes an account. Closed accounts cannot be reopened, and only accounts with a zero balance can be closed",
                 "consumes": [
                     "application/json"
                 ],
@@ -91,17 +211,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "accounts"
                 ],
-                "summary": "Creates a new transaction",
+                "summary": "Changes the status of an account",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "accountID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "description": "Request body",
-                        "name": "transaction",
+                        "name": "status",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.CreateTransactionRequest"
+                            "$ref": "#/definitions/api.UpdateAccountStatusRequest"
                         }
                     }
                 ],
@@ -109,60 +236,1249 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.CreateTransactionResponse"
+                            "$ref": "#/definitions/api.GetAccountResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.CreateAccountRequest": {
-            "type": "object",
-            "required": [
-                "document_number"
-            ],
-            "properties": {
-                "document_number": {
-                    "type": "string"
+        },
+        "/accounts/{accountID}/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists the transactions of an account, with optional filters and cursor pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "List the transactions of an account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "accountID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Operation type IDs",
+                        "name": "operation_type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date, RFC 3339 or YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date, RFC 3339 or YYYY-MM-DD (inclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum absolute amount",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum absolute amount",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort by transaction date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The next_cursor returned by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListTransactionsResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
                 }
             }
         },
-        "api.CreateAccountResponse": {
-            "type": "object",
-            "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
-                "document_number": {
-                    "type": "string"
+        "/admin/api-clients": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists every API client, enabled or not, without their keys",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-clients"
+                ],
+                "summary": "List the API clients",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListAPIClientsResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint registers an API client with the given scopes and issues its key. The key is only returned here and when it is rotated, so store it safely",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-clients"
+                ],
+                "summary": "Issues an API key",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "client",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateAPIClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIClientKeyResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
                 }
             }
         },
-        "api.CreateTransactionRequest": {
-            "type": "object",
-            "required": [
-                "account_id",
-                "amount",
-                "operation_type_id"
-            ],
-            "properties": {
-                "account_id": {
-                    "type": "integer",
-                    "minimum": 1
-                },
-                "amount": {
-                    "type": "number",
-                    "minimum": 0
-                },
-                "operation_type_id": {
-                    "type": "integer",
-                    "enum": [
-                        1,
-                        2,
-                        3,
-                        4
-                    ]
+        "/admin/api-clients/{clientID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-clients"
+                ],
+                "summary": "Get an API client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API client ID",
+                        "name": "clientID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIClientResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint deletes an API client, so its key is rejected for good",
+                "tags": [
+                    "api-clients"
+                ],
+                "summary": "Revokes an API client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API client ID",
+                        "name": "clientID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-clients/{clientID}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint rejects the key of an API client until it is enabled again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-clients"
+                ],
+                "summary": "Disables an API client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API client ID",
+                        "name": "clientID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIClientResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-clients/{clientID}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint accepts the key of a disabled API client again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-clients"
+                ],
+                "summary": "Enables an API client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API client ID",
+                        "name": "clientID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIClientResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-clients/{clientID}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint issues a new key for an API client. The previous key stops working at once",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-clients"
+                ],
+                "summary": "Rotates the key of an API client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API client ID",
+                        "name": "clientID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIClientKeyResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/operation-types": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists every operation type of the catalog, active or not",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List the operation types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.OperationTypeResponse"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint adds an operation type to the catalog. Its sign decides whether amounts are debited or credited",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Creates a new operation type",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "operationType",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateOperationTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.OperationTypeResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/outbox/dead-letters/redrive": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint queues the dead lettered events of the outbox again, with a fresh set of attempts. The later events of their accounts are held back until then",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Redrive the dead lettered events",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RedriveOutboxMessagesResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "This endpoint answers as long as the process is serving requests, without checking its dependencies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "This endpoint checks that the database is reachable and that its schema is at the latest migration. It answers 503 with the failing checks otherwise",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint creates a new transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Creates a new transaction",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateTransactionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries of this request safe to send",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateTransactionResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{transactionID}/installments": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists the installments of an installment purchase, with the due date and status of each one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get the installment plan of a purchase",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transactionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.InstallmentPlanResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{transactionID}/installments/{number}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint gets one installment of an installment purchase by its number, starting at 1",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get an installment of a purchase",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transactionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Installment number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.InstallmentResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{transactionID}/reversals": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint posts a compensating transaction for the original one. Without an amount, everything still unreversed is reversed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Reverses a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transactionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "reversal",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateReversalRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries of this request safe to send",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReversalResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists every webhook subscription, active or not",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List the webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListWebhooksResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint registers an HTTP endpoint that receives the events of the given types, or of every type when none is given. Deliveries are signed with HMAC-SHA256 in the X-Webhook-Signature header, and the secret is only returned here",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Subscribes a webhook endpoint",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWebhookResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WebhookResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint replaces the URL, event types and active flag of a webhook. Inactive webhooks get no new deliveries, and their pending deliveries are dead lettered",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Updates a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WebhookResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint deletes a webhook along with its deliveries",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Deletes a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookID}/dead-letters/redrive": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint queues the dead lettered deliveries of a webhook again, with a fresh set of attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redrive the dead letters of a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RedriveWebhookDeliveriesResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint is the delivery log of a webhook, newest first, with the attempts, last response status and last error of each delivery. Filter by dead_lettered to see the dead letter store",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List the deliveries of a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "dead_lettered"
+                        ],
+                        "type": "string",
+                        "description": "Delivery status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 200,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum number of deliveries",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListWebhookDeliveriesResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.APIClientKeyResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "key_prefix": {
+                    "description": "KeyPrefix is the beginning of the key of the client, to recognize it.",
+                    "type": "string",
+                    "example": "ak_3f9c2a1b"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing"
+                },
+                "rotated_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "accounts:read"
+                    ]
+                }
+            }
+        },
+        "api.APIClientResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "key_prefix": {
+                    "description": "KeyPrefix is the beginning of the key of the client, to recognize it.",
+                    "type": "string",
+                    "example": "ak_3f9c2a1b"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing"
+                },
+                "rotated_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "accounts:read"
+                    ]
+                }
+            }
+        },
+        "api.CreateAPIClientRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "billing"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "accounts:read"
+                    ]
+                }
+            }
+        },
+        "api.CreateAccountRequest": {
+            "type": "object",
+            "required": [
+                "document_number"
+            ],
+            "properties": {
+                "available_credit_limit": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 5000
+                },
+                "closing_day": {
+                    "type": "integer",
+                    "maximum": 28,
+                    "minimum": 1,
+                    "example": 25
+                },
+                "document_number": {
+                    "type": "string",
+                    "example": "529.982.247-25"
+                },
+                "due_day": {
+                    "type": "integer",
+                    "maximum": 28,
+                    "minimum": 1,
+                    "example": 5
+                }
+            }
+        },
+        "api.CreateAccountResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "available_credit_limit": {
+                    "type": "number",
+                    "example": 5000
+                },
+                "closing_day": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "document_number": {
+                    "type": "string",
+                    "example": "52998224725"
+                },
+                "document_type": {
+                    "type": "string",
+                    "example": "cpf"
+                },
+                "due_day": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "api.CreateOperationTypeRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "sign"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "operation_type_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "sign": {
+                    "type": "string",
+                    "enum": [
+                        "debit",
+                        "credit"
+                    ]
+                }
+            }
+        },
+        "api.CreateReversalRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                }
+            }
+        },
+        "api.CreateTransactionRequest": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "operation_type_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "amount": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 200.5
+                },
+                "installments": {
+                    "type": "integer",
+                    "maximum": 48,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "operation_type_id": {
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
@@ -173,27 +1489,655 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "amount": {
-                    "type": "number"
+                    "type": "number",
+                    "example": -200.5
+                },
+                "balance": {
+                    "type": "number",
+                    "example": -200.5
+                },
+                "operation_type_id": {
+                    "type": "integer"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "transaction.posted"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "api.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "transaction.posted"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.FieldViolation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the path of the field in the request body, such as\nevent_types[0].",
+                    "type": "string",
+                    "example": "amount"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "amount must be greater than or equal to 0"
+                },
+                "param": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "gte"
+                }
+            }
+        },
+        "api.GetAccountResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "available_credit_limit": {
+                    "type": "number",
+                    "example": 4879.5
+                },
+                "balance": {
+                    "type": "number",
+                    "example": -120.5
+                },
+                "closing_day": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "document_number": {
+                    "type": "string",
+                    "example": "52998224725"
+                },
+                "document_type": {
+                    "type": "string",
+                    "example": "cpf"
+                },
+                "due_day": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "api.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "api.InstallmentPlanResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "installment_count": {
+                    "type": "integer"
+                },
+                "installments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.InstallmentResponse"
+                    }
+                },
+                "total_amount": {
+                    "type": "number",
+                    "example": 200.5
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.InstallmentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 66.84
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "scheduled"
+                }
+            }
+        },
+        "api.ListAPIClientsResponse": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.APIClientResponse"
+                    }
+                }
+            }
+        },
+        "api.ListStatementsResponse": {
+            "type": "object",
+            "properties": {
+                "statements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.StatementResponse"
+                    }
+                }
+            }
+        },
+        "api.ListTransactionsResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TransactionResponse"
+                    }
+                }
+            }
+        },
+        "api.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookDeliveryResponse"
+                    }
+                }
+            }
+        },
+        "api.ListWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookResponse"
+                    }
+                }
+            }
+        },
+        "api.OperationTypeResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
                 },
                 "operation_type_id": {
                     "type": "integer"
                 },
+                "sign": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code identifies the error. Clients should branch on it rather than on\nthe detail, which may be reworded.",
+                    "type": "string",
+                    "enum": [
+                        "ACCOUNT_BALANCE_NOT_ZERO",
+                        "ACCOUNT_NOT_ACTIVE",
+                        "ACCOUNT_NOT_FOUND",
+                        "API_CLIENT_NOT_FOUND",
+                        "API_KEY_REQUIRED",
+                        "CONFLICT",
+                        "CREDIT_LIMIT_EXCEEDED",
+                        "DUPLICATE_DOCUMENT",
+                        "DUPLICATE_OPERATION_TYPE",
+                        "FORBIDDEN",
+                        "IDEMPOTENCY_KEY_IN_USE",
+                        "IDEMPOTENCY_KEY_REUSED",
+                        "INSTALLMENT_NOT_FOUND",
+                        "INSTALLMENT_PLAN_NOT_FOUND",
+                        "INSUFFICIENT_SCOPE",
+                        "INTERNAL_ERROR",
+                        "INVALID_API_KEY",
+                        "INVALID_DOCUMENT_NUMBER",
+                        "INVALID_IDEMPOTENCY_KEY",
+                        "INVALID_INSTALLMENTS",
+                        "INVALID_OPERATION_TYPE",
+                        "INVALID_PARAMETER",
+                        "INVALID_PERIOD",
+                        "INVALID_REQUEST_BODY",
+                        "INVALID_REVERSAL_AMOUNT",
+                        "INVALID_STATUS_TRANSITION",
+                        "NOT_FOUND",
+                        "OPERATION_TYPE_NOT_ACTIVE",
+                        "REVERSAL_NOT_ALLOWED",
+                        "STATEMENT_NOT_FOUND",
+                        "TRANSACTION_ALREADY_REVERSED",
+                        "TRANSACTION_NOT_FOUND",
+                        "UNAUTHORIZED",
+                        "UNPROCESSABLE_ENTITY",
+                        "VALIDATION_FAILED",
+                        "WEBHOOK_NOT_FOUND"
+                    ],
+                    "example": "ACCOUNT_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Account not found"
+                },
+                "instance": {
+                    "description": "Instance is the path of the request that failed.",
+                    "type": "string",
+                    "example": "/accounts/42"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "5f0c6d9e2b7a41c38e1d0f6a9b2c4e71"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The account was not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:accounts-transactions:problem:ACCOUNT_NOT_FOUND"
+                },
+                "violations": {
+                    "description": "Violations lists the invalid fields of the request body, if any.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.FieldViolation"
+                    }
+                }
+            }
+        },
+        "api.RedriveOutboxMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "redriven": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.RedriveWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "redriven": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ReversalResponse": {
+            "type": "object",
+            "properties": {
+                "original": {
+                    "$ref": "#/definitions/api.TransactionResponse"
+                },
+                "reversal": {
+                    "$ref": "#/definitions/api.TransactionResponse"
+                }
+            }
+        },
+        "api.StatementDetailResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "closing_balance": {
+                    "type": "number",
+                    "example": -250.5
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "installments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.StatementInstallmentResponse"
+                    }
+                },
+                "minimum_payment": {
+                    "type": "number",
+                    "example": 37.58
+                },
+                "opening_balance": {
+                    "type": "number",
+                    "example": -100
+                },
+                "period": {
+                    "type": "string",
+                    "example": "2024-09"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "total_credits": {
+                    "type": "number",
+                    "example": 100
+                },
+                "total_debits": {
+                    "type": "number",
+                    "example": 250.5
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TransactionResponse"
+                    }
+                }
+            }
+        },
+        "api.StatementInstallmentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 66.84
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
                 "transaction_id": {
                     "type": "integer"
                 }
             }
         },
-        "api.GetAccountResponse": {
+        "api.StatementResponse": {
             "type": "object",
             "properties": {
                 "account_id": {
                     "type": "integer"
                 },
-                "document_number": {
+                "closed": {
+                    "type": "boolean"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "closing_balance": {
+                    "type": "number",
+                    "example": -250.5
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "minimum_payment": {
+                    "type": "number",
+                    "example": 37.58
+                },
+                "opening_balance": {
+                    "type": "number",
+                    "example": -100
+                },
+                "period": {
+                    "type": "string",
+                    "example": "2024-09"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "total_credits": {
+                    "type": "number",
+                    "example": 100
+                },
+                "total_debits": {
+                    "type": "number",
+                    "example": 250.5
+                }
+            }
+        },
+        "api.TransactionResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number",
+                    "example": -200.5
+                },
+                "balance": {
+                    "type": "number",
+                    "example": -200.5
+                },
+                "operation_type_id": {
+                    "type": "integer"
+                },
+                "reversal_of_transaction_id": {
+                    "type": "integer"
+                },
+                "reversed_amount": {
+                    "type": "number",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "example": "posted"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.UpdateAccountStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "blocked",
+                        "closed"
+                    ],
+                    "example": "blocked"
+                }
+            }
+        },
+        "api.UpdateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "active",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "transaction.posted"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "api.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "transaction.posted"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer",
+                    "example": 503
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                }
+            }
+        },
+        "api.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
                     "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "transaction.posted"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                },
+                "webhook_id": {
+                    "type": "integer"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of the client, as \"Bearer \u003ckey\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
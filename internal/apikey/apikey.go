@@ -0,0 +1,56 @@
+// Package apikey generates the keys that authenticate API clients and the
+// hashes they are stored as.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// keyPrefix tells the keys of this API apart from other secrets, which
+	// helps secret scanners and people alike.
+	keyPrefix = "ak_"
+	// displayLength is how many characters of a key are kept to recognize
+	// it. They carry 32 of its 256 random bits.
+	displayLength = len(keyPrefix) + 8
+)
+
+// New generates a random key.
+func New() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(secret), nil
+}
+
+// Hash returns the hex encoded SHA-256 of the key. Keys are random, so a
+// plain hash is as hard to reverse as the key is to guess, and it can be
+// looked up directly.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Prefix returns the first characters of the key, which are safe to show and
+// to log.
+func Prefix(key string) string {
+	if len(key) < displayLength {
+		return key
+	}
+	return key[:displayLength]
+}
+
+// LooksValid reports whether the key has the format of the keys made by New,
+// so malformed keys are rejected without a lookup.
+func LooksValid(key string) bool {
+	secret, found := strings.CutPrefix(key, keyPrefix)
+	if !found || len(secret) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(secret)
+	return err == nil
+}
@@ -0,0 +1,41 @@
+package apikey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	key, err := New()
+	assert.NoError(t, err)
+	assert.Len(t, key, 67)
+	assert.Equal(t, "ak_", key[:3])
+	assert.True(t, LooksValid(key))
+
+	other, err := New()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestHash(t *testing.T) {
+	key := "ak_" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	assert.Len(t, Hash(key), 64)
+	assert.Equal(t, Hash(key), Hash(key))
+	assert.NotEqual(t, Hash(key), Hash(key+"0"))
+	assert.NotContains(t, Hash(key), key[3:])
+}
+
+func TestPrefix(t *testing.T) {
+	assert.Equal(t, "ak_01234567", Prefix("ak_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"))
+	assert.Equal(t, "ak_01", Prefix("ak_01"))
+}
+
+func TestLooksValid(t *testing.T) {
+	assert.False(t, LooksValid(""))
+	assert.False(t, LooksValid("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"))
+	assert.False(t, LooksValid("ak_0123456789abcdef"))
+	assert.False(t, LooksValid("ak_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdeg"))
+	assert.False(t, LooksValid("sk_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"))
+}
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the application. It is loaded by Load from,
+// in increasing order of precedence, the defaults, a YAML file, environment
+// variables and command-line flags.
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Log         LogConfig         `yaml:"log"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Webhook     WebhookConfig     `yaml:"webhook"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Address      string        `yaml:"address"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout bounds how long in-flight requests and background workers
+	// are waited for on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver"`
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"ssl_mode"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+type IdempotencyConfig struct {
+	KeyTTL time.Duration `yaml:"key_ttl"`
+	Lease  time.Duration `yaml:"lease"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+}
+
+type WebhookConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// Tracing exporters accepted in tracing.exporter.
+const (
+	NoExporter     = "none"
+	StdoutExporter = "stdout"
+	OTLPExporter   = "otlp"
+)
+
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+	// OTLPEndpoint is the URL of the OTLP/HTTP collector. When empty, the
+	// standard OTEL_EXPORTER_OTLP_* variables apply.
+	OTLPEndpoint string  `yaml:"otlp_endpoint"`
+	SampleRatio  float64 `yaml:"sample_ratio"`
+	ServiceName  string  `yaml:"service_name"`
+}
+
+// Default returns the configuration used for every setting that is not given.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Address:         ":8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:          MySQL,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Idempotency: IdempotencyConfig{KeyTTL: 24 * time.Hour, Lease: time.Minute},
+		Outbox:      OutboxConfig{PollInterval: time.Second, MaxAttempts: 8},
+		Webhook:     WebhookConfig{MaxAttempts: 8, Timeout: 10 * time.Second},
+		Tracing: TracingConfig{
+			Exporter:    NoExporter,
+			SampleRatio: 1,
+			ServiceName: "accounts_transactions",
+		},
+	}
+}
+
+// setting binds one configuration value to its environment variable and flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	value func(config *Config) interface{}
+}
+
+var settings = []setting{
+	{"SERVER_ADDRESS", "server-address", "address the HTTP server listens on", func(c *Config) interface{} { return &c.Server.Address }},
+	{"SERVER_READ_TIMEOUT", "server-read-timeout", "maximum duration for reading a request", func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{"SERVER_WRITE_TIMEOUT", "server-write-timeout", "maximum duration for writing a response", func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{"SERVER_IDLE_TIMEOUT", "server-idle-timeout", "maximum duration a keep-alive connection stays idle", func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{"SERVER_SHUTDOWN_TIMEOUT", "server-shutdown-timeout", "maximum duration to wait for requests and workers on shutdown", func(c *Config) interface{} { return &c.Server.ShutdownTimeout }},
+	{"DB_DRIVER", "db-driver", "database driver: mysql, postgres or sqlite", func(c *Config) interface{} { return &c.Database.Driver }},
+	{"DB_HOST", "db-host", "database host", func(c *Config) interface{} { return &c.Database.Host }},
+	{"DB_PORT", "db-port", "database port, 3306 for mysql and 5432 for postgres by default", func(c *Config) interface{} { return &c.Database.Port }},
+	{"DB_USER", "db-user", "database user", func(c *Config) interface{} { return &c.Database.User }},
+	{"DB_PASSWORD", "db-password", "database password", func(c *Config) interface{} { return &c.Database.Password }},
+	{"DB_NAME", "db-name", "database name, or the database file for sqlite", func(c *Config) interface{} { return &c.Database.Name }},
+	{"DB_SSLMODE", "db-sslmode", "postgres SSL mode", func(c *Config) interface{} { return &c.Database.SSLMode }},
+	{"DB_MAX_OPEN_CONNS", "db-max-open-conns", "maximum number of open database connections", func(c *Config) interface{} { return &c.Database.MaxOpenConns }},
+	{"DB_MAX_IDLE_CONNS", "db-max-idle-conns", "maximum number of idle database connections", func(c *Config) interface{} { return &c.Database.MaxIdleConns }},
+	{"DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "maximum duration a database connection is reused", func(c *Config) interface{} { return &c.Database.ConnMaxLifetime }},
+	{"DB_CONN_MAX_IDLE_TIME", "db-conn-max-idle-time", "maximum duration a database connection stays idle", func(c *Config) interface{} { return &c.Database.ConnMaxIdleTime }},
+	{"LOG_LEVEL", "log-level", "log level: trace, debug, info, warn or error", func(c *Config) interface{} { return &c.Log.Level }},
+	{"LOG_FORMAT", "log-format", "log format: text or json", func(c *Config) interface{} { return &c.Log.Format }},
+	{"IDEMPOTENCY_KEY_TTL", "idempotency-key-ttl", "how long idempotency keys are kept", func(c *Config) interface{} { return &c.Idempotency.KeyTTL }},
+	{"IDEMPOTENCY_LEASE", "idempotency-lease", "how long a request may hold its idempotency key before it is taken as abandoned", func(c *Config) interface{} { return &c.Idempotency.Lease }},
+	{"OUTBOX_POLL_INTERVAL", "outbox-poll-interval", "how often pending events and webhook deliveries are looked for", func(c *Config) interface{} { return &c.Outbox.PollInterval }},
+	{"OUTBOX_MAX_ATTEMPTS", "outbox-max-attempts", "attempts of an outbox message before it is dead lettered", func(c *Config) interface{} { return &c.Outbox.MaxAttempts }},
+	{"WEBHOOK_MAX_ATTEMPTS", "webhook-max-attempts", "attempts of a webhook delivery before it is dead lettered", func(c *Config) interface{} { return &c.Webhook.MaxAttempts }},
+	{"WEBHOOK_TIMEOUT", "webhook-timeout", "maximum duration of a webhook delivery attempt", func(c *Config) interface{} { return &c.Webhook.Timeout }},
+	{"TRACING_EXPORTER", "tracing-exporter", "trace exporter: none, stdout or otlp", func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{"TRACING_OTLP_ENDPOINT", "tracing-otlp-endpoint", "URL of the OTLP/HTTP trace collector", func(c *Config) interface{} { return &c.Tracing.OTLPEndpoint }},
+	{"TRACING_SAMPLE_RATIO", "tracing-sample-ratio", "fraction of new traces that are sampled, from 0 to 1", func(c *Config) interface{} { return &c.Tracing.SampleRatio }},
+	{"TRACING_SERVICE_NAME", "tracing-service-name", "service name reported in traces", func(c *Config) interface{} { return &c.Tracing.ServiceName }},
+}
+
+// Load builds the configuration from the defaults, the YAML file named by the
+// -config flag or CONFIG_FILE, the environment and the flags in args, each
+// overriding the previous ones, and validates it. Every environment variable
+// can instead be read from the file named by the same variable suffixed with
+// _FILE, which suits secrets mounted as files. Load returns the arguments left
+// after the flags.
+func Load(args []string) (*Config, []string, error) {
+	return load(args, os.LookupEnv, os.ReadFile)
+}
+
+func load(args []string, lookupEnv func(string) (string, bool), readFile func(string) ([]byte, error)) (*Config, []string, error) {
+	config := Default()
+
+	flags := flag.NewFlagSet("accounts_transactions", flag.ContinueOnError)
+	configFile := flags.String("config", "", "path of the YAML configuration file")
+	for _, s := range settings {
+		flags.String(s.flag, "", s.usage+" (env "+s.env+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		content, err := readFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading configuration file: %w", err)
+		}
+		decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("parsing configuration file %s: %w", path, err)
+		}
+	}
+
+	var problems []string
+	for _, s := range settings {
+		value, found, err := lookupSecret(s.env, lookupEnv, readFile)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if found {
+			if err = set(s.value(&config), value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", s.env, err))
+			}
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				if err := set(s.value(&config), f.Value.String()); err != nil {
+					problems = append(problems, fmt.Sprintf("-%s: %v", s.flag, err))
+				}
+			}
+		}
+	})
+
+	if config.Database.Port == 0 {
+		config.Database.Port = defaultPorts[config.Database.Driver]
+	}
+
+	if len(problems) == 0 {
+		problems = config.problems()
+	}
+	if len(problems) > 0 {
+		return nil, nil, errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+
+	return &config, flags.Args(), nil
+}
+
+// lookupSecret reads the environment variable name, or the file named by
+// name_FILE. Setting both is an error.
+func lookupSecret(name string, lookupEnv func(string) (string, bool), readFile func(string) ([]byte, error)) (string, bool, error) {
+	value, found := lookupEnv(name)
+	path, fromFile := lookupEnv(name + "_FILE")
+	if !fromFile {
+		return value, found, nil
+	}
+	if found {
+		return "", false, fmt.Errorf("%s and %s_FILE are both set, expected only one", name, name)
+	}
+
+	content, err := readFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %v", name, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+func set(target interface{}, value string) error {
+	switch target := target.(type) {
+	case *string:
+		*target = value
+	case *int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", value)
+		}
+		*target = number
+	case *float64:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", value)
+		}
+		*target = number
+	case *time.Duration:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 1s or 5m, got %q", value)
+		}
+		*target = duration
+	default:
+		return fmt.Errorf("unsupported setting type %T", target)
+	}
+	return nil
+}
+
+// problems returns a readable description of every invalid setting.
+func (c *Config) problems() []string {
+	var problems []string
+	positive := func(name string, value int64) {
+		if value <= 0 {
+			problems = append(problems, name+" must be positive")
+		}
+	}
+
+	if c.Server.Address == "" {
+		problems = append(problems, "server.address must not be empty")
+	}
+	positive("server.read_timeout", int64(c.Server.ReadTimeout))
+	positive("server.write_timeout", int64(c.Server.WriteTimeout))
+	positive("server.idle_timeout", int64(c.Server.IdleTimeout))
+	positive("server.shutdown_timeout", int64(c.Server.ShutdownTimeout))
+
+	switch c.Database.Driver {
+	case MySQL, Postgres:
+		required := []struct{ name, value string }{
+			{"database.host", c.Database.Host},
+			{"database.user", c.Database.User},
+			{"database.name", c.Database.Name},
+		}
+		for _, r := range required {
+			if r.value == "" {
+				problems = append(problems, r.name+" must be set for "+c.Database.Driver)
+			}
+		}
+		if c.Database.Port <= 0 || c.Database.Port > 65535 {
+			problems = append(problems, fmt.Sprintf("database.port must be between 1 and 65535 for %s, got %d", c.Database.Driver, c.Database.Port))
+		}
+	case SQLite:
+		if c.Database.Name == "" {
+			problems = append(problems, "database.name must be the path of the database file for sqlite")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("database.driver must be mysql, postgres or sqlite, got %q", c.Database.Driver))
+	}
+	positive("database.max_open_conns", int64(c.Database.MaxOpenConns))
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "database.max_idle_conns must be between 0 and database.max_open_conns")
+	}
+	positive("database.conn_max_lifetime", int64(c.Database.ConnMaxLifetime))
+	positive("database.conn_max_idle_time", int64(c.Database.ConnMaxIdleTime))
+
+	if _, err := log.ParseLevel(c.Log.Level); err != nil {
+		problems = append(problems, fmt.Sprintf("log.level must be trace, debug, info, warn or error, got %q", c.Log.Level))
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		problems = append(problems, fmt.Sprintf("log.format must be text or json, got %q", c.Log.Format))
+	}
+
+	positive("idempotency.key_ttl", int64(c.Idempotency.KeyTTL))
+	positive("idempotency.lease", int64(c.Idempotency.Lease))
+	positive("outbox.poll_interval", int64(c.Outbox.PollInterval))
+	positive("outbox.max_attempts", int64(c.Outbox.MaxAttempts))
+	positive("webhook.max_attempts", int64(c.Webhook.MaxAttempts))
+	positive("webhook.timeout", int64(c.Webhook.Timeout))
+
+	switch c.Tracing.Exporter {
+	case NoExporter, StdoutExporter, OTLPExporter:
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+	if c.Tracing.ServiceName == "" {
+		problems = append(problems, "tracing.service_name must not be empty")
+	}
+
+	return problems
+}
+
+// ConfigureLogging applies the log level and format to the global logger.
+func ConfigureLogging(config LogConfig) {
+	level, _ := log.ParseLevel(config.Level)
+	log.SetLevel(level)
+	if config.Format == "json" {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeEnv(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, found := values[name]
+		return value, found
+	}
+}
+
+func fakeFiles(files map[string]string) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		content, found := files[path]
+		if !found {
+			return nil, os.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+}
+
+var mysqlEnv = map[string]string{
+	"DB_HOST": "localhost",
+	"DB_USER": "root",
+	"DB_NAME": "accounts",
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	config, args, err := load(nil, fakeEnv(mysqlEnv), fakeFiles(nil))
+
+	require.NoError(t, err)
+	assert.Empty(t, args)
+	assert.Equal(t, ":8080", config.Server.Address)
+	assert.Equal(t, MySQL, config.Database.Driver)
+	assert.Equal(t, 3306, config.Database.Port)
+	assert.Equal(t, 24*time.Hour, config.Idempotency.KeyTTL)
+	assert.Equal(t, time.Minute, config.Idempotency.Lease)
+	assert.Equal(t, 8, config.Outbox.MaxAttempts)
+	assert.Equal(t, 8, config.Webhook.MaxAttempts)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+server:
+  address: ":9000"
+  read_timeout: 5s
+database:
+  driver: postgres
+  host: db
+  user: app
+  name: accounts
+log:
+  level: debug
+webhook:
+  max_attempts: 3
+`,
+	}
+	env := map[string]string{
+		"SERVER_ADDRESS":       ":9100",
+		"WEBHOOK_MAX_ATTEMPTS": "4",
+		"TRACING_SAMPLE_RATIO": "0.25",
+	}
+
+	config, args, err := load([]string{"-config", "config.yaml", "-server-address", ":9200", "migrate", "up"}, fakeEnv(env), fakeFiles(files))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"migrate", "up"}, args)
+	assert.Equal(t, ":9200", config.Server.Address)
+	assert.Equal(t, 5*time.Second, config.Server.ReadTimeout)
+	assert.Equal(t, 30*time.Second, config.Server.WriteTimeout)
+	assert.Equal(t, Postgres, config.Database.Driver)
+	assert.Equal(t, 5432, config.Database.Port)
+	assert.Equal(t, "debug", config.Log.Level)
+	assert.Equal(t, 4, config.Webhook.MaxAttempts)
+	assert.Equal(t, 0.25, config.Tracing.SampleRatio)
+}
+
+func TestLoad_ConfigFileFromEnv(t *testing.T) {
+	files := map[string]string{"app.yaml": "database:\n  driver: sqlite\n  name: accounts.db\n"}
+
+	config, _, err := load(nil, fakeEnv(map[string]string{"CONFIG_FILE": "app.yaml"}), fakeFiles(files))
+
+	require.NoError(t, err)
+	assert.Equal(t, SQLite, config.Database.Driver)
+	assert.Equal(t, "accounts.db", config.Database.Name)
+}
+
+func TestLoad_UnknownFileField(t *testing.T) {
+	files := map[string]string{"config.yaml": "server:\n  adress: \":9000\"\n"}
+
+	_, _, err := load([]string{"-config", "config.yaml"}, fakeEnv(mysqlEnv), fakeFiles(files))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "adress")
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, _, err := load([]string{"-config", "missing.yaml"}, fakeEnv(mysqlEnv), fakeFiles(nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading configuration file")
+}
+
+func TestLoad_SecretFromFile(t *testing.T) {
+	env := map[string]string{"DB_PASSWORD_FILE": "/run/secrets/db_password"}
+	for name, value := range mysqlEnv {
+		env[name] = value
+	}
+
+	config, _, err := load(nil, fakeEnv(env), fakeFiles(map[string]string{"/run/secrets/db_password": "s3cret\n"}))
+
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", config.Database.Password)
+}
+
+func TestLoad_SecretSetTwice(t *testing.T) {
+	env := map[string]string{"DB_PASSWORD": "s3cret", "DB_PASSWORD_FILE": "/run/secrets/db_password"}
+	for name, value := range mysqlEnv {
+		env[name] = value
+	}
+
+	_, _, err := load(nil, fakeEnv(env), fakeFiles(map[string]string{"/run/secrets/db_password": "s3cret"}))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DB_PASSWORD and DB_PASSWORD_FILE are both set")
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	env := map[string]string{
+		"DB_DRIVER":           "oracle",
+		"IDEMPOTENCY_KEY_TTL": "a day",
+		"DB_MAX_OPEN_CONNS":   "many",
+	}
+
+	_, _, err := load(nil, fakeEnv(env), fakeFiles(nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `IDEMPOTENCY_KEY_TTL: expected a duration such as 1s or 5m, got "a day"`)
+	assert.Contains(t, err.Error(), `DB_MAX_OPEN_CONNS: expected an integer, got "many"`)
+}
+
+func TestLoad_Validation(t *testing.T) {
+	env := map[string]string{
+		"DB_DRIVER":            "oracle",
+		"LOG_FORMAT":           "xml",
+		"WEBHOOK_MAX_ATTEMPTS": "0",
+		"DB_MAX_IDLE_CONNS":    "50",
+		"TRACING_EXPORTER":     "jaeger",
+		"TRACING_SAMPLE_RATIO": "1.5",
+	}
+
+	_, _, err := load(nil, fakeEnv(env), fakeFiles(nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `database.driver must be mysql, postgres or sqlite, got "oracle"`)
+	assert.Contains(t, err.Error(), `log.format must be text or json, got "xml"`)
+	assert.Contains(t, err.Error(), "webhook.max_attempts must be positive")
+	assert.Contains(t, err.Error(), "database.max_idle_conns must be between 0 and database.max_open_conns")
+	assert.Contains(t, err.Error(), `tracing.exporter must be none, stdout or otlp, got "jaeger"`)
+	assert.Contains(t, err.Error(), "tracing.sample_ratio must be between 0 and 1, got 1.5")
+}
+
+func TestLoad_MissingDatabaseSettings(t *testing.T) {
+	_, _, err := load(nil, fakeEnv(nil), fakeFiles(nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database.host must be set for mysql")
+	assert.Contains(t, err.Error(), "database.user must be set for mysql")
+	assert.Contains(t, err.Error(), "database.name must be set for mysql")
+}
+
+func TestLoad_InvalidFlag(t *testing.T) {
+	_, _, err := load([]string{"-webhook-timeout", "soon"}, fakeEnv(mysqlEnv), fakeFiles(nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `-webhook-timeout: expected a duration such as 1s or 5m, got "soon"`)
+}
+
+func TestLoad_Help(t *testing.T) {
+	_, _, err := load([]string{"-h"}, fakeEnv(mysqlEnv), fakeFiles(nil))
+
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+}
@@ -2,30 +2,72 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"strconv"
+	"time"
 
+	"github.com/gmerten/accounts_transactions/internal/logging"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-func GetDBConnection() *gorm.DB {
+// Database drivers accepted in database.driver.
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// slowQueryThreshold is the duration above which statements are logged as
+// warnings.
+const slowQueryThreshold = 200 * time.Millisecond
 
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
+// defaultPorts are used when database.port is not set.
+var defaultPorts = map[string]int{
+	MySQL:    3306,
+	Postgres: 5432,
+}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=true&loc=Local", dbUser, dbPassword, dbHost, dbPort, dbName)
+// GetDBConnection connects to the configured database and sizes its connection
+// pool. MySQL and PostgreSQL connect to the configured server, and SQLite opens
+// the database file named by database.name.
+func GetDBConnection(config DatabaseConfig) (*gorm.DB, error) {
 
-	db, errorDB := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+	db, errorDB := gorm.Open(getDialector(config), &gorm.Config{
+		Logger: logging.GormLogger(slowQueryThreshold),
 	})
 
 	if errorDB != nil {
-		panic("Failed to connect mysql database")
+		return nil, fmt.Errorf("failed to connect %s database: %w", config.Driver, errorDB)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	return db
+	return db, nil
+}
+
+func getDialector(config DatabaseConfig) gorm.Dialector {
+
+	port := strconv.Itoa(config.Port)
+
+	switch config.Driver {
+	case Postgres:
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", config.Host, port, config.User, config.Password, config.Name, config.SSLMode)
+		return postgres.Open(dsn)
+	case SQLite:
+		// Foreign keys are off by default in SQLite, and the busy timeout lets the
+		// background workers wait for the write lock instead of failing.
+		return sqlite.Open(config.Name + "?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL")
+	default:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=true&loc=Local", config.User, config.Password, config.Host, port, config.Name)
+		return mysql.Open(dsn)
+	}
 }
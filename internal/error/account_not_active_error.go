@@ -0,0 +1,24 @@
+package errors
+
+import "net/http"
+
+// AccountNotActiveError rejects postings to blocked or closed accounts.
+type AccountNotActiveError struct {
+	Message string
+}
+
+func (e AccountNotActiveError) Error() string {
+	return e.Message
+}
+
+func (e AccountNotActiveError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e AccountNotActiveError) Code() Code {
+	return AccountNotActive
+}
+
+func NewAccountNotActiveError(message string) AccountNotActiveError {
+	return AccountNotActiveError{message}
+}
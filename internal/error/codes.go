@@ -0,0 +1,133 @@
+package errors
+
+import "sort"
+
+// Code is a stable, machine readable identifier of an error, so clients can
+// tell failures apart without matching their messages. Codes are part of the
+// API contract: messages may be reworded, codes never change once published.
+type Code string
+
+// Generic codes of each error type, used when no more specific code applies.
+const (
+	ValidationFailed    Code = "VALIDATION_FAILED"
+	Unauthorized        Code = "UNAUTHORIZED"
+	Forbidden           Code = "FORBIDDEN"
+	NotFound            Code = "NOT_FOUND"
+	Conflict            Code = "CONFLICT"
+	UnprocessableEntity Code = "UNPROCESSABLE_ENTITY"
+	InternalError       Code = "INTERNAL_ERROR"
+)
+
+// Codes of the validation errors, answered with 400.
+const (
+	InvalidRequestBody    Code = "INVALID_REQUEST_BODY"
+	InvalidParameter      Code = "INVALID_PARAMETER"
+	InvalidDocumentNumber Code = "INVALID_DOCUMENT_NUMBER"
+	InvalidOperationType  Code = "INVALID_OPERATION_TYPE"
+	OperationTypeInactive Code = "OPERATION_TYPE_NOT_ACTIVE"
+	InvalidInstallments   Code = "INVALID_INSTALLMENTS"
+	InvalidPeriod         Code = "INVALID_PERIOD"
+	InvalidIdempotencyKey Code = "INVALID_IDEMPOTENCY_KEY"
+)
+
+// Codes of the authentication errors, answered with 401.
+const (
+	APIKeyRequired Code = "API_KEY_REQUIRED"
+	InvalidAPIKey  Code = "INVALID_API_KEY"
+)
+
+// Codes of the authorization errors, answered with 403.
+const (
+	InsufficientScope Code = "INSUFFICIENT_SCOPE"
+)
+
+// Codes of the not found errors, answered with 404.
+const (
+	AccountNotFound         Code = "ACCOUNT_NOT_FOUND"
+	TransactionNotFound     Code = "TRANSACTION_NOT_FOUND"
+	InstallmentPlanNotFound Code = "INSTALLMENT_PLAN_NOT_FOUND"
+	InstallmentNotFound     Code = "INSTALLMENT_NOT_FOUND"
+	StatementNotFound       Code = "STATEMENT_NOT_FOUND"
+	WebhookNotFound         Code = "WEBHOOK_NOT_FOUND"
+	APIClientNotFound       Code = "API_CLIENT_NOT_FOUND"
+)
+
+// Codes of the conflict errors, answered with 409.
+const (
+	DuplicateDocument      Code = "DUPLICATE_DOCUMENT"
+	DuplicateOperationType Code = "DUPLICATE_OPERATION_TYPE"
+	IdempotencyKeyInUse    Code = "IDEMPOTENCY_KEY_IN_USE"
+)
+
+// Codes of the requests that are well formed but cannot be applied, answered
+// with 422.
+const (
+	IdempotencyKeyReused       Code = "IDEMPOTENCY_KEY_REUSED"
+	InvalidStatusTransition    Code = "INVALID_STATUS_TRANSITION"
+	AccountBalanceNotZero      Code = "ACCOUNT_BALANCE_NOT_ZERO"
+	AccountNotActive           Code = "ACCOUNT_NOT_ACTIVE"
+	CreditLimitExceeded        Code = "CREDIT_LIMIT_EXCEEDED"
+	ReversalNotAllowed         Code = "REVERSAL_NOT_ALLOWED"
+	TransactionAlreadyReversed Code = "TRANSACTION_ALREADY_REVERSED"
+	InvalidReversalAmount      Code = "INVALID_REVERSAL_AMOUNT"
+)
+
+// titles summarize every code of the catalog. They are the same for every
+// occurrence of the code, while the message of the error details it.
+var titles = map[Code]string{
+	ValidationFailed:           "The request is invalid",
+	Unauthorized:               "The request is not authenticated",
+	Forbidden:                  "The request is not allowed",
+	NotFound:                   "The resource was not found",
+	Conflict:                   "The request conflicts with existing data",
+	UnprocessableEntity:        "The request cannot be applied",
+	InternalError:              "An unexpected error occurred",
+	InvalidRequestBody:         "The request body is invalid",
+	InvalidParameter:           "A path or query parameter is invalid",
+	InvalidDocumentNumber:      "The document number is not a valid CPF or CNPJ",
+	InvalidOperationType:       "The operation type does not exist",
+	OperationTypeInactive:      "The operation type is not active",
+	InvalidInstallments:        "The installment count is not allowed",
+	InvalidPeriod:              "The statement period is invalid",
+	InvalidIdempotencyKey:      "The Idempotency-Key header is invalid",
+	APIKeyRequired:             "An API key is required",
+	InvalidAPIKey:              "The API key is invalid or disabled",
+	InsufficientScope:          "The API key lacks the scope of the request",
+	AccountNotFound:            "The account was not found",
+	TransactionNotFound:        "The transaction was not found",
+	InstallmentPlanNotFound:    "The transaction has no installment plan",
+	InstallmentNotFound:        "The installment was not found",
+	StatementNotFound:          "The statement was not found",
+	WebhookNotFound:            "The webhook was not found",
+	APIClientNotFound:          "The API client was not found",
+	DuplicateDocument:          "An account with this document number already exists",
+	DuplicateOperationType:     "An operation type with this ID already exists",
+	IdempotencyKeyInUse:        "A request with this Idempotency-Key is still being processed",
+	IdempotencyKeyReused:       "The Idempotency-Key was used with a different request",
+	InvalidStatusTransition:    "The account cannot change to this status",
+	AccountBalanceNotZero:      "The account balance is not zero",
+	AccountNotActive:           "The account is not active",
+	CreditLimitExceeded:        "The available credit limit is exceeded",
+	ReversalNotAllowed:         "The transaction cannot be reversed",
+	TransactionAlreadyReversed: "The transaction is already reversed",
+	InvalidReversalAmount:      "The reversal amount is invalid",
+}
+
+// Title returns the summary of the code, or the one of an internal error for
+// codes that are not in the catalog.
+func (c Code) Title() string {
+	if title, ok := titles[c]; ok {
+		return title
+	}
+	return titles[InternalError]
+}
+
+// Codes returns every code of the catalog, sorted.
+func Codes() []Code {
+	codes := make([]Code, 0, len(titles))
+	for code := range titles {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
@@ -1,15 +1,10 @@
 package errors
 
-import (
-	"errors"
-	"net/http"
-
-	"github.com/go-sql-driver/mysql"
-	"gorm.io/gorm"
-)
+import "net/http"
 
 type ConflictError struct {
-	Message string
+	Message   string
+	ErrorCode Code
 }
 
 func (e ConflictError) Error() string {
@@ -20,20 +15,14 @@ func (e ConflictError) StatusCode() int {
 	return http.StatusConflict
 }
 
-func NewConflictError(message string) ConflictError {
-	return ConflictError{message}
-}
-
-func IsDuplicateKeyError(err error) bool {
-	var mysqlErr *mysql.MySQLError
-
-	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
-		return true
-	}
-
-	if errors.Is(err, gorm.ErrDuplicatedKey) || err.Error() == "UNIQUE constraint failed" {
-		return true
+// Code returns the code of the error, Conflict when it has none.
+func (e ConflictError) Code() Code {
+	if e.ErrorCode == "" {
+		return Conflict
 	}
+	return e.ErrorCode
+}
 
-	return false
+func NewConflictError(code Code, message string) ConflictError {
+	return ConflictError{message, code}
 }
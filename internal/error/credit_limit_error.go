@@ -0,0 +1,24 @@
+package errors
+
+import "net/http"
+
+// CreditLimitExceededError rejects debits beyond the available credit limit.
+type CreditLimitExceededError struct {
+	Message string
+}
+
+func (e CreditLimitExceededError) Error() string {
+	return e.Message
+}
+
+func (e CreditLimitExceededError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e CreditLimitExceededError) Code() Code {
+	return CreditLimitExceeded
+}
+
+func NewCreditLimitExceededError(message string) CreditLimitExceededError {
+	return CreditLimitExceededError{message}
+}
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// MySQL error numbers and PostgreSQL SQLSTATE codes of constraint violations.
+const (
+	mysqlDuplicateEntry         = 1062
+	mysqlRowIsReferenced        = 1451
+	mysqlNoReferencedRow        = 1452
+	postgresUniqueViolation     = "23505"
+	postgresForeignKeyViolation = "23503"
+)
+
+// IsDuplicateKeyError reports whether err is a unique constraint violation on
+// any of the supported databases.
+func IsDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return true
+	}
+
+	var postgresErr *pgconn.PgError
+	if errors.As(err, &postgresErr) && postgresErr.Code == postgresUniqueViolation {
+		return true
+	}
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) || strings.HasPrefix(err.Error(), "UNIQUE constraint failed") {
+		return true
+	}
+
+	return false
+}
+
+// IsForeignKeyError reports whether err is a foreign key constraint violation
+// on any of the supported databases, either because the referenced row does
+// not exist or because a row still references the one being deleted.
+func IsForeignKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && (mysqlErr.Number == mysqlRowIsReferenced || mysqlErr.Number == mysqlNoReferencedRow) {
+		return true
+	}
+
+	var postgresErr *pgconn.PgError
+	if errors.As(err, &postgresErr) && postgresErr.Code == postgresForeignKeyViolation {
+		return true
+	}
+
+	if errors.Is(err, gorm.ErrForeignKeyViolated) || strings.HasPrefix(err.Error(), "FOREIGN KEY constraint failed") {
+		return true
+	}
+
+	return false
+}
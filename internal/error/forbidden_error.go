@@ -0,0 +1,30 @@
+package errors
+
+import "net/http"
+
+// ForbiddenError rejects a request of an authenticated caller that is not
+// allowed to make it.
+type ForbiddenError struct {
+	Message   string
+	ErrorCode Code
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
+
+func (e ForbiddenError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// Code returns the code of the error, Forbidden when it has none.
+func (e ForbiddenError) Code() Code {
+	if e.ErrorCode == "" {
+		return Forbidden
+	}
+	return e.ErrorCode
+}
+
+func NewForbiddenError(code Code, message string) ForbiddenError {
+	return ForbiddenError{message, code}
+}
@@ -3,7 +3,8 @@ package errors
 import "net/http"
 
 type NotFoundError struct {
-	Message string
+	Message   string
+	ErrorCode Code
 }
 
 func (e NotFoundError) Error() string {
@@ -14,6 +15,14 @@ func (e NotFoundError) StatusCode() int {
 	return http.StatusNotFound
 }
 
-func NewNotFoundError(message string) NotFoundError {
-	return NotFoundError{message}
+// Code returns the code of the error, NotFound when it has none.
+func (e NotFoundError) Code() Code {
+	if e.ErrorCode == "" {
+		return NotFound
+	}
+	return e.ErrorCode
+}
+
+func NewNotFoundError(code Code, message string) NotFoundError {
+	return NotFoundError{message, code}
 }
@@ -0,0 +1,29 @@
+package errors
+
+import "net/http"
+
+// UnauthorizedError rejects a request that does not authenticate its caller.
+type UnauthorizedError struct {
+	Message   string
+	ErrorCode Code
+}
+
+func (e UnauthorizedError) Error() string {
+	return e.Message
+}
+
+func (e UnauthorizedError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// Code returns the code of the error, Unauthorized when it has none.
+func (e UnauthorizedError) Code() Code {
+	if e.ErrorCode == "" {
+		return Unauthorized
+	}
+	return e.ErrorCode
+}
+
+func NewUnauthorizedError(code Code, message string) UnauthorizedError {
+	return UnauthorizedError{message, code}
+}
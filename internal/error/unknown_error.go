@@ -2,6 +2,7 @@ package errors
 
 import "net/http"
 
+// UnknownError reports unexpected failures, whose cause is only logged.
 type UnknownError struct {
 	Message string
 }
@@ -14,6 +15,10 @@ func (e UnknownError) StatusCode() int {
 	return http.StatusInternalServerError
 }
 
+func (e UnknownError) Code() Code {
+	return InternalError
+}
+
 func NewUnknownError(message string) UnknownError {
 	return UnknownError{message}
 }
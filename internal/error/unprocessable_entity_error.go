@@ -0,0 +1,28 @@
+package errors
+
+import "net/http"
+
+type UnprocessableEntityError struct {
+	Message   string
+	ErrorCode Code
+}
+
+func (e UnprocessableEntityError) Error() string {
+	return e.Message
+}
+
+func (e UnprocessableEntityError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Code returns the code of the error, UnprocessableEntity when it has none.
+func (e UnprocessableEntityError) Code() Code {
+	if e.ErrorCode == "" {
+		return UnprocessableEntity
+	}
+	return e.ErrorCode
+}
+
+func NewUnprocessableEntityError(code Code, message string) UnprocessableEntityError {
+	return UnprocessableEntityError{message, code}
+}
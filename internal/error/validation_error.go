@@ -3,6 +3,19 @@ package errors
 import "net/http"
 
 type ValidationError struct {
+	Message   string
+	ErrorCode Code
+	// Violations lists the fields of the request that are invalid, when the
+	// error is about its fields.
+	Violations []FieldViolation
+}
+
+// FieldViolation describes a field of the request that failed a validation
+// rule, such as gte with the parameter 1.
+type FieldViolation struct {
+	Field   string
+	Rule    string
+	Param   string
 	Message string
 }
 
@@ -14,6 +27,19 @@ func (e ValidationError) StatusCode() int {
 	return http.StatusBadRequest
 }
 
-func NewValidationError(message string) ValidationError {
-	return ValidationError{message}
+// Code returns the code of the error, ValidationFailed when it has none.
+func (e ValidationError) Code() Code {
+	if e.ErrorCode == "" {
+		return ValidationFailed
+	}
+	return e.ErrorCode
+}
+
+func NewValidationError(code Code, message string) ValidationError {
+	return ValidationError{Message: message, ErrorCode: code}
+}
+
+// NewFieldValidationError reports the fields of the request that are invalid.
+func NewFieldValidationError(code Code, message string, violations []FieldViolation) ValidationError {
+	return ValidationError{Message: message, ErrorCode: code, Violations: violations}
 }
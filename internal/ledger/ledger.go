@@ -0,0 +1,129 @@
+// Package ledger keeps a double-entry record of every posting. Each
+// transaction is written as a journal whose entries debit and credit ledger
+// accounts, and every journal must sum to zero.
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+)
+
+// Account is the code of a ledger account. Each customer account has its own
+// receivable ledger account, and the other side of a posting goes to one of
+// the shared accounts below.
+type Account string
+
+const (
+	MerchantSettlement Account = "merchant_settlement"
+	Cash               Account = "cash"
+	Fees               Account = "fees"
+
+	customerPrefix = "customer:"
+)
+
+var ErrUnbalanced = errors.New("journal is unbalanced")
+
+// CustomerAccount is the ledger account of a customer account, debited with
+// what the customer owes and credited with what they pay.
+func CustomerAccount(accountID int64) Account {
+	return Account(customerPrefix + strconv.FormatInt(accountID, 10))
+}
+
+// Journal groups the entries written for one transaction. Entries are signed:
+// debits are positive and credits negative.
+type Journal struct {
+	ID            int64     `gorm:"primaryKey"`
+	TransactionID int64     `gorm:"not null;uniqueIndex"`
+	PostedAt      time.Time `gorm:"not null"`
+	Entries       []Entry   `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (Journal) TableName() string {
+	return "ledger_journals"
+}
+
+// Entry is one leg of a journal.
+type Entry struct {
+	ID            int64       `gorm:"primaryKey"`
+	JournalID     int64       `gorm:"not null;index"`
+	LedgerAccount Account     `gorm:"size:64;not null;index"`
+	Amount        model.Money `gorm:"not null"`
+}
+
+func (Entry) TableName() string {
+	return "ledger_entries"
+}
+
+// Sum is the total of the journal entries, zero when it is balanced.
+func (j *Journal) Sum() model.Money {
+	var sum model.Money
+	for _, entry := range j.Entries {
+		sum += entry.Amount
+	}
+	return sum
+}
+
+// Validate checks that the journal has a debit and a credit leg, no empty
+// legs, and that its entries sum to zero.
+func (j *Journal) Validate() error {
+	var debits, credits int
+	for _, entry := range j.Entries {
+		switch {
+		case entry.Amount > 0:
+			debits++
+		case entry.Amount < 0:
+			credits++
+		default:
+			return fmt.Errorf("%w: entry on %s has no amount", ErrUnbalanced, entry.LedgerAccount)
+		}
+	}
+
+	if debits == 0 || credits == 0 {
+		return fmt.Errorf("%w: journal needs a debit and a credit leg", ErrUnbalanced)
+	}
+	if sum := j.Sum(); sum != 0 {
+		return fmt.Errorf("%w: entries sum to %s", ErrUnbalanced, sum)
+	}
+	return nil
+}
+
+// NewTransactionJournal builds the journal of a posted transaction. The
+// customer ledger account takes the opposite of the transaction amount, since
+// a negative amount is owed to us, and the counterparty takes the amount.
+func NewTransactionJournal(transaction *model.Transaction) *Journal {
+	return &Journal{
+		TransactionID: transaction.ID,
+		PostedAt:      transaction.TransactionDate,
+		Entries: []Entry{
+			{LedgerAccount: CustomerAccount(transaction.AccountID), Amount: -transaction.Amount},
+			{LedgerAccount: counterparty(transaction), Amount: transaction.Amount},
+		},
+	}
+}
+
+// counterparty picks the shared ledger account on the other side of a
+// transaction. Purchases settle with merchants, while withdrawals and payments
+// move cash. Other catalog operations are fees when they charge the customer
+// and merchant refunds when they pay them back. A reversal uses the account of
+// the transaction it reverses, so it mirrors the original journal.
+func counterparty(transaction *model.Transaction) Account {
+	switch transaction.OperationType {
+	case model.Purchase, model.InstallmentPurchase:
+		return MerchantSettlement
+	case model.Withdrawal, model.Payment:
+		return Cash
+	}
+
+	charge := transaction.Amount < 0
+	if transaction.ReversalOfID != nil {
+		charge = !charge
+	}
+	if charge {
+		return Fees
+	}
+	return MerchantSettlement
+}
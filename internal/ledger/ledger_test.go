@@ -0,0 +1,84 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransactionJournal(t *testing.T) {
+	reversalOf := int64(1)
+	feeType := model.OperationType(5)
+
+	tests := []struct {
+		name         string
+		transaction  *model.Transaction
+		counterparty Account
+	}{
+		{"purchase", &model.Transaction{OperationType: model.Purchase, Amount: model.MustParseMoney("-100.00")}, MerchantSettlement},
+		{"installment purchase", &model.Transaction{OperationType: model.InstallmentPurchase, Amount: model.MustParseMoney("-100.00")}, MerchantSettlement},
+		{"withdrawal", &model.Transaction{OperationType: model.Withdrawal, Amount: model.MustParseMoney("-100.00")}, Cash},
+		{"payment", &model.Transaction{OperationType: model.Payment, Amount: model.MustParseMoney("100.00")}, Cash},
+		{"purchase reversal", &model.Transaction{OperationType: model.Purchase, Amount: model.MustParseMoney("100.00"), ReversalOfID: &reversalOf}, MerchantSettlement},
+		{"catalog charge", &model.Transaction{OperationType: feeType, Amount: model.MustParseMoney("-100.00")}, Fees},
+		{"catalog charge reversal", &model.Transaction{OperationType: feeType, Amount: model.MustParseMoney("100.00"), ReversalOfID: &reversalOf}, Fees},
+		{"catalog refund", &model.Transaction{OperationType: feeType, Amount: model.MustParseMoney("100.00")}, MerchantSettlement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.transaction.ID = 7
+			tt.transaction.AccountID = 3
+
+			journal := NewTransactionJournal(tt.transaction)
+
+			assert.NoError(t, journal.Validate())
+			assert.Equal(t, int64(7), journal.TransactionID)
+			assert.Equal(t, Account("customer:3"), journal.Entries[0].LedgerAccount)
+			assert.Equal(t, -tt.transaction.Amount, journal.Entries[0].Amount)
+			assert.Equal(t, tt.counterparty, journal.Entries[1].LedgerAccount)
+			assert.Equal(t, tt.transaction.Amount, journal.Entries[1].Amount)
+		})
+	}
+}
+
+func TestJournal_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+	}{
+		{"no entries", nil},
+		{"single leg", []Entry{{LedgerAccount: Cash, Amount: model.MustParseMoney("10.00")}}},
+		{"empty leg", []Entry{{LedgerAccount: Cash, Amount: model.MustParseMoney("10.00")}, {LedgerAccount: Fees}, {LedgerAccount: Fees, Amount: model.MustParseMoney("-10.00")}}},
+		{"unbalanced", []Entry{{LedgerAccount: Cash, Amount: model.MustParseMoney("10.00")}, {LedgerAccount: Fees, Amount: model.MustParseMoney("-9.99")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journal := &Journal{Entries: tt.entries}
+			assert.True(t, errors.Is(journal.Validate(), ErrUnbalanced))
+		})
+	}
+}
+
+func TestReport_Check(t *testing.T) {
+	balanced := *NewTransactionJournal(&model.Transaction{ID: 1, AccountID: 1, OperationType: model.Payment, Amount: model.MustParseMoney("50.00")})
+	unbalanced := Journal{ID: 2, TransactionID: 2, Entries: []Entry{
+		{LedgerAccount: CustomerAccount(1), Amount: model.MustParseMoney("10.00")},
+		{LedgerAccount: MerchantSettlement, Amount: model.MustParseMoney("-9.00")},
+	}}
+
+	report := &Report{}
+	report.Check([]Journal{balanced})
+	assert.True(t, report.Balanced())
+
+	report.Check([]Journal{unbalanced})
+	assert.False(t, report.Balanced())
+	assert.Equal(t, 2, report.JournalsChecked)
+	assert.Equal(t, model.MustParseMoney("1.00"), report.Total)
+	assert.Len(t, report.Imbalances, 1)
+	assert.Equal(t, int64(2), report.Imbalances[0].TransactionID)
+	assert.Equal(t, model.MustParseMoney("1.00"), report.Imbalances[0].Sum)
+}
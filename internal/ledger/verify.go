@@ -0,0 +1,41 @@
+package ledger
+
+import "github.com/gmerten/accounts_transactions/internal/model"
+
+// Imbalance describes a journal that failed validation.
+type Imbalance struct {
+	JournalID     int64
+	TransactionID int64
+	Sum           model.Money
+	Reason        string
+}
+
+// Report is the outcome of verifying the ledger. Total is the sum of every
+// entry checked, which is zero when the whole ledger is balanced.
+type Report struct {
+	JournalsChecked int
+	Total           model.Money
+	Imbalances      []Imbalance
+}
+
+func (r *Report) Balanced() bool {
+	return len(r.Imbalances) == 0 && r.Total == 0
+}
+
+// Check validates the journals and adds them to the report.
+func (r *Report) Check(journals []Journal) {
+	for i := range journals {
+		journal := &journals[i]
+		r.JournalsChecked++
+		r.Total += journal.Sum()
+
+		if err := journal.Validate(); err != nil {
+			r.Imbalances = append(r.Imbalances, Imbalance{
+				JournalID:     journal.ID,
+				TransactionID: journal.TransactionID,
+				Sum:           journal.Sum(),
+				Reason:        err.Error(),
+			})
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package lifecycle runs the background workers of the application and stops
+// them when it shuts down.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// Worker runs until its context is cancelled.
+type Worker func(ctx context.Context)
+
+type manager struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mutex   sync.Mutex
+	running map[string]int
+	done    sync.WaitGroup
+}
+
+type Manager interface {
+	Go(name string, worker Worker)
+	Shutdown(ctx context.Context) error
+}
+
+func NewManager() Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &manager{ctx: ctx, cancel: cancel, running: make(map[string]int)}
+}
+
+// Go starts a worker in its own goroutine. Workers started after Shutdown
+// return immediately, since their context is already cancelled.
+func (m *manager) Go(name string, worker Worker) {
+	m.mutex.Lock()
+	m.running[name]++
+	m.mutex.Unlock()
+	m.done.Add(1)
+
+	go func() {
+		defer func() {
+			m.mutex.Lock()
+			if m.running[name]--; m.running[name] == 0 {
+				delete(m.running, name)
+			}
+			m.mutex.Unlock()
+			m.done.Done()
+		}()
+
+		logger := log.WithField("worker", name)
+		logger.Info("Worker started")
+		worker(logging.NewContext(m.ctx, logger))
+		logger.Info("Worker stopped")
+	}()
+}
+
+// Shutdown cancels the context of every worker and waits for them to return.
+// If ctx ends first, it returns an error naming the workers still running.
+func (m *manager) Shutdown(ctx context.Context) error {
+	m.cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		m.done.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		m.mutex.Lock()
+		names := make([]string, 0, len(m.running))
+		for name := range m.running {
+			names = append(names, name)
+		}
+		m.mutex.Unlock()
+		sort.Strings(names)
+		return fmt.Errorf("workers still running after shutdown timeout: %s", strings.Join(names, ", "))
+	}
+}
+
+// Every returns a Worker that runs task once per interval. A run in progress
+// is finished before the worker stops, so task gets a context that keeps the
+// values of the worker context but is not cancelled with it.
+func Every(interval time.Duration, task func(ctx context.Context)) Worker {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				task(context.WithoutCancel(ctx))
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package lifecycle
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ShutdownStopsWorkers(t *testing.T) {
+	manager := NewManager()
+
+	var stopped atomic.Int32
+	for _, name := range []string{"first", "second"} {
+		manager.Go(name, func(ctx context.Context) {
+			<-ctx.Done()
+			stopped.Add(1)
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, manager.Shutdown(ctx))
+	assert.Equal(t, int32(2), stopped.Load())
+}
+
+func TestManager_ShutdownTimeout(t *testing.T) {
+	manager := NewManager()
+
+	release := make(chan struct{})
+	defer close(release)
+	manager.Go("stuck", func(ctx context.Context) {
+		<-release
+	})
+	manager.Go("quick", func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := manager.Shutdown(ctx)
+
+	require.Error(t, err)
+	assert.Equal(t, "workers still running after shutdown timeout: stuck", err.Error())
+}
+
+func TestManager_GoAfterShutdown(t *testing.T) {
+	manager := NewManager()
+	require.NoError(t, manager.Shutdown(context.Background()))
+
+	done := make(chan struct{})
+	manager.Go("late", func(ctx context.Context) {
+		<-ctx.Done()
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker started after shutdown did not stop")
+	}
+}
+
+func TestEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var runs atomic.Int32
+	stopped := make(chan struct{})
+	go func() {
+		Every(time.Millisecond, func(ctx context.Context) {
+			if runs.Add(1) == 3 {
+				cancel()
+			}
+		})(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop after its context was cancelled")
+	}
+	assert.GreaterOrEqual(t, runs.Load(), int32(3))
+}
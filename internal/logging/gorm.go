@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type gormLogger struct {
+	slowThreshold time.Duration
+}
+
+// GormLogger logs the statements gorm runs with the logger of their context,
+// through WithContext, so they carry the ID of the request that ran them.
+// Statements are logged at debug level, or as warnings when they take longer
+// than slowThreshold, always with their placeholders and never the values
+// bound to them.
+func GormLogger(slowThreshold time.Duration) logger.Interface {
+	return gormLogger{slowThreshold}
+}
+
+// LogMode is a no-op, the statements follow the level of the logger.
+func (l gormLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (gormLogger) Info(ctx context.Context, message string, data ...interface{}) {
+	FromContext(ctx).Infof(message, data...)
+}
+
+func (gormLogger) Warn(ctx context.Context, message string, data ...interface{}) {
+	FromContext(ctx).Warnf(message, data...)
+}
+
+func (gormLogger) Error(ctx context.Context, message string, data ...interface{}) {
+	FromContext(ctx).Errorf(message, data...)
+}
+
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	entry := FromContext(ctx)
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+	if !slow && !entry.Logger.IsLevelEnabled(log.DebugLevel) {
+		return
+	}
+
+	sql, rows := fc()
+	entry = entry.WithFields(log.Fields{
+		"sql":       sql,
+		"rows":      rows,
+		"elapsedMs": float64(elapsed.Microseconds()) / 1000,
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		entry = entry.WithError(err)
+	}
+
+	if slow {
+		entry.Warn("Slow statement")
+		return
+	}
+	entry.Debug("Statement")
+}
+
+// ParamsFilter drops the values bound to the statement before it is logged.
+func (gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
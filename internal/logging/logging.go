@@ -0,0 +1,63 @@
+// Package logging keeps a request-scoped logger in the context, so every line
+// logged while serving a request carries its request ID, and the fields added
+// along the way, such as the account, end up in its access log.
+package logging
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// scope holds the logger of a request. Fields added by any layer are seen by
+// the lines logged afterwards, including the access log.
+type scope struct {
+	mutex sync.Mutex
+	entry *log.Entry
+}
+
+// NewContext returns a context holding entry as its logger.
+func NewContext(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey, &scope{entry: entry})
+}
+
+// FromContext returns the logger of ctx, or the standard logger when it has
+// none, as in the command-line tools.
+func FromContext(ctx context.Context) *log.Entry {
+	if scope, ok := ctx.Value(loggerKey).(*scope); ok {
+		scope.mutex.Lock()
+		defer scope.mutex.Unlock()
+		return scope.entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// AddField adds a field to the logger of ctx for the rest of the request. It
+// does nothing when ctx has no logger of its own.
+func AddField(ctx context.Context, key string, value interface{}) {
+	if scope, ok := ctx.Value(loggerKey).(*scope); ok {
+		scope.mutex.Lock()
+		scope.entry = scope.entry.WithField(key, value)
+		scope.mutex.Unlock()
+	}
+}
+
+// WithRequestID returns a context holding the ID of the request being served.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the request being served, or an empty string
+// outside a request.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
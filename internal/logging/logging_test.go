@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestFromContext_StandardLogger(t *testing.T) {
+	entry := FromContext(context.Background())
+
+	assert.Equal(t, log.StandardLogger(), entry.Logger)
+	assert.Empty(t, entry.Data)
+}
+
+func TestAddField(t *testing.T) {
+	logger, hook := logTest.NewNullLogger()
+	ctx := NewContext(context.Background(), logger.WithField("requestID", "abc"))
+
+	AddField(ctx, "accountID", int64(42))
+	FromContext(ctx).Info("Done")
+
+	require.Len(t, hook.Entries, 1)
+	assert.Equal(t, log.Fields{"requestID": "abc", "accountID": int64(42)}, hook.LastEntry().Data)
+}
+
+func TestAddField_WithoutLogger(t *testing.T) {
+	ctx := context.Background()
+
+	AddField(ctx, "accountID", int64(42))
+
+	assert.NotContains(t, FromContext(ctx).Data, "accountID")
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+	assert.Equal(t, "abc", RequestID(WithRequestID(context.Background(), "abc")))
+}
+
+func TestGormLogger(t *testing.T) {
+	logger, hook := logTest.NewNullLogger()
+	logger.SetLevel(log.DebugLevel)
+	ctx := NewContext(context.Background(), logger.WithField("requestID", "abc"))
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: GormLogger(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("CREATE TABLE webhooks (id INTEGER PRIMARY KEY, secret TEXT)").Error)
+
+	require.NoError(t, db.WithContext(ctx).Exec("INSERT INTO webhooks (secret) VALUES (?)", "s3cret").Error)
+
+	require.Len(t, hook.Entries, 1)
+	entry := hook.LastEntry()
+	assert.Equal(t, log.DebugLevel, entry.Level)
+	assert.Equal(t, "abc", entry.Data["requestID"])
+	assert.Equal(t, "INSERT INTO webhooks (secret) VALUES (?)", entry.Data["sql"])
+	assert.Equal(t, int64(1), entry.Data["rows"])
+}
+
+func TestGormLogger_SlowStatement(t *testing.T) {
+	logger, hook := logTest.NewNullLogger()
+	ctx := NewContext(context.Background(), logger.WithField("requestID", "abc"))
+
+	GormLogger(time.Millisecond).Trace(ctx, time.Now().Add(-time.Second), func() (string, int64) {
+		return "SELECT * FROM accounts", 1
+	}, nil)
+	GormLogger(time.Hour).Trace(ctx, time.Now(), func() (string, int64) {
+		return "SELECT * FROM accounts", 1
+	}, nil)
+
+	require.Len(t, hook.Entries, 1)
+	assert.Equal(t, log.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Slow statement", hook.LastEntry().Message)
+}
@@ -0,0 +1,127 @@
+// Package metrics records the application metrics and exposes them in the
+// Prometheus format, so the rest of the application records them without
+// depending on the Prometheus client.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "accounts_transactions"
+
+// Services that label the conflict and validation failure counters.
+const (
+	AccountService       = "account"
+	TransactionService   = "transaction"
+	OperationTypeService = "operation_type"
+	StatementService     = "statement"
+	IdempotencyService   = "idempotency"
+)
+
+// UnmatchedRoute labels requests that matched no route, so unknown paths do
+// not create a series each.
+const UnmatchedRoute = "unmatched"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route pattern and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	accountsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accounts_created_total",
+		Help:      "Accounts created.",
+	})
+
+	transactionsPosted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_posted_total",
+		Help:      "Transactions posted by operation type, reversals excluded.",
+	}, []string{"operation_type"})
+
+	transactionsReversed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_reversed_total",
+		Help:      "Reversals posted by the operation type of the reversed transaction.",
+	}, []string{"operation_type"})
+
+	conflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "conflicts_total",
+		Help:      "Requests rejected because they conflict with existing data, by service.",
+	}, []string{"service"})
+
+	validationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validation_failures_total",
+		Help:      "Requests rejected by the business validations of a service, by service.",
+	}, []string{"service"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		accountsCreated,
+		transactionsPosted,
+		transactionsReversed,
+		conflicts,
+		validationFailures,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exposes the connection pool statistics of a database, labelled
+// with its name.
+func RegisterDB(db *sql.DB, name string) error {
+	return registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func AccountCreated() {
+	accountsCreated.Inc()
+}
+
+func TransactionPosted(operationType model.OperationType) {
+	transactionsPosted.WithLabelValues(strconv.FormatInt(int64(operationType), 10)).Inc()
+}
+
+func TransactionReversed(operationType model.OperationType) {
+	transactionsReversed.WithLabelValues(strconv.FormatInt(int64(operationType), 10)).Inc()
+}
+
+func Conflict(service string) {
+	conflicts.WithLabelValues(service).Inc()
+}
+
+func ValidationFailed(service string) {
+	validationFailures.WithLabelValues(service).Inc()
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestObserveRequest(t *testing.T) {
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/accounts/{accountID}", "404"))
+
+	ObserveRequest("GET", "/accounts/{accountID}", http.StatusNotFound, 15*time.Millisecond)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/accounts/{accountID}", "404")))
+}
+
+func TestBusinessCounters(t *testing.T) {
+	accounts := testutil.ToFloat64(accountsCreated)
+	purchases := testutil.ToFloat64(transactionsPosted.WithLabelValues("1"))
+	reversals := testutil.ToFloat64(transactionsReversed.WithLabelValues("1"))
+	accountConflicts := testutil.ToFloat64(conflicts.WithLabelValues(AccountService))
+	transactionFailures := testutil.ToFloat64(validationFailures.WithLabelValues(TransactionService))
+
+	AccountCreated()
+	TransactionPosted(model.Purchase)
+	TransactionPosted(model.Purchase)
+	TransactionReversed(model.Purchase)
+	Conflict(AccountService)
+	ValidationFailed(TransactionService)
+
+	assert.Equal(t, accounts+1, testutil.ToFloat64(accountsCreated))
+	assert.Equal(t, purchases+2, testutil.ToFloat64(transactionsPosted.WithLabelValues("1")))
+	assert.Equal(t, reversals+1, testutil.ToFloat64(transactionsReversed.WithLabelValues("1")))
+	assert.Equal(t, accountConflicts+1, testutil.ToFloat64(conflicts.WithLabelValues(AccountService)))
+	assert.Equal(t, transactionFailures+1, testutil.ToFloat64(validationFailures.WithLabelValues(TransactionService)))
+}
+
+func TestHandler(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	require.NoError(t, RegisterDB(sqlDB, "metrics_test"))
+	AccountCreated()
+
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(rr.Body)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, string(body), "accounts_transactions_accounts_created_total")
+	assert.Contains(t, string(body), `go_sql_open_connections{db_name="metrics_test"}`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
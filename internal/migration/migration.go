@@ -0,0 +1,379 @@
+// Package migration applies the versioned schema migrations embedded in the
+// binary. Each database has its own directory of numbered scripts, written as
+// NN_name.up.sql and NN_name.down.sql, and the applied versions are tracked in
+// the schema_migrations table.
+package migration
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+//go:embed mysql postgres sqlite
+var scripts embed.FS
+
+// ErrNothingToRollBack is returned by Down when no migration is applied.
+var ErrNothingToRollBack = errors.New("no migration to roll back")
+
+// Migration is one version of the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration records an applied migration.
+type SchemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255;not null"`
+	AppliedAt time.Time
+}
+
+// Status is a migration along with when it was applied, nil when pending.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	now        func() time.Time
+}
+
+type Migrator interface {
+	Up() (int, error)
+	Down() (*Migration, error)
+	Status() ([]Status, error)
+	Version(ctx context.Context) (int, error)
+	Latest() int
+}
+
+// NewMigrator returns a Migrator for the migrations of the database db is
+// connected to.
+func NewMigrator(db *gorm.DB) (Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &migrator{db, migrations, time.Now}, nil
+}
+
+// Load reads the migrations of a database, mysql, postgres or sqlite, ordered
+// by version. Every version needs both an up and a down script.
+func Load(driver string) ([]Migration, error) {
+	entries, err := fs.ReadDir(scripts, driver)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database %q", driver)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		number, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || !found || err != nil || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %q, expected NN_name.up.sql or NN_name.down.sql", entry.Name())
+		}
+
+		content, err := fs.ReadFile(scripts, path.Join(driver, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns how many were
+// applied. Each migration runs in its own database transaction, but MySQL
+// commits schema changes immediately, so a failed MySQL migration may be left
+// half applied and has to be fixed by hand. A schema created by the SQL scripts
+// of the database container, before migrations were tracked, is adopted at the
+// version it reached first.
+func (m *migrator) Up() (int, error) {
+	tracked := m.db.Migrator().HasTable(&SchemaMigration{})
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return 0, err
+	}
+	if !tracked {
+		if err := m.adopt(); err != nil {
+			return 0, err
+		}
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, done := applied[migration.Version]; done {
+			continue
+		}
+
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execute(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: m.now()}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %02d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		log.WithField("version", migration.Version).Info("Applied migration " + migration.Name)
+		count++
+	}
+
+	return count, nil
+}
+
+// preRunnerSchema tells how far the SQL scripts run by the database container,
+// before migrations were tracked, got: entry N holds for a schema at version
+// N+1. The scripts matched the first 15 migrations.
+var preRunnerSchema = []func(gorm.Migrator) bool{
+	hasTable("accounts"),
+	hasColumn("accounts", "balance"),
+	hasColumn("transactions", "balance"),
+	hasIndex("transactions", "idx_transactions_account_date"),
+	hasTable("idempotency_keys"),
+	hasColumn("accounts", "available_credit_limit"),
+	hasTable("operation_types"),
+	hasColumn("transactions", "reversal_of_id"),
+	hasColumn("accounts", "status"),
+	hasColumn("accounts", "document_type"),
+	hasTable("installments"),
+	hasTable("statements"),
+	hasTable("ledger_journals"),
+	hasTable("outbox"),
+	hasTable("webhook_subscriptions"),
+}
+
+// adopt records the migrations a schema created before migrations were tracked
+// already has, so their scripts are not applied twice.
+func (m *migrator) adopt() error {
+	version := 0
+	for _, applied := range preRunnerSchema {
+		if !applied(m.db.Migrator()) {
+			break
+		}
+		version++
+	}
+	if version == 0 {
+		return nil
+	}
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if err := tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: m.now()}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("adopting the untracked schema at version %d: %w", version, err)
+	}
+
+	log.WithField("version", version).Info("Adopted a schema created before migrations were tracked")
+	return nil
+}
+
+func hasTable(table string) func(gorm.Migrator) bool {
+	return func(migrator gorm.Migrator) bool { return migrator.HasTable(table) }
+}
+
+func hasColumn(table, column string) func(gorm.Migrator) bool {
+	return func(migrator gorm.Migrator) bool { return migrator.HasColumn(table, column) }
+}
+
+func hasIndex(table, index string) func(gorm.Migrator) bool {
+	return func(migrator gorm.Migrator) bool { return migrator.HasIndex(table, index) }
+}
+
+// Down rolls back the latest applied migration and returns it.
+func (m *migrator) Down() (*Migration, error) {
+	version, err := m.version(m.db)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, ErrNothingToRollBack
+	}
+
+	index := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= version })
+	if index == len(m.migrations) || m.migrations[index].Version != version {
+		return nil, fmt.Errorf("applied migration %d is unknown to this binary", version)
+	}
+	migration := m.migrations[index]
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := execute(tx, migration.Down); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("migration %02d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	log.WithField("version", migration.Version).Info("Rolled back migration " + migration.Name)
+	return &migration, nil
+}
+
+// Status lists every migration known to the binary and when it was applied.
+func (m *migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if record, done := applied[migration.Version]; done {
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Version returns the latest applied version, 0 when none is. The queries are
+// bound to ctx, so a readiness probe that gives up does not leave them running.
+func (m *migrator) Version(ctx context.Context) (int, error) {
+	return m.version(m.db.WithContext(ctx))
+}
+
+func (m *migrator) version(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		// HasTable hides its errors, so a cancelled lookup would read as an
+		// empty schema.
+		return 0, db.Statement.Context.Err()
+	}
+
+	var version int
+	err := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// Latest returns the version of the newest migration known to the binary.
+func (m *migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *migrator) applied() (map[int]SchemaMigration, error) {
+	applied := make(map[int]SchemaMigration)
+	if !m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var records []SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// execute runs the statements of a script one at a time, since not every
+// driver accepts several statements in a single call.
+func execute(tx *gorm.DB, script string) error {
+	for _, statement := range split(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// split breaks a script into its statements at the semicolons that are outside
+// quotes and comments, dropping the comments.
+func split(script string) []string {
+	var statements []string
+	var statement strings.Builder
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			statement.WriteByte('\n')
+		case c == '\'':
+			end := i + 1
+			for end < len(script) {
+				if script[end] == '\'' {
+					// A doubled quote is an escaped quote inside the string.
+					if end+1 < len(script) && script[end+1] == '\'' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			statement.WriteString(script[i:min(end+1, len(script))])
+			i = end
+		case c == ';':
+			if text := strings.TrimSpace(statement.String()); text != "" {
+				statements = append(statements, text)
+			}
+			statement.Reset()
+		default:
+			statement.WriteByte(c)
+		}
+	}
+
+	if text := strings.TrimSpace(statement.String()); text != "" {
+		statements = append(statements, text)
+	}
+	return statements
+}
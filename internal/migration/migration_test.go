@@ -0,0 +1,250 @@
+package migration
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every connection to :memory: opens a new database, so keep a single one.
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	db.Exec("PRAGMA foreign_keys = ON")
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	for _, driver := range []string{"mysql", "postgres", "sqlite"} {
+		t.Run(driver, func(t *testing.T) {
+			migrations, err := Load(driver)
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, migrations)
+			for i, migration := range migrations {
+				assert.Equal(t, i+1, migration.Version)
+				assert.NotEmpty(t, migration.Up)
+				assert.NotEmpty(t, migration.Down)
+			}
+		})
+	}
+}
+
+func TestLoadSameVersionsForEveryDatabase(t *testing.T) {
+	mysql, _ := Load("mysql")
+	postgres, _ := Load("postgres")
+	sqlite, _ := Load("sqlite")
+
+	names := func(migrations []Migration) []string {
+		result := make([]string, 0, len(migrations))
+		for _, migration := range migrations {
+			result = append(result, migration.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, names(mysql), names(postgres))
+	assert.Equal(t, names(mysql), names(sqlite))
+}
+
+func TestLoadIDsAre64Bit(t *testing.T) {
+	// The models hold ids as int64, so every 32-bit id, and foreign key to one,
+	// created by the earlier scripts is widened by 18_widen_ids.
+	narrowID := regexp.MustCompile(`(?i)\b(id|\w+_id|operation_type)\s+(?:INT|INTEGER|SERIAL)\b`)
+
+	for _, driver := range []string{"mysql", "postgres"} {
+		migrations, _ := Load(driver)
+
+		var widen *Migration
+		for i := range migrations {
+			if migrations[i].Name == "widen_ids" {
+				widen = &migrations[i]
+			}
+		}
+		if !assert.NotNil(t, widen, driver) {
+			continue
+		}
+
+		for _, migration := range migrations[:widen.Version-1] {
+			for _, match := range narrowID.FindAllStringSubmatch(migration.Up, -1) {
+				widened := regexp.MustCompile(`(?i)\b` + match[1] + `\s+(TYPE\s+)?BIGINT\b`)
+				assert.Regexp(t, widened, widen.Up, "%s: %s of %d_%s", driver, match[1], migration.Version, migration.Name)
+			}
+		}
+	}
+}
+
+func TestLoadUnknownDatabase(t *testing.T) {
+	_, err := Load("oracle")
+
+	assert.EqualError(t, err, `no migrations for database "oracle"`)
+}
+
+func TestMigrator_VersionCancelled(t *testing.T) {
+	db := setupTestDB(t)
+
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = migrator.Version(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMigrator_UpDownRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+
+	version, err := migrator.Version(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, version)
+
+	applied, err := migrator.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), applied)
+
+	applied, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Zero(t, applied)
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, status.Name)
+	}
+
+	for version := migrator.Latest(); version > 0; version-- {
+		migration, err := migrator.Down()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, version, migration.Version)
+	}
+
+	_, err = migrator.Down()
+	assert.ErrorIs(t, err, ErrNothingToRollBack)
+
+	var tables []string
+	db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')").Scan(&tables)
+	assert.Empty(t, tables)
+
+	applied, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), applied)
+}
+
+func TestMigrator_DownRollsBackLatest(t *testing.T) {
+	db := setupTestDB(t)
+
+	migrator, _ := NewMigrator(db)
+	_, err := migrator.Up()
+	assert.NoError(t, err)
+
+	migration, err := migrator.Down()
+	assert.NoError(t, err)
+	assert.Equal(t, "backfill_account_balances", migration.Name)
+
+	version, _ := migrator.Version(context.Background())
+	assert.Equal(t, migrator.Latest()-1, version)
+
+	statuses, _ := migrator.Status()
+	assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+	assert.NotNil(t, statuses[len(statuses)-2].AppliedAt)
+}
+
+func TestMigrator_BackfillsAccountBalance(t *testing.T) {
+	db := setupTestDB(t)
+
+	migrator, _ := NewMigrator(db)
+	_, err := migrator.Up()
+	assert.NoError(t, err)
+	// The databases that applied 02_add_account_balance before the backfill
+	// existed hold accounts with transactions and a zero balance.
+	for version, _ := migrator.Version(context.Background()); version > 2; version, _ = migrator.Version(context.Background()) {
+		_, err = migrator.Down()
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	db.Exec("INSERT INTO accounts (id, document_number) VALUES (1, '12345678900'), (2, '98765432100')")
+	db.Exec("INSERT INTO transactions (operation_type, amount, account_id) VALUES (1, -50.25, 1), (4, 20, 1)")
+
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	var balances []float64
+	db.Raw("SELECT balance FROM accounts ORDER BY id").Scan(&balances)
+	assert.Equal(t, []float64{-30.25, 0}, balances)
+}
+
+func TestMigrator_AdoptsUntrackedSchema(t *testing.T) {
+	for _, version := range []int{1, 15} {
+		db := setupTestDB(t)
+
+		migrator, _ := NewMigrator(db)
+		_, err := migrator.Up()
+		assert.NoError(t, err)
+		for current, _ := migrator.Version(context.Background()); current > version; current, _ = migrator.Version(context.Background()) {
+			_, err = migrator.Down()
+			if !assert.NoError(t, err) {
+				return
+			}
+		}
+
+		// The SQL scripts of the database container created the schema without
+		// tracking the versions.
+		assert.NoError(t, db.Migrator().DropTable(&SchemaMigration{}))
+
+		applied, err := migrator.Up()
+		assert.NoError(t, err, "version %d", version)
+		assert.Equal(t, migrator.Latest()-version, applied, "version %d", version)
+
+		statuses, _ := migrator.Status()
+		for _, status := range statuses {
+			assert.NotNil(t, status.AppliedAt, "%d_%s", status.Version, status.Name)
+		}
+	}
+}
+
+func TestMigrator_UpOnEmptyDatabaseAdoptsNothing(t *testing.T) {
+	db := setupTestDB(t)
+
+	migrator, _ := NewMigrator(db)
+	applied, err := migrator.Up()
+
+	assert.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), applied)
+}
+
+func TestSplit(t *testing.T) {
+	script := `-- A comment; with a semicolon
+CREATE TABLE a (id INT);
+
+INSERT INTO a (name) VALUES ('semi;colon'), ('it''s; quoted'); -- trailing
+UPDATE a SET id = 1
+`
+
+	assert.Equal(t, []string{
+		"CREATE TABLE a (id INT)",
+		"INSERT INTO a (name) VALUES ('semi;colon'), ('it''s; quoted')",
+		"UPDATE a SET id = 1",
+	}, split(script))
+}
@@ -0,0 +1 @@
+ALTER TABLE accounts ADD COLUMN balance DECIMAL(10, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- The composite index also served the account_id foreign key, so MySQL dropped
+-- the index it had created for it, and it has to exist again before this one goes.
+CREATE INDEX account_id ON transactions (account_id);
+DROP INDEX idx_transactions_account_date ON transactions;
@@ -0,0 +1,4 @@
+ALTER TABLE transactions DROP FOREIGN KEY fk_transactions_operation_type;
+DROP INDEX fk_transactions_operation_type ON transactions;
+
+DROP TABLE operation_types;
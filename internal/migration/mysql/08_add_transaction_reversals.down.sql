@@ -0,0 +1,6 @@
+ALTER TABLE transactions DROP FOREIGN KEY fk_transactions_reversal_of;
+DROP INDEX fk_transactions_reversal_of ON transactions;
+
+ALTER TABLE transactions DROP COLUMN reversal_of_id;
+ALTER TABLE transactions DROP COLUMN status;
+ALTER TABLE transactions DROP COLUMN reversed_amount;
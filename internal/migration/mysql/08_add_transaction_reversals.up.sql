@@ -0,0 +1,6 @@
+ALTER TABLE transactions ADD COLUMN reversed_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'posted';
+ALTER TABLE transactions ADD COLUMN reversal_of_id INT NULL;
+
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_reversal_of
+    FOREIGN KEY (reversal_of_id) REFERENCES transactions(id);
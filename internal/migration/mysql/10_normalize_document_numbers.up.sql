@@ -0,0 +1,11 @@
+ALTER TABLE accounts ADD COLUMN document_type VARCHAR(4) NULL;
+
+-- Document numbers are stored without punctuation, so formatted copies of the
+-- same CPF or CNPJ collide on idx_document_number. Accounts that duplicate each
+-- other once normalized make this update fail and must be merged first.
+UPDATE accounts SET document_number = UPPER(REGEXP_REPLACE(document_number, '[.\\-/ ]', ''));
+
+UPDATE accounts SET document_type = CASE CHAR_LENGTH(document_number)
+    WHEN 11 THEN 'cpf'
+    WHEN 14 THEN 'cnpj'
+END;
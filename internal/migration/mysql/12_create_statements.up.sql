@@ -0,0 +1,20 @@
+ALTER TABLE accounts ADD COLUMN closing_day INT NOT NULL DEFAULT 25;
+ALTER TABLE accounts ADD COLUMN due_day INT NOT NULL DEFAULT 5;
+
+CREATE TABLE IF NOT EXISTS statements (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    account_id INT NOT NULL,
+    period VARCHAR(7) NOT NULL,
+    period_start DATETIME NOT NULL,
+    period_end DATETIME NOT NULL,
+    due_date DATETIME NOT NULL,
+    opening_balance DECIMAL(10, 2) NOT NULL,
+    total_debits DECIMAL(10, 2) NOT NULL,
+    total_credits DECIMAL(10, 2) NOT NULL,
+    closing_balance DECIMAL(10, 2) NOT NULL,
+    minimum_payment DECIMAL(10, 2) NOT NULL,
+    closed_at DATETIME,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_statements_account_period ON statements (account_id, period);
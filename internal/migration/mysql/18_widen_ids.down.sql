@@ -0,0 +1,38 @@
+ALTER TABLE webhook_deliveries DROP FOREIGN KEY fk_webhook_deliveries_outbox_message;
+-- MySQL created an index for the foreign key, as no other index starts with
+-- outbox_message_id.
+DROP INDEX fk_webhook_deliveries_outbox_message ON webhook_deliveries;
+ALTER TABLE outbox DROP FOREIGN KEY fk_outbox_account;
+
+SET FOREIGN_KEY_CHECKS = 0;
+
+ALTER TABLE accounts MODIFY id INT AUTO_INCREMENT;
+ALTER TABLE operation_types MODIFY id INT AUTO_INCREMENT;
+ALTER TABLE transactions
+    MODIFY id INT AUTO_INCREMENT,
+    MODIFY operation_type INT NOT NULL,
+    MODIFY account_id INT,
+    MODIFY reversal_of_id INT NULL;
+ALTER TABLE installments
+    MODIFY id INT AUTO_INCREMENT,
+    MODIFY transaction_id INT NOT NULL;
+ALTER TABLE statements
+    MODIFY id INT AUTO_INCREMENT,
+    MODIFY account_id INT NOT NULL;
+ALTER TABLE ledger_journals
+    MODIFY id INT AUTO_INCREMENT,
+    MODIFY transaction_id INT NOT NULL;
+ALTER TABLE ledger_entries
+    MODIFY id INT AUTO_INCREMENT,
+    MODIFY journal_id INT NOT NULL;
+ALTER TABLE outbox
+    MODIFY id INT AUTO_INCREMENT,
+    MODIFY account_id INT NOT NULL;
+ALTER TABLE webhook_subscriptions MODIFY id INT AUTO_INCREMENT;
+ALTER TABLE webhook_deliveries
+    MODIFY id INT AUTO_INCREMENT,
+    MODIFY subscription_id INT NOT NULL,
+    MODIFY outbox_message_id INT NOT NULL;
+ALTER TABLE api_clients MODIFY id INT AUTO_INCREMENT;
+
+SET FOREIGN_KEY_CHECKS = 1;
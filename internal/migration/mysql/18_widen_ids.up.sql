@@ -0,0 +1,40 @@
+-- The models hold ids as int64, but the tables were created with 32-bit ids.
+-- MySQL refuses to change the type of a column on either side of a foreign key
+-- while the keys are checked, and checks every key again once they are back on.
+SET FOREIGN_KEY_CHECKS = 0;
+
+ALTER TABLE accounts MODIFY id BIGINT AUTO_INCREMENT;
+ALTER TABLE operation_types MODIFY id BIGINT AUTO_INCREMENT;
+ALTER TABLE transactions
+    MODIFY id BIGINT AUTO_INCREMENT,
+    MODIFY operation_type BIGINT NOT NULL,
+    MODIFY account_id BIGINT,
+    MODIFY reversal_of_id BIGINT NULL;
+ALTER TABLE installments
+    MODIFY id BIGINT AUTO_INCREMENT,
+    MODIFY transaction_id BIGINT NOT NULL;
+ALTER TABLE statements
+    MODIFY id BIGINT AUTO_INCREMENT,
+    MODIFY account_id BIGINT NOT NULL;
+ALTER TABLE ledger_journals
+    MODIFY id BIGINT AUTO_INCREMENT,
+    MODIFY transaction_id BIGINT NOT NULL;
+ALTER TABLE ledger_entries
+    MODIFY id BIGINT AUTO_INCREMENT,
+    MODIFY journal_id BIGINT NOT NULL;
+ALTER TABLE outbox
+    MODIFY id BIGINT AUTO_INCREMENT,
+    MODIFY account_id BIGINT NOT NULL;
+ALTER TABLE webhook_subscriptions MODIFY id BIGINT AUTO_INCREMENT;
+ALTER TABLE webhook_deliveries
+    MODIFY id BIGINT AUTO_INCREMENT,
+    MODIFY subscription_id BIGINT NOT NULL,
+    MODIFY outbox_message_id BIGINT NOT NULL;
+ALTER TABLE api_clients MODIFY id BIGINT AUTO_INCREMENT;
+
+SET FOREIGN_KEY_CHECKS = 1;
+
+ALTER TABLE outbox ADD CONSTRAINT fk_outbox_account
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE;
+ALTER TABLE webhook_deliveries ADD CONSTRAINT fk_webhook_deliveries_outbox_message
+    FOREIGN KEY (outbox_message_id) REFERENCES outbox(id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+-- 02_add_account_balance started every account at 0, so accounts opened before
+-- balances were tracked start out with the sum of their transactions. Later
+-- postings kept the balance at that sum, so it is safe to compute it again.
+UPDATE accounts SET balance = (SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE transactions.account_id = accounts.id);
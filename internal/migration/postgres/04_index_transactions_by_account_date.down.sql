@@ -0,0 +1 @@
+DROP INDEX idx_transactions_account_date;
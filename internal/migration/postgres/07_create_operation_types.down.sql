@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP CONSTRAINT fk_transactions_operation_type;
+
+DROP TABLE operation_types;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS operation_types (
+    id SERIAL PRIMARY KEY,
+    description VARCHAR(255) NOT NULL,
+    sign VARCHAR(6) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CHECK (sign IN ('debit', 'credit'))
+);
+
+INSERT INTO operation_types (id, description, sign, active) VALUES
+    (1, 'Normal Purchase', 'debit', TRUE),
+    (2, 'Purchase with installments', 'debit', TRUE),
+    (3, 'Withdrawal', 'debit', TRUE),
+    (4, 'Credit Voucher', 'credit', TRUE);
+
+-- The built-in operation types take explicit ids, so the sequence has to skip them.
+SELECT setval('operation_types_id_seq', (SELECT MAX(id) FROM operation_types));
+
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_operation_type
+    FOREIGN KEY (operation_type) REFERENCES operation_types(id);
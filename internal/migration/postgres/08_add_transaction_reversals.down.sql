@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN reversal_of_id;
+ALTER TABLE transactions DROP COLUMN status;
+ALTER TABLE transactions DROP COLUMN reversed_amount;
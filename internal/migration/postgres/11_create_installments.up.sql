@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS installments (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    number INT NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    due_date TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_installments_transaction_number ON installments (transaction_id, number);
@@ -0,0 +1,4 @@
+DROP TABLE statements;
+
+ALTER TABLE accounts DROP COLUMN due_day;
+ALTER TABLE accounts DROP COLUMN closing_day;
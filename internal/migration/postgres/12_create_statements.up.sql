@@ -0,0 +1,20 @@
+ALTER TABLE accounts ADD COLUMN closing_day INT NOT NULL DEFAULT 25;
+ALTER TABLE accounts ADD COLUMN due_day INT NOT NULL DEFAULT 5;
+
+CREATE TABLE IF NOT EXISTS statements (
+    id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    period VARCHAR(7) NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    due_date TIMESTAMPTZ NOT NULL,
+    opening_balance DECIMAL(10, 2) NOT NULL,
+    total_debits DECIMAL(10, 2) NOT NULL,
+    total_credits DECIMAL(10, 2) NOT NULL,
+    closing_balance DECIMAL(10, 2) NOT NULL,
+    minimum_payment DECIMAL(10, 2) NOT NULL,
+    closed_at TIMESTAMPTZ,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_statements_account_period ON statements (account_id, period);
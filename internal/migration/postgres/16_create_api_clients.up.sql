@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_clients (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    rotated_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX idx_api_clients_key_hash ON api_clients (key_hash);
@@ -0,0 +1,43 @@
+ALTER TABLE webhook_deliveries DROP CONSTRAINT fk_webhook_deliveries_outbox_message;
+ALTER TABLE outbox DROP CONSTRAINT fk_outbox_account;
+
+ALTER SEQUENCE accounts_id_seq AS INTEGER;
+ALTER SEQUENCE operation_types_id_seq AS INTEGER;
+ALTER SEQUENCE transactions_id_seq AS INTEGER;
+ALTER SEQUENCE installments_id_seq AS INTEGER;
+ALTER SEQUENCE statements_id_seq AS INTEGER;
+ALTER SEQUENCE ledger_journals_id_seq AS INTEGER;
+ALTER SEQUENCE ledger_entries_id_seq AS INTEGER;
+ALTER SEQUENCE outbox_id_seq AS INTEGER;
+ALTER SEQUENCE webhook_subscriptions_id_seq AS INTEGER;
+ALTER SEQUENCE webhook_deliveries_id_seq AS INTEGER;
+ALTER SEQUENCE api_clients_id_seq AS INTEGER;
+
+ALTER TABLE accounts ALTER COLUMN id TYPE INT;
+ALTER TABLE operation_types ALTER COLUMN id TYPE INT;
+ALTER TABLE transactions
+    ALTER COLUMN id TYPE INT,
+    ALTER COLUMN operation_type TYPE INT,
+    ALTER COLUMN account_id TYPE INT,
+    ALTER COLUMN reversal_of_id TYPE INT;
+ALTER TABLE installments
+    ALTER COLUMN id TYPE INT,
+    ALTER COLUMN transaction_id TYPE INT;
+ALTER TABLE statements
+    ALTER COLUMN id TYPE INT,
+    ALTER COLUMN account_id TYPE INT;
+ALTER TABLE ledger_journals
+    ALTER COLUMN id TYPE INT,
+    ALTER COLUMN transaction_id TYPE INT;
+ALTER TABLE ledger_entries
+    ALTER COLUMN id TYPE INT,
+    ALTER COLUMN journal_id TYPE INT;
+ALTER TABLE outbox
+    ALTER COLUMN id TYPE INT,
+    ALTER COLUMN account_id TYPE INT;
+ALTER TABLE webhook_subscriptions ALTER COLUMN id TYPE INT;
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN id TYPE INT,
+    ALTER COLUMN subscription_id TYPE INT,
+    ALTER COLUMN outbox_message_id TYPE INT;
+ALTER TABLE api_clients ALTER COLUMN id TYPE INT;
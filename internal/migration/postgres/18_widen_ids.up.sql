@@ -0,0 +1,48 @@
+-- The models hold ids as int64, but the tables were created with 32-bit ids.
+-- The foreign keys to a widened column are rebuilt along with it.
+ALTER TABLE accounts ALTER COLUMN id TYPE BIGINT;
+ALTER TABLE operation_types ALTER COLUMN id TYPE BIGINT;
+ALTER TABLE transactions
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN operation_type TYPE BIGINT,
+    ALTER COLUMN account_id TYPE BIGINT,
+    ALTER COLUMN reversal_of_id TYPE BIGINT;
+ALTER TABLE installments
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN transaction_id TYPE BIGINT;
+ALTER TABLE statements
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN account_id TYPE BIGINT;
+ALTER TABLE ledger_journals
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN transaction_id TYPE BIGINT;
+ALTER TABLE ledger_entries
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN journal_id TYPE BIGINT;
+ALTER TABLE outbox
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN account_id TYPE BIGINT;
+ALTER TABLE webhook_subscriptions ALTER COLUMN id TYPE BIGINT;
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN subscription_id TYPE BIGINT,
+    ALTER COLUMN outbox_message_id TYPE BIGINT;
+ALTER TABLE api_clients ALTER COLUMN id TYPE BIGINT;
+
+-- SERIAL sequences are integer sequences and stop at the 32-bit maximum.
+ALTER SEQUENCE accounts_id_seq AS BIGINT;
+ALTER SEQUENCE operation_types_id_seq AS BIGINT;
+ALTER SEQUENCE transactions_id_seq AS BIGINT;
+ALTER SEQUENCE installments_id_seq AS BIGINT;
+ALTER SEQUENCE statements_id_seq AS BIGINT;
+ALTER SEQUENCE ledger_journals_id_seq AS BIGINT;
+ALTER SEQUENCE ledger_entries_id_seq AS BIGINT;
+ALTER SEQUENCE outbox_id_seq AS BIGINT;
+ALTER SEQUENCE webhook_subscriptions_id_seq AS BIGINT;
+ALTER SEQUENCE webhook_deliveries_id_seq AS BIGINT;
+ALTER SEQUENCE api_clients_id_seq AS BIGINT;
+
+ALTER TABLE outbox ADD CONSTRAINT fk_outbox_account
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE;
+ALTER TABLE webhook_deliveries ADD CONSTRAINT fk_webhook_deliveries_outbox_message
+    FOREIGN KEY (outbox_message_id) REFERENCES outbox(id) ON DELETE CASCADE;
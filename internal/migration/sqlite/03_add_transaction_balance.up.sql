@@ -0,0 +1,4 @@
+ALTER TABLE transactions ADD COLUMN balance DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Transactions posted before settlement existed start out undischarged.
+UPDATE transactions SET balance = amount;
@@ -0,0 +1 @@
+ALTER TABLE accounts ADD COLUMN available_credit_limit DECIMAL(10, 2) NULL;
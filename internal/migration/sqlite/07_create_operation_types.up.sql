@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS operation_types (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    description VARCHAR(255) NOT NULL,
+    sign VARCHAR(6) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CHECK (sign IN ('debit', 'credit'))
+);
+
+INSERT INTO operation_types (id, description, sign, active) VALUES
+    (1, 'Normal Purchase', 'debit', TRUE),
+    (2, 'Purchase with installments', 'debit', TRUE),
+    (3, 'Withdrawal', 'debit', TRUE),
+    (4, 'Credit Voucher', 'credit', TRUE);
+
+-- SQLite cannot add a foreign key to an existing table, so transactions.operation_type
+-- is not checked against operation_types here.
@@ -0,0 +1,5 @@
+ALTER TABLE transactions ADD COLUMN reversed_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'posted';
+-- SQLite cannot drop a column that is part of a foreign key, so reversal_of_id
+-- is not checked against transactions here, which keeps this migration reversible.
+ALTER TABLE transactions ADD COLUMN reversal_of_id INT NULL;
@@ -0,0 +1,2 @@
+-- Document numbers stay normalized, since the punctuation they had is lost.
+ALTER TABLE accounts DROP COLUMN document_type;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS ledger_journals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INT NOT NULL,
+    posted_at DATETIME NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_ledger_journals_transaction_id ON ledger_journals (transaction_id);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    journal_id INT NOT NULL,
+    ledger_account VARCHAR(64) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    FOREIGN KEY (journal_id) REFERENCES ledger_journals(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ledger_entries_journal_id ON ledger_entries (journal_id);
+CREATE INDEX idx_ledger_entries_ledger_account ON ledger_entries (ledger_account);
+
+-- Backfill the journals of the transactions posted before the ledger existed.
+INSERT INTO ledger_journals (transaction_id, posted_at)
+SELECT id, COALESCE(transaction_date, CURRENT_TIMESTAMP) FROM transactions;
+
+INSERT INTO ledger_entries (journal_id, ledger_account, amount)
+SELECT j.id, 'customer:' || t.account_id, -t.amount
+FROM ledger_journals j JOIN transactions t ON t.id = j.transaction_id;
+
+INSERT INTO ledger_entries (journal_id, ledger_account, amount)
+SELECT j.id,
+    CASE
+        WHEN t.operation_type IN (1, 2) THEN 'merchant_settlement'
+        WHEN t.operation_type IN (3, 4) THEN 'cash'
+        WHEN (t.amount < 0) <> (t.reversal_of_id IS NOT NULL) THEN 'fees'
+        ELSE 'merchant_settlement'
+    END,
+    t.amount
+FROM ledger_journals j JOIN transactions t ON t.id = j.transaction_id;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    published_at DATETIME NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(1024)
+);
+
+CREATE INDEX idx_outbox_account_id ON outbox (account_id);
+CREATE INDEX idx_outbox_published_at ON outbox (published_at);
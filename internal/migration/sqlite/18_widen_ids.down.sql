@@ -0,0 +1 @@
+-- Nothing to undo, see 18_widen_ids.up.sql.
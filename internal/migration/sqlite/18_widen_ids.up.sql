@@ -0,0 +1,2 @@
+-- SQLite ids are INTEGER, which already holds 64-bit values, and SQLite cannot
+-- add foreign keys to existing tables, so there is nothing to change here.
@@ -0,0 +1 @@
+-- Nothing to undo, the backfilled balances are the correct ones.
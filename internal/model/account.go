@@ -1,7 +1,49 @@
 package model
 
+// AccountStatus is the lifecycle state of an account. Only active accounts
+// accept postings, and closed is final.
+type AccountStatus string
+
+const (
+	AccountActive  AccountStatus = "active"
+	AccountBlocked AccountStatus = "blocked"
+	AccountClosed  AccountStatus = "closed"
+)
+
+// CanTransitionTo reports whether an account in status s may move to target.
+// Active and blocked accounts swap freely and either may be closed, while a
+// closed account stays closed. Keeping the current status is always allowed.
+func (s AccountStatus) CanTransitionTo(target AccountStatus) bool {
+	if s == target {
+		return true
+	}
+
+	switch target {
+	case AccountActive:
+		return s == AccountBlocked
+	case AccountBlocked:
+		return s == AccountActive
+	case AccountClosed:
+		return true
+	}
+	return false
+}
+
+// Account holds the running balance of its transactions. DocumentNumber is
+// stored normalized, without punctuation. A nil AvailableCreditLimit means the
+// account has no credit limit. ClosingDay and DueDay set its billing cycle.
 type Account struct {
-	ID             int64         `gorm:"primaryKey"`
-	DocumentNumber string        `gorm:"unique;not null"`
-	Transactions   []Transaction `gorm:"foreignKey:AccountID;references:ID"`
+	ID                   int64        `gorm:"primaryKey"`
+	DocumentNumber       string       `gorm:"unique;not null"`
+	DocumentType         DocumentType `gorm:"size:4"`
+	Balance              Money        `gorm:"not null;default:0"`
+	AvailableCreditLimit *Money
+	Status               AccountStatus `gorm:"size:20;not null;default:active"`
+	ClosingDay           int           `gorm:"not null;default:25"`
+	DueDay               int           `gorm:"not null;default:5"`
+	Transactions         []Transaction `gorm:"foreignKey:AccountID;references:ID"`
+}
+
+func (a *Account) IsActive() bool {
+	return a.Status == AccountActive
 }
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     AccountStatus
+		to       AccountStatus
+		expected bool
+	}{
+		{AccountActive, AccountBlocked, true},
+		{AccountBlocked, AccountActive, true},
+		{AccountActive, AccountClosed, true},
+		{AccountBlocked, AccountClosed, true},
+		{AccountActive, AccountActive, true},
+		{AccountClosed, AccountClosed, true},
+		{AccountClosed, AccountActive, false},
+		{AccountClosed, AccountBlocked, false},
+		{AccountActive, AccountStatus("frozen"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scope is a permission granted to an API client. Every route requires one.
+type Scope string
+
+const (
+	ScopeAccountsRead      Scope = "accounts:read"
+	ScopeAccountsWrite     Scope = "accounts:write"
+	ScopeTransactionsWrite Scope = "transactions:write"
+	// ScopeAdmin grants the administration routes and every other scope.
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes is a list of scopes stored as a comma separated column.
+type Scopes []Scope
+
+func (s Scopes) Value() (driver.Value, error) {
+	values := make([]string, len(s))
+	for i, scope := range s {
+		values[i] = string(scope)
+	}
+	return strings.Join(values, ","), nil
+}
+
+func (s *Scopes) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", value)
+	}
+
+	*s = nil
+	for _, scope := range strings.Split(text, ",") {
+		if scope != "" {
+			*s = append(*s, Scope(scope))
+		}
+	}
+	return nil
+}
+
+// APIClient is a caller of the API, authenticated by its key. Only the SHA-256
+// hash of the key is stored, along with its first characters so the key can
+// be recognized.
+type APIClient struct {
+	ID        int64  `gorm:"primaryKey"`
+	Name      string `gorm:"size:255;not null"`
+	KeyPrefix string `gorm:"size:16;not null"`
+	KeyHash   string `gorm:"size:64;not null;uniqueIndex"`
+	Scopes    Scopes `gorm:"size:255;not null;default:''"`
+	Active    bool   `gorm:"not null"`
+	CreatedAt time.Time
+	RotatedAt *time.Time
+}
+
+// HasScope reports whether the client was granted the scope, which admin
+// clients are for every scope.
+func (c *APIClient) HasScope(scope Scope) bool {
+	return slices.Contains(c.Scopes, scope) || slices.Contains(c.Scopes, ScopeAdmin)
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+// DocumentType tells which kind of Brazilian taxpayer number identifies an
+// account holder.
+type DocumentType string
+
+const (
+	CPF  DocumentType = "cpf"
+	CNPJ DocumentType = "cnpj"
+
+	cpfLength  = 11
+	cnpjLength = 14
+)
+
+var ErrInvalidDocumentNumber = errors.New("invalid document number")
+
+// NormalizeDocumentNumber strips the punctuation and spaces used when writing
+// CPFs and CNPJs, such as "123.456.789-09" or "12.345.678/0001-95", and upper
+// cases letters of alphanumeric CNPJs.
+func NormalizeDocumentNumber(documentNumber string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '-', '/', ' ':
+			return -1
+		}
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}, documentNumber)
+}
+
+// ParseDocumentNumber normalizes the document number and checks it is a CPF
+// or CNPJ with valid check digits, returning the normalized form and its type.
+func ParseDocumentNumber(documentNumber string) (string, DocumentType, error) {
+	normalized := NormalizeDocumentNumber(documentNumber)
+
+	switch {
+	case len(normalized) == cpfLength && isValidCPF(normalized):
+		return normalized, CPF, nil
+	case len(normalized) == cnpjLength && isValidCNPJ(normalized):
+		return normalized, CNPJ, nil
+	}
+	return "", "", ErrInvalidDocumentNumber
+}
+
+func isValidCPF(cpf string) bool {
+	if !isDigits(cpf) || isRepeated(cpf) {
+		return false
+	}
+
+	values := documentValues(cpf)
+	return values[9] == cpfCheckDigit(values[:9]) && values[10] == cpfCheckDigit(values[:10])
+}
+
+func cpfCheckDigit(values []int) int {
+	sum := 0
+	for i, value := range values {
+		sum += value * (len(values) + 1 - i)
+	}
+	digit := sum * 10 % 11
+	if digit == 10 {
+		return 0
+	}
+	return digit
+}
+
+// isValidCNPJ accepts both numeric CNPJs and the alphanumeric ones, whose
+// first 12 characters may be letters. Check digits are always numeric.
+func isValidCNPJ(cnpj string) bool {
+	for _, r := range cnpj[:12] {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	if !isDigits(cnpj[12:]) || isRepeated(cnpj) {
+		return false
+	}
+
+	values := documentValues(cnpj)
+	return values[12] == cnpjCheckDigit(values[:12]) && values[13] == cnpjCheckDigit(values[:13])
+}
+
+func cnpjCheckDigit(values []int) int {
+	sum := 0
+	weight := len(values) - 7
+	for _, value := range values {
+		sum += value * weight
+		weight--
+		if weight < 2 {
+			weight = 9
+		}
+	}
+	remainder := sum % 11
+	if remainder < 2 {
+		return 0
+	}
+	return 11 - remainder
+}
+
+// documentValues maps each character to the value used in check digit sums,
+// its ASCII code minus the one of '0', which covers digits and letters alike.
+func documentValues(document string) []int {
+	values := make([]int, len(document))
+	for i, r := range document {
+		values[i] = int(r - '0')
+	}
+	return values
+}
+
+func isRepeated(document string) bool {
+	return strings.Count(document, document[:1]) == len(document)
+}
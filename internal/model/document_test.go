@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument_ParseDocumentNumber(t *testing.T) {
+	valid := map[string]struct {
+		normalized   string
+		documentType DocumentType
+	}{
+		"529.982.247-25":     {"52998224725", CPF},
+		"52998224725":        {"52998224725", CPF},
+		" 111.444.777-35 ":   {"11144477735", CPF},
+		"11.222.333/0001-81": {"11222333000181", CNPJ},
+		"11222333000181":     {"11222333000181", CNPJ},
+		"12.abc.345/01de-35": {"12ABC34501DE35", CNPJ},
+	}
+	for documentNumber, expected := range valid {
+		normalized, documentType, err := ParseDocumentNumber(documentNumber)
+		assert.NoError(t, err, documentNumber)
+		assert.Equal(t, expected.normalized, normalized, documentNumber)
+		assert.Equal(t, expected.documentType, documentType, documentNumber)
+	}
+
+	invalid := []string{
+		"",
+		"abc",
+		"52998224724",
+		"529982247",
+		"11111111111",
+		"11222333000182",
+		"00000000000000",
+		"1122233300018A",
+		"5299822472A",
+		"529_982_247_25",
+	}
+	for _, documentNumber := range invalid {
+		_, _, err := ParseDocumentNumber(documentNumber)
+		assert.ErrorIs(t, err, ErrInvalidDocumentNumber, documentNumber)
+	}
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type EventType string
+
+const (
+	AccountCreatedEvent    EventType = "account.created"
+	TransactionPostedEvent EventType = "transaction.posted"
+)
+
+// Event is a domain event published to downstream systems. Events are keyed by
+// account, and the events of an account are delivered in the order they
+// happened.
+type Event interface {
+	EventType() EventType
+	EventAccountID() int64
+}
+
+type AccountCreated struct {
+	AccountID            int64         `json:"account_id"`
+	DocumentNumber       string        `json:"document_number"`
+	DocumentType         DocumentType  `json:"document_type"`
+	Status               AccountStatus `json:"status"`
+	AvailableCreditLimit *Money        `json:"available_credit_limit"`
+	ClosingDay           int           `json:"closing_day"`
+	DueDay               int           `json:"due_day"`
+}
+
+func NewAccountCreated(account *Account) *AccountCreated {
+	return &AccountCreated{
+		AccountID:            account.ID,
+		DocumentNumber:       account.DocumentNumber,
+		DocumentType:         account.DocumentType,
+		Status:               account.Status,
+		AvailableCreditLimit: account.AvailableCreditLimit,
+		ClosingDay:           account.ClosingDay,
+		DueDay:               account.DueDay,
+	}
+}
+
+func (e *AccountCreated) EventType() EventType {
+	return AccountCreatedEvent
+}
+
+func (e *AccountCreated) EventAccountID() int64 {
+	return e.AccountID
+}
+
+// TransactionPosted is published for every posting, reversals included.
+type TransactionPosted struct {
+	TransactionID           int64         `json:"transaction_id"`
+	AccountID               int64         `json:"account_id"`
+	OperationTypeID         OperationType `json:"operation_type_id"`
+	Amount                  Money         `json:"amount"`
+	ReversalOfTransactionID *int64        `json:"reversal_of_transaction_id,omitempty"`
+	TransactionDate         time.Time     `json:"transaction_date"`
+}
+
+func NewTransactionPosted(transaction *Transaction) *TransactionPosted {
+	return &TransactionPosted{
+		TransactionID:           transaction.ID,
+		AccountID:               transaction.AccountID,
+		OperationTypeID:         transaction.OperationType,
+		Amount:                  transaction.Amount,
+		ReversalOfTransactionID: transaction.ReversalOfID,
+		TransactionDate:         transaction.TransactionDate,
+	}
+}
+
+func (e *TransactionPosted) EventType() EventType {
+	return TransactionPostedEvent
+}
+
+func (e *TransactionPosted) EventAccountID() int64 {
+	return e.AccountID
+}
+
+// OutboxMessage is an event waiting in the outbox. It is written in the same
+// database transaction as the change it describes and stays pending until a
+// publisher accepts it, or until it runs out of attempts and is dead lettered.
+type OutboxMessage struct {
+	ID             int64      `gorm:"primaryKey"`
+	AccountID      int64      `gorm:"not null;index"`
+	EventType      EventType  `gorm:"size:64;not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	OccurredAt     time.Time  `gorm:"not null"`
+	PublishedAt    *time.Time `gorm:"index"`
+	Attempts       int        `gorm:"not null;default:0"`
+	LastError      string     `gorm:"size:1024"`
+	DeadLetteredAt *time.Time
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
+
+func NewOutboxMessage(event Event, occurredAt time.Time) (*OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		AccountID:  event.EventAccountID(),
+		EventType:  event.EventType(),
+		Payload:    string(payload),
+		OccurredAt: occurredAt,
+	}, nil
+}
+
+// Event decodes the typed event carried by the message.
+func (m *OutboxMessage) Event() (Event, error) {
+	var event Event
+	switch m.EventType {
+	case AccountCreatedEvent:
+		event = &AccountCreated{}
+	case TransactionPostedEvent:
+		event = &TransactionPosted{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", m.EventType)
+	}
+
+	if err := json.Unmarshal([]byte(m.Payload), event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
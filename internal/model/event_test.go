@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxMessage_RoundTrip(t *testing.T) {
+	reversalOf := int64(3)
+	transaction := &Transaction{
+		ID:              4,
+		AccountID:       1,
+		OperationType:   Purchase,
+		Amount:          MustParseMoney("25.00"),
+		ReversalOfID:    &reversalOf,
+		TransactionDate: time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC),
+	}
+
+	message, err := NewOutboxMessage(NewTransactionPosted(transaction), transaction.TransactionDate)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), message.AccountID)
+	assert.Equal(t, TransactionPostedEvent, message.EventType)
+	assert.JSONEq(t, `{"transaction_id":4,"account_id":1,"operation_type_id":1,"amount":25.00,"reversal_of_transaction_id":3,"transaction_date":"2024-09-02T10:00:00Z"}`, message.Payload)
+
+	event, err := message.Event()
+
+	assert.NoError(t, err)
+	assert.Equal(t, NewTransactionPosted(transaction), event)
+}
+
+func TestOutboxMessage_EventUnknownType(t *testing.T) {
+	message := &OutboxMessage{EventType: "account.deleted", Payload: "{}"}
+
+	_, err := message.Event()
+
+	assert.EqualError(t, err, `unknown event type "account.deleted"`)
+}
@@ -0,0 +1,22 @@
+package model
+
+// HealthCheck is the outcome of checking one dependency. Error is empty when
+// the dependency is healthy.
+type HealthCheck struct {
+	Name  string
+	Error string
+}
+
+// Readiness tells whether the application can serve traffic.
+type Readiness struct {
+	Checks []HealthCheck
+}
+
+func (r *Readiness) Ready() bool {
+	for _, check := range r.Checks {
+		if check.Error != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// IdempotencyKey records the first response produced for a client supplied
+// Idempotency-Key, so retries of the same request can be answered with it.
+type IdempotencyKey struct {
+	Key          string `gorm:"column:idempotency_key;primaryKey;size:255"`
+	Scope        string `gorm:"primaryKey;size:255"`
+	RequestHash  string `gorm:"size:64;not null"`
+	Completed    bool   `gorm:"not null;default:false"`
+	StatusCode   int
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"index;not null"`
+}
+
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+// IsAbandoned reports whether the key is still in progress after the lease its
+// request had to finish in.
+func (k *IdempotencyKey) IsAbandoned(now time.Time, lease time.Duration) bool {
+	return !k.Completed && !now.Before(k.CreatedAt.Add(lease))
+}
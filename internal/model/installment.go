@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+// MaxInstallments is the longest installment plan a purchase can be split in.
+const MaxInstallments = 48
+
+type InstallmentStatus string
+
+const (
+	InstallmentScheduled InstallmentStatus = "scheduled"
+	InstallmentOverdue   InstallmentStatus = "overdue"
+	InstallmentPaid      InstallmentStatus = "paid"
+	InstallmentCancelled InstallmentStatus = "cancelled"
+)
+
+// Installment is one monthly share of an installment purchase. Amount is
+// unsigned, and Status is not stored but derived by NewInstallmentPlan.
+type Installment struct {
+	ID            int64             `gorm:"primaryKey"`
+	TransactionID int64             `gorm:"not null;uniqueIndex:idx_installments_transaction_number"`
+	Number        int               `gorm:"not null;uniqueIndex:idx_installments_transaction_number"`
+	Amount        Money             `gorm:"not null"`
+	DueDate       time.Time         `gorm:"not null"`
+	Status        InstallmentStatus `gorm:"-"`
+}
+
+// InstallmentPlan is an installment purchase along with its installments,
+// ordered by number.
+type InstallmentPlan struct {
+	Transaction  Transaction
+	Installments []Installment
+}
+
+// NewInstallments splits the transaction amount in count installments due
+// monthly from the transaction date. The cents that do not divide evenly are
+// put on the first installment.
+func NewInstallments(transaction *Transaction, count int) []Installment {
+	total := transaction.Amount.Abs()
+	share := total / Money(count)
+
+	installments := make([]Installment, count)
+	for i := range installments {
+		installments[i] = Installment{
+			TransactionID: transaction.ID,
+			Number:        i + 1,
+			Amount:        share,
+			DueDate:       addMonths(transaction.TransactionDate, i+1),
+		}
+	}
+	installments[0].Amount += total - share*Money(count)
+
+	return installments
+}
+
+// NewInstallmentPlan derives the status of each installment at now. What was
+// reversed cancels installments from the last one backwards, and what was
+// settled pays them from the first one onwards.
+func NewInstallmentPlan(transaction *Transaction, installments []Installment, now time.Time) *InstallmentPlan {
+	active := transaction.Amount.Abs() - transaction.ReversedAmount
+	paid := max(active-transaction.Balance.Abs(), 0)
+
+	var cumulative Money
+	for i := range installments {
+		installment := &installments[i]
+		start := cumulative
+		cumulative += installment.Amount
+
+		switch {
+		case start >= active:
+			installment.Status = InstallmentCancelled
+		case min(cumulative, active) <= paid:
+			installment.Status = InstallmentPaid
+		case !installment.DueDate.After(now):
+			installment.Status = InstallmentOverdue
+		default:
+			installment.Status = InstallmentScheduled
+		}
+	}
+
+	return &InstallmentPlan{Transaction: *transaction, Installments: installments}
+}
+
+// addMonths moves t the given number of months ahead, keeping its day of the
+// month unless the target month is shorter, in which case its last day is used.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	lastDay := time.Date(year, month+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	hour, minute, second := t.Clock()
+	return time.Date(year, month+time.Month(months), min(day, lastDay), hour, minute, second, t.Nanosecond(), t.Location())
+}
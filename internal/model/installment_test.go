@@ -0,0 +1,92 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallment_NewInstallments(t *testing.T) {
+	transaction := &Transaction{
+		ID:              1,
+		Amount:          MustParseMoney("-100.00"),
+		TransactionDate: time.Date(2024, time.January, 31, 10, 30, 0, 0, time.UTC),
+	}
+
+	installments := NewInstallments(transaction, 3)
+
+	assert.Len(t, installments, 3)
+	assert.Equal(t, MustParseMoney("33.34"), installments[0].Amount)
+	assert.Equal(t, MustParseMoney("33.33"), installments[1].Amount)
+	assert.Equal(t, MustParseMoney("33.33"), installments[2].Amount)
+	assert.Equal(t, time.Date(2024, time.February, 29, 10, 30, 0, 0, time.UTC), installments[0].DueDate)
+	assert.Equal(t, time.Date(2024, time.March, 31, 10, 30, 0, 0, time.UTC), installments[1].DueDate)
+	assert.Equal(t, time.Date(2024, time.April, 30, 10, 30, 0, 0, time.UTC), installments[2].DueDate)
+
+	for i, installment := range installments {
+		assert.Equal(t, int64(1), installment.TransactionID)
+		assert.Equal(t, i+1, installment.Number)
+	}
+}
+
+func TestInstallment_NewInstallmentPlan(t *testing.T) {
+	transactionDate := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		balance  Money
+		reversed Money
+		expected []InstallmentStatus
+	}{
+		{
+			name:     "nothing paid",
+			balance:  MustParseMoney("-100.00"),
+			expected: []InstallmentStatus{InstallmentOverdue, InstallmentOverdue, InstallmentScheduled, InstallmentScheduled},
+		},
+		{
+			name:     "first installments paid",
+			balance:  MustParseMoney("-40.00"),
+			expected: []InstallmentStatus{InstallmentPaid, InstallmentPaid, InstallmentScheduled, InstallmentScheduled},
+		},
+		{
+			name:     "partially reversed",
+			balance:  MustParseMoney("-70.00"),
+			reversed: MustParseMoney("30.00"),
+			expected: []InstallmentStatus{InstallmentOverdue, InstallmentOverdue, InstallmentScheduled, InstallmentCancelled},
+		},
+		{
+			name:     "partially reversed and paid",
+			balance:  MustParseMoney("0.00"),
+			reversed: MustParseMoney("30.00"),
+			expected: []InstallmentStatus{InstallmentPaid, InstallmentPaid, InstallmentPaid, InstallmentCancelled},
+		},
+		{
+			name:     "reversed",
+			balance:  MustParseMoney("0.00"),
+			reversed: MustParseMoney("100.00"),
+			expected: []InstallmentStatus{InstallmentCancelled, InstallmentCancelled, InstallmentCancelled, InstallmentCancelled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &Transaction{
+				ID:              1,
+				Amount:          MustParseMoney("-100.00"),
+				Balance:         tt.balance,
+				ReversedAmount:  tt.reversed,
+				TransactionDate: transactionDate,
+			}
+
+			plan := NewInstallmentPlan(transaction, NewInstallments(transaction, 4), now)
+
+			statuses := make([]InstallmentStatus, 0, len(plan.Installments))
+			for _, installment := range plan.Installments {
+				statuses = append(statuses, installment.Status)
+			}
+			assert.Equal(t, tt.expected, statuses)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Money is an exact monetary amount with two fraction digits, kept as an
+// integer number of cents so sums never drift.
+type Money int64
+
+const centsPerUnit = 100
+
+// MaxMoney is the largest amount the DECIMAL(10,2) columns hold. Larger
+// amounts are rejected when requests are validated, before any database
+// refuses or truncates them.
+const MaxMoney Money = 9999999999
+
+func MoneyFromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// ParseMoney parses a plain decimal such as "-12.30". More than two fraction
+// digits, exponents and values that do not fit are rejected.
+func ParseMoney(value string) (Money, error) {
+	text := value
+	negative := false
+	switch {
+	case strings.HasPrefix(text, "-"):
+		negative = true
+		text = text[1:]
+	case strings.HasPrefix(text, "+"):
+		text = text[1:]
+	}
+
+	units, fraction, hasFraction := strings.Cut(text, ".")
+	if units == "" || !isDigits(units) || (hasFraction && (fraction == "" || !isDigits(fraction))) {
+		return 0, fmt.Errorf("invalid money value %q", value)
+	}
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("money value %q has more than 2 fraction digits", value)
+	}
+
+	parsedUnits, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || parsedUnits > math.MaxInt64/centsPerUnit-1 {
+		return 0, fmt.Errorf("money value %q is out of range", value)
+	}
+
+	cents := parsedUnits * centsPerUnit
+	if fraction != "" {
+		parsedFraction, _ := strconv.ParseInt(fraction, 10, 64)
+		if len(fraction) == 1 {
+			parsedFraction *= 10
+		}
+		cents += parsedFraction
+	}
+
+	if negative {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+// MustParseMoney is like ParseMoney but panics on invalid input. It is meant
+// for constants and tests.
+func MustParseMoney(value string) Money {
+	money, err := ParseMoney(value)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// InRange reports whether the amount fits the DECIMAL(10,2) columns.
+func (m Money) InRange() bool {
+	return m.Abs() <= MaxMoney
+}
+
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsPerUnit, cents%centsPerUnit)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string. The literal is
+// parsed as text, never through float64. Invalid amounts are reported as a
+// json.UnmarshalTypeError, which the decoder completes with the field at fault.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	money, err := ParseMoney(text)
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeOf(*m)}
+	}
+	*m = money
+	return nil
+}
+
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v * centsPerUnit)
+	case float64:
+		*m = Money(math.Round(v * centsPerUnit))
+	case []byte:
+		return m.scanText(string(v))
+	case string:
+		return m.scanText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", value)
+	}
+	return nil
+}
+
+// scanText parses the textual form drivers use for DECIMAL columns, which may
+// carry more zero padding than two fraction digits.
+func (m *Money) scanText(value string) error {
+	if units, fraction, ok := strings.Cut(value, "."); ok && len(fraction) > 2 {
+		if strings.Trim(fraction[2:], "0") != "" {
+			return fmt.Errorf("money value %q has more than 2 fraction digits", value)
+		}
+		value = units + "." + fraction[:2]
+	}
+
+	money, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (Money) GormDBDataType(*gorm.DB, *schema.Field) string {
+	return "DECIMAL(10,2)"
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_ParseMoney(t *testing.T) {
+	valid := map[string]Money{
+		"0":        0,
+		"10":       1000,
+		"10.5":     1050,
+		"10.05":    1005,
+		"-0.30":    -30,
+		"+1234.99": 123499,
+	}
+	for text, expected := range valid {
+		money, err := ParseMoney(text)
+		assert.NoError(t, err, text)
+		assert.Equal(t, expected, money, text)
+	}
+
+	invalid := []string{"", "-", "abc", "1.", ".5", "1.234", "1e3", "1,50", "99999999999999999999"}
+	for _, text := range invalid {
+		_, err := ParseMoney(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestMoney_ExactSums(t *testing.T) {
+	assert.Equal(t, MustParseMoney("0.30"), MustParseMoney("0.10")+MustParseMoney("0.20"))
+	assert.Equal(t, "-13.80", (MustParseMoney("-73.80") + MustParseMoney("60")).String())
+}
+
+func TestMoney_InRange(t *testing.T) {
+	assert.True(t, MustParseMoney("99999999.99").InRange())
+	assert.True(t, MustParseMoney("-99999999.99").InRange())
+	assert.False(t, MustParseMoney("100000000.00").InRange())
+	assert.False(t, MustParseMoney("-100000000.00").InRange())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var payload struct {
+		Amount Money `json:"amount"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": 200.5}`), &payload))
+	assert.Equal(t, MoneyFromCents(20050), payload.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": "0.10"}`), &payload))
+	assert.Equal(t, MoneyFromCents(10), payload.Amount)
+
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, json.Unmarshal([]byte(`{"amount": 0.105}`), &payload), &typeErr)
+	assert.Equal(t, reflect.TypeOf(Money(0)), typeErr.Type)
+	assert.Equal(t, "0.105", typeErr.Value)
+
+	encoded, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 0.10}`, string(encoded))
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var money Money
+
+	assert.NoError(t, money.Scan([]byte("1234.50")))
+	assert.Equal(t, MoneyFromCents(123450), money)
+
+	assert.NoError(t, money.Scan("-0.3000"))
+	assert.Equal(t, MoneyFromCents(-30), money)
+
+	assert.NoError(t, money.Scan(0.3))
+	assert.Equal(t, MoneyFromCents(30), money)
+
+	assert.NoError(t, money.Scan(int64(7)))
+	assert.Equal(t, MoneyFromCents(700), money)
+
+	assert.NoError(t, money.Scan(nil))
+	assert.Zero(t, money)
+
+	assert.Error(t, money.Scan("1.005"))
+	assert.Error(t, money.Scan(true))
+
+	value, err := MoneyFromCents(-5).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "-0.05", value)
+}
@@ -0,0 +1,37 @@
+package model
+
+type OperationSign string
+
+const (
+	Debit  OperationSign = "debit"
+	Credit OperationSign = "credit"
+)
+
+// OperationTypeDefinition is an entry of the operation types catalog. Its sign
+// decides whether the amounts posted with it are debited or credited.
+type OperationTypeDefinition struct {
+	ID          OperationType `gorm:"primaryKey"`
+	Description string        `gorm:"size:255;not null"`
+	Sign        OperationSign `gorm:"size:6;not null"`
+	Active      bool          `gorm:"not null"`
+}
+
+func (OperationTypeDefinition) TableName() string {
+	return "operation_types"
+}
+
+// BuiltInOperationTypes are the entries every catalog is seeded with.
+var BuiltInOperationTypes = []OperationTypeDefinition{
+	{ID: Purchase, Description: "Normal Purchase", Sign: Debit, Active: true},
+	{ID: InstallmentPurchase, Description: "Purchase with installments", Sign: Debit, Active: true},
+	{ID: Withdrawal, Description: "Withdrawal", Sign: Debit, Active: true},
+	{ID: Payment, Description: "Credit Voucher", Sign: Credit, Active: true},
+}
+
+// SignedAmount applies the sign of the operation type to a positive amount.
+func (o *OperationTypeDefinition) SignedAmount(amount Money) Money {
+	if o.Sign == Debit {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultClosingDay = 25
+	DefaultDueDay     = 5
+	// MaxBillingDay keeps closing and due days valid in every month.
+	MaxBillingDay = 28
+
+	minimumPaymentPercent = 15
+
+	periodLayout = "2006-01"
+)
+
+var minimumPaymentFloor = MustParseMoney("10.00")
+
+// Statement summarizes one billing cycle of an account. A cycle is named after
+// the month it closes in, covers [PeriodStart, PeriodEnd) and ends at the end
+// of the closing day. It bills the transactions posted during the cycle, except
+// installment purchases, and the installments due during it. Balances follow
+// the account sign, so a negative closing balance is what is billed and owed.
+// ClosedAt is nil while the cycle is still open.
+type Statement struct {
+	ID             int64     `gorm:"primaryKey"`
+	AccountID      int64     `gorm:"not null;uniqueIndex:idx_statements_account_period"`
+	Period         string    `gorm:"size:7;not null;uniqueIndex:idx_statements_account_period"`
+	PeriodStart    time.Time `gorm:"not null"`
+	PeriodEnd      time.Time `gorm:"not null"`
+	DueDate        time.Time `gorm:"not null"`
+	OpeningBalance Money     `gorm:"not null"`
+	TotalDebits    Money     `gorm:"not null"`
+	TotalCredits   Money     `gorm:"not null"`
+	ClosingBalance Money     `gorm:"not null"`
+	MinimumPayment Money     `gorm:"not null"`
+	ClosedAt       *time.Time
+	Transactions   []Transaction `gorm:"-"`
+	Installments   []Installment `gorm:"-"`
+}
+
+func (s *Statement) IsClosed() bool {
+	return s.ClosedAt != nil
+}
+
+// ParsePeriod parses a period such as "2024-09" into the first instant of its
+// month, in UTC.
+func ParsePeriod(period string) (time.Time, error) {
+	month, err := time.Parse(periodLayout, period)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+	return month, nil
+}
+
+func FormatPeriod(month time.Time) string {
+	return month.Format(periodLayout)
+}
+
+// CycleEnd is the exclusive end of the account cycle closing in month.
+func (a *Account) CycleEnd(month time.Time) time.Time {
+	return time.Date(month.Year(), month.Month(), a.ClosingDay+1, 0, 0, 0, 0, time.UTC)
+}
+
+// CycleStart is the inclusive start of the account cycle closing in month,
+// which is where the previous cycle ends.
+func (a *Account) CycleStart(month time.Time) time.Time {
+	return a.CycleEnd(month.AddDate(0, -1, 0))
+}
+
+// CycleDueDate is when the statement of the cycle closing in month is due,
+// the first due day after the closing day.
+func (a *Account) CycleDueDate(month time.Time) time.Time {
+	if a.DueDay > a.ClosingDay {
+		return time.Date(month.Year(), month.Month(), a.DueDay, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(month.Year(), month.Month()+1, a.DueDay, 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodAt returns the month of the account cycle that contains t.
+func (a *Account) PeriodAt(t time.Time) time.Time {
+	t = t.UTC()
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !t.Before(a.CycleEnd(month)) {
+		return month.AddDate(0, 1, 0)
+	}
+	return month
+}
+
+// NewStatement computes the statement of the account cycle closing in month
+// from the balance it opened with, the transactions posted during it and the
+// installments due during it. Installment purchases among the transactions are
+// left out, as their installments bill them.
+func NewStatement(account *Account, month time.Time, openingBalance Money, transactions []Transaction, installments []Installment) *Statement {
+	statement := &Statement{
+		AccountID:      account.ID,
+		Period:         FormatPeriod(month),
+		PeriodStart:    account.CycleStart(month),
+		PeriodEnd:      account.CycleEnd(month),
+		DueDate:        account.CycleDueDate(month),
+		OpeningBalance: openingBalance,
+		Transactions:   BilledTransactions(transactions),
+		Installments:   installments,
+	}
+
+	for _, transaction := range statement.Transactions {
+		if transaction.Amount < 0 {
+			statement.TotalDebits -= transaction.Amount
+		} else {
+			statement.TotalCredits += transaction.Amount
+		}
+	}
+	for _, installment := range installments {
+		statement.TotalDebits += installment.Amount
+	}
+
+	statement.ClosingBalance = openingBalance - statement.TotalDebits + statement.TotalCredits
+	statement.MinimumPayment = MinimumPayment(statement.ClosingBalance)
+
+	return statement
+}
+
+// BilledTransactions returns the transactions a statement bills directly,
+// leaving out the installment purchases.
+func BilledTransactions(transactions []Transaction) []Transaction {
+	billed := make([]Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if !transaction.IsBilledInInstallments() {
+			billed = append(billed, transaction)
+		}
+	}
+	return billed
+}
+
+// MinimumPayment is 15% of what is owed, rounded up to the cent, but at least
+// 10.00 or everything owed when that is less.
+func MinimumPayment(closingBalance Money) Money {
+	if closingBalance >= 0 {
+		return 0
+	}
+
+	owed := -closingBalance
+	minimum := (owed*minimumPaymentPercent + 99) / 100
+	return min(max(minimum, minimumPaymentFloor), owed)
+}
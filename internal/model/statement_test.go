@@ -0,0 +1,85 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatement_Cycle(t *testing.T) {
+	account := &Account{ClosingDay: 25, DueDay: 5}
+	september, _ := ParsePeriod("2024-09")
+
+	assert.Equal(t, time.Date(2024, time.August, 26, 0, 0, 0, 0, time.UTC), account.CycleStart(september))
+	assert.Equal(t, time.Date(2024, time.September, 26, 0, 0, 0, 0, time.UTC), account.CycleEnd(september))
+	assert.Equal(t, time.Date(2024, time.October, 5, 0, 0, 0, 0, time.UTC), account.CycleDueDate(september))
+
+	assert.Equal(t, "2024-09", FormatPeriod(account.PeriodAt(time.Date(2024, time.September, 25, 23, 59, 0, 0, time.UTC))))
+	assert.Equal(t, "2024-10", FormatPeriod(account.PeriodAt(time.Date(2024, time.September, 26, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, "2025-01", FormatPeriod(account.PeriodAt(time.Date(2024, time.December, 30, 0, 0, 0, 0, time.UTC))))
+
+	sameMonthDue := &Account{ClosingDay: 3, DueDay: 13}
+	assert.Equal(t, time.Date(2024, time.September, 13, 0, 0, 0, 0, time.UTC), sameMonthDue.CycleDueDate(september))
+
+	_, err := ParsePeriod("2024-13")
+	assert.Error(t, err)
+}
+
+func TestStatement_NewStatement(t *testing.T) {
+	account := &Account{ID: 1, ClosingDay: 10, DueDay: 20}
+	month, _ := ParsePeriod("2024-03")
+
+	transactions := []Transaction{
+		{Amount: MustParseMoney("-300.00")},
+		{Amount: MustParseMoney("-50.50")},
+		{Amount: MustParseMoney("100.00")},
+	}
+
+	statement := NewStatement(account, month, MustParseMoney("-20.00"), transactions, nil)
+
+	assert.Equal(t, "2024-03", statement.Period)
+	assert.Equal(t, MustParseMoney("350.50"), statement.TotalDebits)
+	assert.Equal(t, MustParseMoney("100.00"), statement.TotalCredits)
+	assert.Equal(t, MustParseMoney("-270.50"), statement.ClosingBalance)
+	assert.Equal(t, MustParseMoney("40.58"), statement.MinimumPayment)
+	assert.False(t, statement.IsClosed())
+}
+
+func TestStatement_NewStatementBillsInstallments(t *testing.T) {
+	account := &Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	purchase := &Transaction{ID: 1, Amount: MustParseMoney("-300.00"), OperationType: InstallmentPurchase, TransactionDate: time.Date(2024, time.September, 10, 0, 0, 0, 0, time.UTC)}
+	installments := NewInstallments(purchase, 3)
+
+	september, _ := ParsePeriod("2024-09")
+	statement := NewStatement(account, september, 0, []Transaction{*purchase}, nil)
+
+	assert.Empty(t, statement.Transactions)
+	assert.Zero(t, statement.TotalDebits)
+	assert.Zero(t, statement.MinimumPayment)
+
+	openingBalance := statement.ClosingBalance
+	for i, period := range []string{"2024-10", "2024-11", "2024-12"} {
+		month, _ := ParsePeriod(period)
+		statement = NewStatement(account, month, openingBalance, nil, installments[i:i+1])
+
+		assert.Equal(t, MustParseMoney("100.00"), statement.TotalDebits, period)
+		assert.Len(t, statement.Installments, 1, period)
+		openingBalance = statement.ClosingBalance
+	}
+
+	assert.Equal(t, MustParseMoney("-300.00"), statement.ClosingBalance)
+	assert.Equal(t, MustParseMoney("45.00"), statement.MinimumPayment)
+
+	reversal := Transaction{ID: 2, Amount: MustParseMoney("300.00"), OperationType: InstallmentPurchase, ReversalOfID: &purchase.ID}
+	statement = NewStatement(account, september, 0, []Transaction{*purchase, reversal}, nil)
+	assert.Equal(t, []Transaction{reversal}, statement.Transactions)
+	assert.Equal(t, MustParseMoney("300.00"), statement.TotalCredits)
+}
+
+func TestStatement_MinimumPayment(t *testing.T) {
+	assert.Equal(t, MustParseMoney("0.00"), MinimumPayment(MustParseMoney("12.00")))
+	assert.Equal(t, MustParseMoney("5.00"), MinimumPayment(MustParseMoney("-5.00")))
+	assert.Equal(t, MustParseMoney("10.00"), MinimumPayment(MustParseMoney("-40.00")))
+	assert.Equal(t, MustParseMoney("150.00"), MinimumPayment(MustParseMoney("-1000.00")))
+}
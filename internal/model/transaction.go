@@ -4,6 +4,8 @@ import (
 	"time"
 )
 
+// OperationType identifies an entry of the operation types catalog. The
+// constants are the IDs of the built-in entries.
 type OperationType int
 
 const (
@@ -13,11 +15,38 @@ const (
 	Payment
 )
 
+type TransactionStatus string
+
+const (
+	Posted            TransactionStatus = "posted"
+	PartiallyReversed TransactionStatus = "partially_reversed"
+	Reversed          TransactionStatus = "reversed"
+)
+
+// Transaction is a signed posting to an account. Balance is what is left of it
+// after settlement, ReversedAmount is how much of it was reversed, and
+// ReversalOfID links a compensating transaction to the one it reverses.
 type Transaction struct {
 	ID              int64 `gorm:"primaryKey"`
 	OperationType   OperationType
-	Amount          float64
+	Amount          Money
+	Balance         Money             `gorm:"not null;default:0"`
+	ReversedAmount  Money             `gorm:"not null;default:0"`
+	Status          TransactionStatus `gorm:"size:20;not null;default:posted"`
+	ReversalOfID    *int64
 	TransactionDate time.Time
 	AccountID       int64
 	Account         Account `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
+
+// IsBilledInInstallments reports whether the transaction is an installment
+// purchase, which statements bill through its installments as they fall due
+// rather than all at once. Its reversals are billed like any other credit.
+func (t *Transaction) IsBilledInInstallments() bool {
+	return t.OperationType == InstallmentPurchase && t.ReversalOfID == nil
+}
+
+// UnreversedAmount is the part of the transaction that can still be reversed.
+func (t *Transaction) UnreversedAmount() Money {
+	return t.Amount.Abs() - t.ReversedAmount
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// TransactionCursor points at the last transaction of a page. Pages are keyed
+// on (TransactionDate, ID), so inserts made while paging never shift them.
+type TransactionCursor struct {
+	TransactionDate time.Time
+	ID              int64
+}
+
+// TransactionFilter narrows the transactions of an account. Amount bounds apply
+// to the absolute amount, as it was posted.
+type TransactionFilter struct {
+	AccountID      int64
+	OperationTypes []OperationType
+	From           *time.Time
+	To             *time.Time
+	MinAmount      *Money
+	MaxAmount      *Money
+	Sort           SortOrder
+	Limit          int
+	After          *TransactionCursor
+}
+
+type TransactionPage struct {
+	Transactions []Transaction
+	Next         *TransactionCursor
+}
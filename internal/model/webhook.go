@@ -0,0 +1,84 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending      WebhookDeliveryStatus = "pending"
+	DeliverySucceeded    WebhookDeliveryStatus = "succeeded"
+	DeliveryDeadLettered WebhookDeliveryStatus = "dead_lettered"
+)
+
+// EventTypes is a list of event types stored as a comma separated column.
+type EventTypes []EventType
+
+func (e EventTypes) Value() (driver.Value, error) {
+	values := make([]string, len(e))
+	for i, eventType := range e {
+		values[i] = string(eventType)
+	}
+	return strings.Join(values, ","), nil
+}
+
+func (e *EventTypes) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EventTypes", value)
+	}
+
+	*e = nil
+	for _, eventType := range strings.Split(text, ",") {
+		if eventType != "" {
+			*e = append(*e, EventType(eventType))
+		}
+	}
+	return nil
+}
+
+// WebhookSubscription is an HTTP endpoint that receives the events of the
+// listed types, or of every type when EventTypes is empty. Deliveries are
+// signed with Secret.
+type WebhookSubscription struct {
+	ID         int64      `gorm:"primaryKey"`
+	URL        string     `gorm:"size:2048;not null"`
+	Secret     string     `gorm:"size:255;not null"`
+	EventTypes EventTypes `gorm:"size:255;not null;default:''"`
+	Active     bool       `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+func (s *WebhookSubscription) Accepts(eventType EventType) bool {
+	return len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, eventType)
+}
+
+// WebhookDelivery is one event queued for one subscription. It is retried with
+// backoff until the endpoint accepts it or it runs out of attempts and is dead
+// lettered, after which it waits to be redriven.
+type WebhookDelivery struct {
+	ID              int64                 `gorm:"primaryKey"`
+	SubscriptionID  int64                 `gorm:"not null;uniqueIndex:idx_webhook_deliveries_subscription_message"`
+	OutboxMessageID int64                 `gorm:"not null;uniqueIndex:idx_webhook_deliveries_subscription_message"`
+	EventType       EventType             `gorm:"size:64;not null"`
+	Payload         string                `gorm:"type:text;not null"`
+	Status          WebhookDeliveryStatus `gorm:"size:20;not null;index:idx_webhook_deliveries_status_next_attempt"`
+	Attempts        int                   `gorm:"not null;default:0"`
+	NextAttemptAt   time.Time             `gorm:"not null;index:idx_webhook_deliveries_status_next_attempt"`
+	LastStatusCode  int                   `gorm:"not null;default:0"`
+	LastError       string                `gorm:"size:1024"`
+	CreatedAt       time.Time
+	DeliveredAt     *time.Time
+	Subscription    WebhookSubscription `gorm:"constraint:OnDelete:CASCADE;"`
+}
@@ -0,0 +1,64 @@
+// Package publisher delivers outbox messages to downstream systems.
+package publisher
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Publisher delivers one outbox message. Messages can be delivered more than
+// once, so downstream consumers must deduplicate them by ID. A returned error
+// leaves the message pending to be retried.
+type Publisher interface {
+	Publish(ctx context.Context, message *model.OutboxMessage) error
+}
+
+// PublisherFunc adapts a function to a Publisher.
+type PublisherFunc func(ctx context.Context, message *model.OutboxMessage) error
+
+func (f PublisherFunc) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	return f(ctx, message)
+}
+
+type logPublisher struct{}
+
+// NewLogPublisher returns a publisher that only logs the messages it gets. The
+// payloads are left out, since they hold personal data such as document
+// numbers.
+func NewLogPublisher() Publisher {
+	return &logPublisher{}
+}
+
+func (p *logPublisher) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"messageID": message.ID,
+		"accountID": message.AccountID,
+		"eventType": message.EventType,
+	}).Info("Published event")
+	return nil
+}
+
+type fanout struct {
+	publishers []Publisher
+}
+
+// NewFanout returns a publisher that delivers each message to all of the given
+// publishers. It fails when any of them fails, so the message is retried on
+// all of them.
+func NewFanout(publishers ...Publisher) Publisher {
+	return &fanout{publishers}
+}
+
+func (f *fanout) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	var errs []error
+	for _, publisher := range f.publishers {
+		if err := publisher.Publish(ctx, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
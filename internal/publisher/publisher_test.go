@@ -0,0 +1,59 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	log "github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanout_Publish(t *testing.T) {
+	var delivered []string
+	recorder := func(name string, err error) Publisher {
+		return PublisherFunc(func(ctx context.Context, message *model.OutboxMessage) error {
+			delivered = append(delivered, name)
+			return err
+		})
+	}
+
+	message := &model.OutboxMessage{ID: 1, EventType: model.AccountCreatedEvent}
+
+	err := NewFanout(recorder("first", nil), NewLogPublisher(), recorder("second", nil)).Publish(context.Background(), message)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, delivered)
+
+	delivered = nil
+	err = NewFanout(recorder("first", errors.New("unavailable")), recorder("second", nil)).Publish(context.Background(), message)
+	assert.EqualError(t, err, "unavailable")
+	assert.Equal(t, []string{"first", "second"}, delivered)
+}
+
+func TestLogPublisher_PublishLeavesPayloadOut(t *testing.T) {
+	logger, hook := logTest.NewNullLogger()
+	logger.SetLevel(log.TraceLevel)
+	ctx := logging.NewContext(context.Background(), log.NewEntry(logger))
+
+	message := &model.OutboxMessage{
+		ID:        7,
+		AccountID: 42,
+		EventType: model.AccountCreatedEvent,
+		Payload:   `{"account_id": 42, "document_number": "12345678900"}`,
+	}
+
+	require.NoError(t, NewLogPublisher().Publish(ctx, message))
+
+	require.Len(t, hook.Entries, 1)
+	assert.Equal(t, "Published event", hook.LastEntry().Message)
+	assert.Equal(t, log.Fields{"messageID": message.ID, "accountID": int64(42), "eventType": model.AccountCreatedEvent}, hook.LastEntry().Data)
+	for _, entry := range hook.AllEntries() {
+		line, err := entry.String()
+		require.NoError(t, err)
+		assert.NotContains(t, line, "12345678900")
+	}
+}
@@ -1,13 +1,21 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/gmerten/accounts_transactions/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AccountRepository interface {
-	Create(account *model.Account) (*model.Account, error)
-	FindById(id int64) (*model.Account, error)
+	Create(ctx context.Context, account *model.Account) (*model.Account, error)
+	FindById(ctx context.Context, id int64) (*model.Account, error)
+	FindByIdForUpdate(ctx context.Context, id int64) (*model.Account, error)
+	FindAfterId(ctx context.Context, afterID int64, limit int) ([]model.Account, error)
+	UpdateBalance(ctx context.Context, id int64, balance model.Money) error
+	UpdateAvailableCreditLimit(ctx context.Context, id int64, availableCreditLimit model.Money) error
+	UpdateStatus(ctx context.Context, id int64, status model.AccountStatus) error
 }
 
 type accountRepository struct {
@@ -18,17 +26,49 @@ func NewAccountRepository(db *gorm.DB) AccountRepository {
 	return &accountRepository{db}
 }
 
-func (r *accountRepository) Create(account *model.Account) (*model.Account, error) {
-	if err := r.db.Create(account).Error; err != nil {
+func (r *accountRepository) Create(ctx context.Context, account *model.Account) (*model.Account, error) {
+	if err := r.db.WithContext(ctx).Create(account).Error; err != nil {
 		return nil, err
 	}
 	return account, nil
 }
 
-func (r *accountRepository) FindById(id int64) (*model.Account, error) {
+func (r *accountRepository) FindById(ctx context.Context, id int64) (*model.Account, error) {
 	var account model.Account
-	if err := r.db.First(&account, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&account, id).Error; err != nil {
 		return nil, err
 	}
 	return &account, nil
 }
+
+// FindByIdForUpdate loads the account holding a row lock until the surrounding
+// database transaction ends, so concurrent postings to it are serialized.
+func (r *accountRepository) FindByIdForUpdate(ctx context.Context, id int64) (*model.Account, error) {
+	var account model.Account
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, id).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindAfterId returns up to limit accounts with an id greater than afterID,
+// ordered by id, so callers can page through every account.
+func (r *accountRepository) FindAfterId(ctx context.Context, afterID int64, limit int) ([]model.Account, error) {
+	var accounts []model.Account
+	if err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *accountRepository) UpdateBalance(ctx context.Context, id int64, balance model.Money) error {
+	return r.db.WithContext(ctx).Model(&model.Account{}).Where("id = ?", id).Update("balance", balance).Error
+}
+
+func (r *accountRepository) UpdateAvailableCreditLimit(ctx context.Context, id int64, availableCreditLimit model.Money) error {
+	return r.db.WithContext(ctx).Model(&model.Account{}).Where("id = ?", id).Update("available_credit_limit", availableCreditLimit).Error
+}
+
+func (r *accountRepository) UpdateStatus(ctx context.Context, id int64, status model.AccountStatus) error {
+	return r.db.WithContext(ctx).Model(&model.Account{}).Where("id = ?", id).Update("status", status).Error
+}
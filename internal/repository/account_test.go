@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"testing"
 
 	"github.com/gmerten/accounts_transactions/internal/model"
@@ -22,13 +23,13 @@ func TestAccountRepository_Create(t *testing.T) {
 		DocumentNumber: "123456780",
 	}
 
-	createdAccount, err := repo.Create(account)
+	createdAccount, err := repo.Create(context.Background(), account)
 
 	assert.NoError(t, err)
 	assert.NotZero(t, createdAccount.ID)
 	assert.Equal(t, "123456780", createdAccount.DocumentNumber)
 
-	_, err = repo.Create(accountError)
+	_, err = repo.Create(context.Background(), accountError)
 	assert.Error(t, err)
 
 }
@@ -43,10 +44,10 @@ func TestAccountRepository_FindById(t *testing.T) {
 		DocumentNumber: "123456",
 	}
 
-	createdAccount, err := repo.Create(account)
+	createdAccount, err := repo.Create(context.Background(), account)
 	assert.NoError(t, err)
 
-	foundAccount, err := repo.FindById(createdAccount.ID)
+	foundAccount, err := repo.FindById(context.Background(), createdAccount.ID)
 	assert.NoError(t, err)
 
 	assert.Equal(t, createdAccount.ID, foundAccount.ID)
@@ -59,8 +60,127 @@ func TestAccountRepository_FindById_NotFound(t *testing.T) {
 
 	repo := NewAccountRepository(db)
 
-	_, err := repo.FindById(999)
+	_, err := repo.FindById(context.Background(), 999)
 
 	assert.Error(t, err)
 	assert.Equal(t, gorm.ErrRecordNotFound, err)
 }
+
+func TestAccountRepository_FindByIdForUpdate(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewAccountRepository(db)
+
+	account := &model.Account{
+		DocumentNumber: "123456",
+	}
+
+	createdAccount, err := repo.Create(context.Background(), account)
+	assert.NoError(t, err)
+
+	foundAccount, err := repo.FindByIdForUpdate(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, createdAccount.ID, foundAccount.ID)
+
+	_, err = repo.FindByIdForUpdate(context.Background(), 999)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestAccountRepository_FindAfterId(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewAccountRepository(db)
+
+	var ids []int64
+	for _, documentNumber := range []string{"111", "222", "333"} {
+		createdAccount, err := repo.Create(context.Background(), &model.Account{DocumentNumber: documentNumber})
+		assert.NoError(t, err)
+		ids = append(ids, createdAccount.ID)
+	}
+
+	accounts, err := repo.FindAfterId(context.Background(), 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, ids[0], accounts[0].ID)
+	assert.Equal(t, ids[1], accounts[1].ID)
+
+	accounts, err = repo.FindAfterId(context.Background(), ids[1], 2)
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, ids[2], accounts[0].ID)
+}
+
+func TestAccountRepository_UpdateBalance(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewAccountRepository(db)
+
+	account := &model.Account{
+		DocumentNumber: "123456",
+	}
+
+	createdAccount, err := repo.Create(context.Background(), account)
+	assert.NoError(t, err)
+	assert.Zero(t, createdAccount.Balance)
+
+	err = repo.UpdateBalance(context.Background(), createdAccount.ID, model.MustParseMoney("-150.50"))
+	assert.NoError(t, err)
+
+	foundAccount, err := repo.FindById(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("-150.50"), foundAccount.Balance)
+}
+
+func TestAccountRepository_UpdateAvailableCreditLimit(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewAccountRepository(db)
+
+	availableCreditLimit := model.MustParseMoney("500.00")
+	account := &model.Account{
+		DocumentNumber:       "123456",
+		AvailableCreditLimit: &availableCreditLimit,
+	}
+
+	createdAccount, err := repo.Create(context.Background(), account)
+	assert.NoError(t, err)
+
+	err = repo.UpdateAvailableCreditLimit(context.Background(), createdAccount.ID, model.MustParseMoney("379.50"))
+	assert.NoError(t, err)
+
+	foundAccount, err := repo.FindById(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("379.50"), *foundAccount.AvailableCreditLimit)
+
+	unlimitedAccount, err := repo.Create(context.Background(), &model.Account{DocumentNumber: "654321"})
+	assert.NoError(t, err)
+
+	foundAccount, err = repo.FindById(context.Background(), unlimitedAccount.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, foundAccount.AvailableCreditLimit)
+}
+
+func TestAccountRepository_UpdateStatus(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewAccountRepository(db)
+
+	createdAccount, err := repo.Create(context.Background(), &model.Account{DocumentNumber: "123456"})
+	assert.NoError(t, err)
+
+	foundAccount, err := repo.FindById(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.AccountActive, foundAccount.Status)
+
+	err = repo.UpdateStatus(context.Background(), createdAccount.ID, model.AccountBlocked)
+	assert.NoError(t, err)
+
+	foundAccount, err = repo.FindById(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.AccountBlocked, foundAccount.Status)
+}
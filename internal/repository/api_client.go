@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+type APIClientRepository interface {
+	Create(ctx context.Context, client *model.APIClient) (*model.APIClient, error)
+	FindById(ctx context.Context, id int64) (*model.APIClient, error)
+	FindByKeyHash(ctx context.Context, keyHash string) (*model.APIClient, error)
+	FindAll(ctx context.Context) ([]model.APIClient, error)
+	Update(ctx context.Context, client *model.APIClient) error
+	Delete(ctx context.Context, id int64) (int64, error)
+}
+
+type apiClientRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIClientRepository(db *gorm.DB) APIClientRepository {
+	return &apiClientRepository{db}
+}
+
+func (r *apiClientRepository) Create(ctx context.Context, client *model.APIClient) (*model.APIClient, error) {
+	if err := r.db.WithContext(ctx).Create(client).Error; err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (r *apiClientRepository) FindById(ctx context.Context, id int64) (*model.APIClient, error) {
+	var client model.APIClient
+	if err := r.db.WithContext(ctx).First(&client, id).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *apiClientRepository) FindByKeyHash(ctx context.Context, keyHash string) (*model.APIClient, error) {
+	var client model.APIClient
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&client).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *apiClientRepository) FindAll(ctx context.Context) ([]model.APIClient, error) {
+	var clients []model.APIClient
+	if err := r.db.WithContext(ctx).Order("id").Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// Update saves the key, scopes and active flag of the client.
+func (r *apiClientRepository) Update(ctx context.Context, client *model.APIClient) error {
+	return r.db.WithContext(ctx).Model(&model.APIClient{}).Where("id = ?", client.ID).Updates(map[string]interface{}{
+		"key_prefix": client.KeyPrefix,
+		"key_hash":   client.KeyHash,
+		"scopes":     client.Scopes,
+		"active":     client.Active,
+		"rotated_at": client.RotatedAt,
+	}).Error
+}
+
+// Delete removes the client and returns how many clients were deleted.
+func (r *apiClientRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&model.APIClient{}, id)
+	return result.RowsAffected, result.Error
+}
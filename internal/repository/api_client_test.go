@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAPIClientRepository_CRUD(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewAPIClientRepository(db)
+
+	created, err := repo.Create(context.Background(), &model.APIClient{
+		Name:      "billing",
+		KeyPrefix: "ak_01234567",
+		KeyHash:   "hash-1",
+		Scopes:    model.Scopes{model.ScopeAccountsRead, model.ScopeTransactionsWrite},
+		Active:    true,
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.Create(context.Background(), &model.APIClient{Name: "duplicate", KeyPrefix: "ak_01234567", KeyHash: "hash-1"})
+	assert.Error(t, err)
+
+	_, err = repo.Create(context.Background(), &model.APIClient{Name: "ops", KeyPrefix: "ak_89abcdef", KeyHash: "hash-2", Scopes: model.Scopes{model.ScopeAdmin}})
+	assert.NoError(t, err)
+
+	found, err := repo.FindByKeyHash(context.Background(), "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.Equal(t, model.Scopes{model.ScopeAccountsRead, model.ScopeTransactionsWrite}, found.Scopes)
+
+	_, err = repo.FindByKeyHash(context.Background(), "hash-3")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	rotatedAt := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+	found.KeyPrefix = "ak_fedcba98"
+	found.KeyHash = "hash-3"
+	found.Active = false
+	found.RotatedAt = &rotatedAt
+	assert.NoError(t, repo.Update(context.Background(), found))
+
+	_, err = repo.FindByKeyHash(context.Background(), "hash-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	found, err = repo.FindById(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "ak_fedcba98", found.KeyPrefix)
+	assert.False(t, found.Active)
+	assert.True(t, rotatedAt.Equal(*found.RotatedAt))
+
+	all, err := repo.FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, "billing", all[0].Name)
+	assert.Equal(t, model.Scopes{model.ScopeAdmin}, all[1].Scopes)
+
+	deleted, err := repo.Delete(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	deleted, err = repo.Delete(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, deleted)
+}
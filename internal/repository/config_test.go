@@ -4,7 +4,7 @@ import (
 	"os"
 	"testing"
 
-	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/migration"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -18,16 +18,34 @@ func SetupTestDB() {
 		panic("failed to connect to database")
 	}
 
+	// Every connection to :memory: opens a new database, so keep a single one.
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
 	db.Exec("PRAGMA foreign_keys = ON")
 
-	if err = db.AutoMigrate(&model.Account{}, &model.Transaction{}); err != nil {
-		panic("failed to migrate database")
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		panic("failed to load migrations")
+	}
+	if _, err = migrator.Up(); err != nil {
+		panic("failed to migrate database: " + err.Error())
 	}
 }
 
 func ResetTestDB() {
-	db.Exec("DELETE FROM accounts")
+	db.Exec("DELETE FROM api_clients")
+	db.Exec("DELETE FROM webhook_deliveries")
+	db.Exec("DELETE FROM webhook_subscriptions")
+	db.Exec("DELETE FROM outbox")
+	db.Exec("DELETE FROM ledger_entries")
+	db.Exec("DELETE FROM ledger_journals")
+	db.Exec("DELETE FROM statements")
+	db.Exec("DELETE FROM installments")
 	db.Exec("DELETE FROM transactions")
+	db.Exec("DELETE FROM accounts")
+	db.Exec("DELETE FROM idempotency_keys")
+	db.Exec("DELETE FROM operation_types")
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+type IdempotencyKeyRepository interface {
+	Create(ctx context.Context, idempotencyKey *model.IdempotencyKey) (*model.IdempotencyKey, error)
+	Find(ctx context.Context, key string, scope string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, idempotencyKey *model.IdempotencyKey) error
+	Delete(ctx context.Context, key string, scope string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db}
+}
+
+func (r *idempotencyKeyRepository) Create(ctx context.Context, idempotencyKey *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	if err := r.db.WithContext(ctx).Create(idempotencyKey).Error; err != nil {
+		return nil, err
+	}
+	return idempotencyKey, nil
+}
+
+func (r *idempotencyKeyRepository) Find(ctx context.Context, key string, scope string) (*model.IdempotencyKey, error) {
+	var idempotencyKey model.IdempotencyKey
+	if err := r.db.WithContext(ctx).Where("idempotency_key = ? AND scope = ?", key, scope).First(&idempotencyKey).Error; err != nil {
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
+
+func (r *idempotencyKeyRepository) Complete(ctx context.Context, idempotencyKey *model.IdempotencyKey) error {
+	return r.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("idempotency_key = ? AND scope = ?", idempotencyKey.Key, idempotencyKey.Scope).
+		Updates(map[string]interface{}{
+			"completed":     true,
+			"status_code":   idempotencyKey.StatusCode,
+			"response_body": idempotencyKey.ResponseBody,
+		}).Error
+}
+
+func (r *idempotencyKeyRepository) Delete(ctx context.Context, key string, scope string) error {
+	return r.db.WithContext(ctx).Where("idempotency_key = ? AND scope = ?", key, scope).Delete(&model.IdempotencyKey{}).Error
+}
+
+func (r *idempotencyKeyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&model.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIdempotencyKeyRepository_CreateAndFind(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewIdempotencyKeyRepository(db)
+
+	now := time.Now()
+	idempotencyKey := &model.IdempotencyKey{
+		Key:         "key-1",
+		Scope:       "POST /transactions",
+		RequestHash: "hash",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	_, err := repo.Create(context.Background(), idempotencyKey)
+	assert.NoError(t, err)
+
+	_, err = repo.Create(context.Background(), &model.IdempotencyKey{Key: "key-1", Scope: "POST /transactions", RequestHash: "other", ExpiresAt: now})
+	assert.True(t, internalErrors.IsDuplicateKeyError(err))
+
+	_, err = repo.Create(context.Background(), &model.IdempotencyKey{Key: "key-1", Scope: "POST /accounts", RequestHash: "hash", ExpiresAt: now})
+	assert.NoError(t, err)
+
+	found, err := repo.Find(context.Background(), "key-1", "POST /transactions")
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", found.RequestHash)
+	assert.False(t, found.Completed)
+
+	_, err = repo.Find(context.Background(), "key-2", "POST /transactions")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestIdempotencyKeyRepository_Complete(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewIdempotencyKeyRepository(db)
+
+	_, err := repo.Create(context.Background(), &model.IdempotencyKey{Key: "key-1", Scope: "POST /accounts", RequestHash: "hash", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+
+	err = repo.Complete(context.Background(), &model.IdempotencyKey{
+		Key:          "key-1",
+		Scope:        "POST /accounts",
+		StatusCode:   201,
+		ResponseBody: []byte(`{"account_id":1}`),
+	})
+	assert.NoError(t, err)
+
+	found, err := repo.Find(context.Background(), "key-1", "POST /accounts")
+	assert.NoError(t, err)
+	assert.True(t, found.Completed)
+	assert.Equal(t, 201, found.StatusCode)
+	assert.Equal(t, []byte(`{"account_id":1}`), found.ResponseBody)
+	assert.Equal(t, "hash", found.RequestHash)
+}
+
+func TestIdempotencyKeyRepository_DeleteAndDeleteExpired(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewIdempotencyKeyRepository(db)
+
+	now := time.Now()
+	for key, expiresAt := range map[string]time.Time{
+		"expired": now.Add(-time.Minute),
+		"valid":   now.Add(time.Hour),
+		"deleted": now.Add(time.Hour),
+	} {
+		_, err := repo.Create(context.Background(), &model.IdempotencyKey{Key: key, Scope: "POST /accounts", RequestHash: "hash", ExpiresAt: expiresAt})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, repo.Delete(context.Background(), "deleted", "POST /accounts"))
+
+	purged, err := repo.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	_, err = repo.Find(context.Background(), "valid", "POST /accounts")
+	assert.NoError(t, err)
+
+	_, err = repo.Find(context.Background(), "expired", "POST /accounts")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	_, err = repo.Find(context.Background(), "deleted", "POST /accounts")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+type InstallmentRepository interface {
+	Create(ctx context.Context, installments []model.Installment) error
+	FindByTransactionId(ctx context.Context, transactionID int64) ([]model.Installment, error)
+	FindByAccountIdDueBetween(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Installment, error)
+	SumDueBefore(ctx context.Context, accountID int64, before time.Time) (model.Money, error)
+}
+
+type installmentRepository struct {
+	db *gorm.DB
+}
+
+func NewInstallmentRepository(db *gorm.DB) InstallmentRepository {
+	return &installmentRepository{db}
+}
+
+func (r *installmentRepository) Create(ctx context.Context, installments []model.Installment) error {
+	return r.db.WithContext(ctx).Create(&installments).Error
+}
+
+func (r *installmentRepository) FindByTransactionId(ctx context.Context, transactionID int64) ([]model.Installment, error) {
+	var installments []model.Installment
+	if err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).Order("number").Find(&installments).Error; err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// FindByAccountIdDueBetween returns the installments of the account purchases
+// due in [from, to), ordered by due date.
+func (r *installmentRepository) FindByAccountIdDueBetween(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Installment, error) {
+	var installments []model.Installment
+	err := r.db.WithContext(ctx).
+		Joins("JOIN transactions ON transactions.id = installments.transaction_id").
+		Where("transactions.account_id = ? AND installments.due_date >= ? AND installments.due_date < ?", accountID, from, to).
+		Order("installments.due_date, installments.transaction_id, installments.number").
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// SumDueBefore returns how much of the account purchases fell due before.
+func (r *installmentRepository) SumDueBefore(ctx context.Context, accountID int64, before time.Time) (model.Money, error) {
+	var sum model.Money
+	err := r.db.WithContext(ctx).Model(&model.Installment{}).
+		Select("COALESCE(SUM(installments.amount), 0)").
+		Joins("JOIN transactions ON transactions.id = installments.transaction_id").
+		Where("transactions.account_id = ? AND installments.due_date < ?", accountID, before).
+		Scan(&sum).Error
+	return sum, err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallmentRepository_CreateAndFindByTransactionId(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	repo := NewInstallmentRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "52998224725"})
+	assert.NoError(t, err)
+
+	transaction, err := transactionRepo.Create(context.Background(), &model.Transaction{
+		AccountID:       createdAccount.ID,
+		Amount:          model.MustParseMoney("-100.00"),
+		Balance:         model.MustParseMoney("-100.00"),
+		TransactionDate: time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+		OperationType:   model.InstallmentPurchase,
+	})
+	assert.NoError(t, err)
+
+	err = repo.Create(context.Background(), model.NewInstallments(transaction, 3))
+	assert.NoError(t, err)
+
+	installments, err := repo.FindByTransactionId(context.Background(), transaction.ID)
+	assert.NoError(t, err)
+	assert.Len(t, installments, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{installments[0].Number, installments[1].Number, installments[2].Number})
+	assert.Equal(t, model.MustParseMoney("33.34"), installments[0].Amount)
+	assert.Equal(t, time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC), installments[1].DueDate.UTC())
+
+	err = repo.Create(context.Background(), []model.Installment{{TransactionID: transaction.ID, Number: 1, Amount: model.MustParseMoney("1.00"), DueDate: time.Now()}})
+	assert.Error(t, err)
+
+	installments, err = repo.FindByTransactionId(context.Background(), transaction.ID+1)
+	assert.NoError(t, err)
+	assert.Empty(t, installments)
+}
+
+func TestInstallmentRepository_FindByAccountIdDueBetweenAndSumDueBefore(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	repo := NewInstallmentRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "52998224725"})
+	assert.NoError(t, err)
+	otherAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "11144477735"})
+	assert.NoError(t, err)
+
+	for _, accountID := range []int64{createdAccount.ID, otherAccount.ID} {
+		transaction, err := transactionRepo.Create(context.Background(), &model.Transaction{
+			AccountID:       accountID,
+			Amount:          model.MustParseMoney("-300.00"),
+			Balance:         model.MustParseMoney("-300.00"),
+			TransactionDate: time.Date(2024, time.September, 10, 0, 0, 0, 0, time.UTC),
+			OperationType:   model.InstallmentPurchase,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(context.Background(), model.NewInstallments(transaction, 3)))
+	}
+
+	start := time.Date(2024, time.October, 26, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, time.November, 26, 0, 0, 0, 0, time.UTC)
+
+	installments, err := repo.FindByAccountIdDueBetween(context.Background(), createdAccount.ID, start, end)
+	assert.NoError(t, err)
+	assert.Len(t, installments, 1)
+	assert.Equal(t, 2, installments[0].Number)
+	assert.Equal(t, model.MustParseMoney("100.00"), installments[0].Amount)
+
+	sum, err := repo.SumDueBefore(context.Background(), createdAccount.ID, end)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("200.00"), sum)
+
+	sum, err = repo.SumDueBefore(context.Background(), createdAccount.ID, start.AddDate(0, -1, 0))
+	assert.NoError(t, err)
+	assert.Zero(t, sum)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gmerten/accounts_transactions/internal/ledger"
+	"gorm.io/gorm"
+)
+
+type LedgerRepository interface {
+	CreateJournal(ctx context.Context, journal *ledger.Journal) error
+	FindJournals(ctx context.Context, afterID int64, limit int) ([]ledger.Journal, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db}
+}
+
+// CreateJournal saves the journal along with its entries.
+func (r *ledgerRepository) CreateJournal(ctx context.Context, journal *ledger.Journal) error {
+	return r.db.WithContext(ctx).Create(journal).Error
+}
+
+// FindJournals returns up to limit journals with an ID above afterID, in ID
+// order and with their entries, so the whole ledger can be scanned in batches.
+func (r *ledgerRepository) FindJournals(ctx context.Context, afterID int64, limit int) ([]ledger.Journal, error) {
+	var journals []ledger.Journal
+	err := r.db.WithContext(ctx).Preload("Entries").
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&journals).Error
+	if err != nil {
+		return nil, err
+	}
+	return journals, nil
+}
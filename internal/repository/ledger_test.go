@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/ledger"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerRepository_CreateJournalAndFindJournals(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewLedgerRepository(db)
+
+	account := &model.Account{DocumentNumber: "12345678909"}
+	assert.NoError(t, db.Create(account).Error)
+
+	transactions := make([]*model.Transaction, 0, 3)
+	for i := 0; i < 3; i++ {
+		transaction := &model.Transaction{
+			AccountID:       account.ID,
+			Amount:          model.MustParseMoney("-10.00"),
+			OperationType:   model.Purchase,
+			TransactionDate: time.Now(),
+		}
+		assert.NoError(t, db.Create(transaction).Error)
+		transactions = append(transactions, transaction)
+
+		journal := ledger.NewTransactionJournal(transaction)
+		assert.NoError(t, repo.CreateJournal(context.Background(), journal))
+		assert.NotZero(t, journal.ID)
+	}
+
+	duplicate := ledger.NewTransactionJournal(&model.Transaction{ID: transactions[0].ID, AccountID: account.ID, Amount: model.MustParseMoney("5.00"), OperationType: model.Payment})
+	assert.Error(t, repo.CreateJournal(context.Background(), duplicate))
+
+	journals, err := repo.FindJournals(context.Background(), 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, journals, 2)
+	assert.Len(t, journals[0].Entries, 2)
+	assert.Equal(t, ledger.CustomerAccount(account.ID), journals[0].Entries[0].LedgerAccount)
+	assert.Equal(t, model.MustParseMoney("10.00"), journals[0].Entries[0].Amount)
+	assert.Equal(t, ledger.MerchantSettlement, journals[0].Entries[1].LedgerAccount)
+
+	journals, err = repo.FindJournals(context.Background(), journals[1].ID, 2)
+	assert.NoError(t, err)
+	assert.Len(t, journals, 1)
+	assert.Equal(t, transactions[2].ID, journals[0].TransactionID)
+}
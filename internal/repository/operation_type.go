@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+type OperationTypeRepository interface {
+	Create(ctx context.Context, operationType *model.OperationTypeDefinition) (*model.OperationTypeDefinition, error)
+	FindById(ctx context.Context, id model.OperationType) (*model.OperationTypeDefinition, error)
+	FindAll(ctx context.Context) ([]model.OperationTypeDefinition, error)
+}
+
+type operationTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewOperationTypeRepository(db *gorm.DB) OperationTypeRepository {
+	return &operationTypeRepository{db}
+}
+
+func (r *operationTypeRepository) Create(ctx context.Context, operationType *model.OperationTypeDefinition) (*model.OperationTypeDefinition, error) {
+	if err := r.db.WithContext(ctx).Create(operationType).Error; err != nil {
+		return nil, err
+	}
+	return operationType, nil
+}
+
+func (r *operationTypeRepository) FindById(ctx context.Context, id model.OperationType) (*model.OperationTypeDefinition, error) {
+	var operationType model.OperationTypeDefinition
+	if err := r.db.WithContext(ctx).First(&operationType, id).Error; err != nil {
+		return nil, err
+	}
+	return &operationType, nil
+}
+
+func (r *operationTypeRepository) FindAll(ctx context.Context) ([]model.OperationTypeDefinition, error) {
+	var operationTypes []model.OperationTypeDefinition
+	if err := r.db.WithContext(ctx).Order("id").Find(&operationTypes).Error; err != nil {
+		return nil, err
+	}
+	return operationTypes, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOperationTypeRepository_CreateAndFind(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewOperationTypeRepository(db)
+
+	for _, builtIn := range model.BuiltInOperationTypes {
+		operationType := builtIn
+		_, err := repo.Create(context.Background(), &operationType)
+		assert.NoError(t, err)
+	}
+
+	createdOperationType, err := repo.Create(context.Background(), &model.OperationTypeDefinition{
+		Description: "Retired fee",
+		Sign:        model.Debit,
+		Active:      false,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, model.OperationType(5), createdOperationType.ID)
+
+	_, err = repo.Create(context.Background(), &model.OperationTypeDefinition{ID: model.Payment, Description: "Duplicated", Sign: model.Credit})
+	assert.Error(t, err)
+
+	foundOperationType, err := repo.FindById(context.Background(), createdOperationType.ID)
+	assert.NoError(t, err)
+	assert.False(t, foundOperationType.Active)
+	assert.Equal(t, model.Debit, foundOperationType.Sign)
+
+	_, err = repo.FindById(context.Background(), 99)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	operationTypes, err := repo.FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, operationTypes, 5)
+	assert.Equal(t, model.Purchase, operationTypes[0].ID)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+const maxOutboxErrorLength = 1024
+
+type OutboxRepository interface {
+	Append(ctx context.Context, message *model.OutboxMessage) error
+	FindPending(ctx context.Context, limit int) ([]model.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, lastError string) error
+	MarkDeadLettered(ctx context.Context, id int64, lastError string, deadLetteredAt time.Time) error
+	Redrive(ctx context.Context) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db}
+}
+
+func (r *outboxRepository) Append(ctx context.Context, message *model.OutboxMessage) error {
+	return r.db.WithContext(ctx).Create(message).Error
+}
+
+// FindPending returns up to limit pending messages in the order they were
+// written. Accounts whose earliest pending message failed before only have
+// that message returned, so accounts that keep failing cannot fill the batch
+// and hold back the other ones. Accounts with a dead lettered message have
+// none returned until it is redriven, so their events stay in order.
+func (r *outboxRepository) FindPending(ctx context.Context, limit int) ([]model.OutboxMessage, error) {
+	pending := "published_at IS NULL AND dead_lettered_at IS NULL"
+	failing := r.db.Model(&model.OutboxMessage{}).Select("account_id").Where(pending + " AND attempts > 0")
+	heads := r.db.Model(&model.OutboxMessage{}).Select("MIN(id)").Where(pending).Group("account_id")
+	deadLettered := r.db.Model(&model.OutboxMessage{}).Select("account_id").Where("published_at IS NULL AND dead_lettered_at IS NOT NULL")
+
+	var messages []model.OutboxMessage
+	err := r.db.WithContext(ctx).Where(pending).
+		Where("account_id NOT IN (?)", deadLettered).
+		Where(r.db.Where("account_id NOT IN (?)", failing).Or("id IN (?)", heads)).
+		Order("id").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"published_at": publishedAt,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   "",
+	}).Error
+}
+
+// MarkFailed counts a failed delivery, keeping the start of its error.
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, lastError string) error {
+	return r.db.WithContext(ctx).Model(&model.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": truncateOutboxError(lastError),
+	}).Error
+}
+
+// MarkDeadLettered counts the last failed delivery of a message and sets it
+// aside. The later messages of its account wait until it is redriven.
+func (r *outboxRepository) MarkDeadLettered(ctx context.Context, id int64, lastError string, deadLetteredAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":         gorm.Expr("attempts + 1"),
+		"last_error":       truncateOutboxError(lastError),
+		"dead_lettered_at": deadLetteredAt,
+	}).Error
+}
+
+// Redrive moves the dead lettered messages back to pending with a fresh set
+// of attempts, which lets the messages of their accounts through again, and
+// returns how many moved.
+func (r *outboxRepository) Redrive(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.OutboxMessage{}).
+		Where("published_at IS NULL AND dead_lettered_at IS NOT NULL").
+		Updates(map[string]interface{}{
+			"dead_lettered_at": nil,
+			"attempts":         0,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func truncateOutboxError(lastError string) string {
+	if len(lastError) > maxOutboxErrorLength {
+		return lastError[:maxOutboxErrorLength]
+	}
+	return lastError
+}
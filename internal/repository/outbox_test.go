@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository_AppendAndFindPending(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewOutboxRepository(db)
+
+	for _, accountID := range []int64{1, 2, 1} {
+		message, err := model.NewOutboxMessage(&model.AccountCreated{AccountID: accountID}, time.Now())
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Append(context.Background(), message))
+	}
+
+	pending, err := repo.FindPending(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 3)
+	assert.Equal(t, []int64{1, 2, 1}, []int64{pending[0].AccountID, pending[1].AccountID, pending[2].AccountID})
+	assert.True(t, pending[0].ID < pending[1].ID && pending[1].ID < pending[2].ID)
+
+	assert.NoError(t, repo.MarkFailed(context.Background(), pending[0].ID, "connection refused"))
+	assert.NoError(t, repo.MarkPublished(context.Background(), pending[1].ID, time.Now()))
+
+	pending, err = repo.FindPending(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, "connection refused", pending[0].LastError)
+
+	// The later message of account 1 waits for its failed head.
+	pending, err = repo.FindPending(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, int64(1), pending[0].AccountID)
+}
+
+func TestOutboxRepository_FindPendingHoldsBackFailingAccounts(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewOutboxRepository(db)
+
+	for _, accountID := range []int64{1, 1, 1, 2, 2} {
+		message, err := model.NewOutboxMessage(&model.AccountCreated{AccountID: accountID}, time.Now())
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Append(context.Background(), message))
+	}
+
+	pending, err := repo.FindPending(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 5)
+
+	// Only the failed head of account 1 is returned, so it leaves room for
+	// account 2 even in a batch of 2.
+	assert.NoError(t, repo.MarkFailed(context.Background(), pending[0].ID, "connection refused"))
+
+	pending, err = repo.FindPending(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, []int64{1, 2}, []int64{pending[0].AccountID, pending[1].AccountID})
+	assert.Equal(t, 1, pending[0].Attempts)
+
+	// Dead lettering the head holds back the later messages of account 1.
+	assert.NoError(t, repo.MarkDeadLettered(context.Background(), pending[0].ID, "invalid payload", time.Now()))
+
+	pending, err = repo.FindPending(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 2}, []int64{pending[0].AccountID, pending[1].AccountID})
+
+	var deadLettered model.OutboxMessage
+	assert.NoError(t, db.Where("dead_lettered_at IS NOT NULL").First(&deadLettered).Error)
+	assert.Equal(t, 2, deadLettered.Attempts)
+	assert.Equal(t, "invalid payload", deadLettered.LastError)
+}
+
+func TestOutboxRepository_Redrive(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewOutboxRepository(db)
+
+	for _, accountID := range []int64{1, 1, 2} {
+		message, err := model.NewOutboxMessage(&model.AccountCreated{AccountID: accountID}, time.Now())
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Append(context.Background(), message))
+	}
+
+	pending, err := repo.FindPending(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.MarkDeadLettered(context.Background(), pending[0].ID, "invalid payload", time.Now()))
+	assert.NoError(t, repo.MarkPublished(context.Background(), pending[2].ID, time.Now()))
+
+	redriven, err := repo.Redrive(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), redriven)
+
+	// The redriven head is relayed again, ahead of the message it held back.
+	pending, err = repo.FindPending(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, []int64{1, 1}, []int64{pending[0].AccountID, pending[1].AccountID})
+	assert.Zero(t, pending[0].Attempts)
+	assert.Nil(t, pending[0].DeadLetteredAt)
+	assert.Equal(t, "invalid payload", pending[0].LastError)
+
+	redriven, err = repo.Redrive(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, redriven)
+}
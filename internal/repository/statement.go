@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+type StatementRepository interface {
+	Create(ctx context.Context, statement *model.Statement) (*model.Statement, error)
+	FindByAccountIdAndPeriod(ctx context.Context, accountID int64, period string) (*model.Statement, error)
+	FindByAccountId(ctx context.Context, accountID int64) ([]model.Statement, error)
+	FindLatestByAccountId(ctx context.Context, accountID int64) (*model.Statement, error)
+}
+
+type statementRepository struct {
+	db *gorm.DB
+}
+
+func NewStatementRepository(db *gorm.DB) StatementRepository {
+	return &statementRepository{db}
+}
+
+func (r *statementRepository) Create(ctx context.Context, statement *model.Statement) (*model.Statement, error) {
+	if err := r.db.WithContext(ctx).Create(statement).Error; err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+func (r *statementRepository) FindByAccountIdAndPeriod(ctx context.Context, accountID int64, period string) (*model.Statement, error) {
+	var statement model.Statement
+	if err := r.db.WithContext(ctx).Where("account_id = ? AND period = ?", accountID, period).First(&statement).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// FindByAccountId returns the closed statements of the account, newest first.
+func (r *statementRepository) FindByAccountId(ctx context.Context, accountID int64) ([]model.Statement, error) {
+	var statements []model.Statement
+	if err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("period DESC").Find(&statements).Error; err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+func (r *statementRepository) FindLatestByAccountId(ctx context.Context, accountID int64) (*model.Statement, error) {
+	var statement model.Statement
+	if err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("period DESC").First(&statement).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
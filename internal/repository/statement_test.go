@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestStatementRepository_CreateAndFind(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	repo := NewStatementRepository(db)
+
+	account, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "52998224725", ClosingDay: 25, DueDay: 5})
+	assert.NoError(t, err)
+
+	closedAt := time.Now()
+	for _, period := range []string{"2024-08", "2024-09"} {
+		month, _ := model.ParsePeriod(period)
+		statement := model.NewStatement(account, month, model.MustParseMoney("-10.00"), nil, nil)
+		statement.ClosedAt = &closedAt
+
+		_, err = repo.Create(context.Background(), statement)
+		assert.NoError(t, err)
+	}
+
+	duplicate, _ := model.ParsePeriod("2024-09")
+	_, err = repo.Create(context.Background(), model.NewStatement(account, duplicate, 0, nil, nil))
+	assert.Error(t, err)
+
+	statement, err := repo.FindByAccountIdAndPeriod(context.Background(), account.ID, "2024-08")
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("-10.00"), statement.ClosingBalance)
+	assert.Equal(t, model.MustParseMoney("10.00"), statement.MinimumPayment)
+	assert.True(t, statement.IsClosed())
+
+	_, err = repo.FindByAccountIdAndPeriod(context.Background(), account.ID, "2024-10")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	statements, err := repo.FindByAccountId(context.Background(), account.ID)
+	assert.NoError(t, err)
+	assert.Len(t, statements, 2)
+	assert.Equal(t, "2024-09", statements[0].Period)
+
+	latest, err := repo.FindLatestByAccountId(context.Background(), account.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-09", latest.Period)
+}
@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/gmerten/accounts_transactions/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type transactionRepository struct {
@@ -10,16 +14,137 @@ type transactionRepository struct {
 }
 
 type TransactionRepository interface {
-	Create(transaction *model.Transaction) (*model.Transaction, error)
+	Create(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error)
+	FindById(ctx context.Context, id int64) (*model.Transaction, error)
+	FindByIdForUpdate(ctx context.Context, id int64) (*model.Transaction, error)
+	FindByAccountId(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error)
+	FindOpenDebitsForUpdate(ctx context.Context, accountID int64) ([]model.Transaction, error)
+	FindByAccountIdBetween(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Transaction, error)
+	SumBilledAmountsBefore(ctx context.Context, accountID int64, before time.Time) (model.Money, error)
+	UpdateBalance(ctx context.Context, id int64, balance model.Money) error
+	UpdateReversal(ctx context.Context, id int64, reversedAmount model.Money, status model.TransactionStatus) error
 }
 
 func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &transactionRepository{db}
 }
 
-func (r *transactionRepository) Create(transaction *model.Transaction) (*model.Transaction, error) {
-	if err := r.db.Create(transaction).Error; err != nil {
+func (r *transactionRepository) Create(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	if err := r.db.WithContext(ctx).Create(transaction).Error; err != nil {
 		return nil, err
 	}
 	return transaction, nil
 }
+
+func (r *transactionRepository) FindById(ctx context.Context, id int64) (*model.Transaction, error) {
+	var transaction model.Transaction
+	if err := r.db.WithContext(ctx).First(&transaction, id).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *transactionRepository) FindByIdForUpdate(ctx context.Context, id int64) (*model.Transaction, error) {
+	var transaction model.Transaction
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// FindByAccountId returns up to filter.Limit transactions of the account that
+// match the filter, ordered by date and id and starting after filter.After.
+func (r *transactionRepository) FindByAccountId(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	query := r.db.WithContext(ctx).Where("account_id = ?", filter.AccountID)
+
+	if len(filter.OperationTypes) > 0 {
+		query = query.Where("operation_type IN ?", filter.OperationTypes)
+	}
+	if filter.From != nil {
+		query = query.Where("transaction_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("transaction_date <= ?", *filter.To)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("(amount >= ? OR amount <= ?)", *filter.MinAmount, -*filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ? AND amount >= ?", *filter.MaxAmount, -*filter.MaxAmount)
+	}
+
+	direction, comparison := "DESC", "<"
+	if filter.Sort == model.SortAscending {
+		direction, comparison = "ASC", ">"
+	}
+
+	if filter.After != nil {
+		query = query.Where(
+			"(transaction_date "+comparison+" ? OR (transaction_date = ? AND id "+comparison+" ?))",
+			filter.After.TransactionDate, filter.After.TransactionDate, filter.After.ID,
+		)
+	}
+
+	var transactions []model.Transaction
+	err := query.
+		Order("transaction_date " + direction).
+		Order("id " + direction).
+		Limit(filter.Limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// FindOpenDebitsForUpdate returns the account transactions that still have a
+// negative balance, oldest first, locking them until the database transaction ends.
+func (r *transactionRepository) FindOpenDebitsForUpdate(ctx context.Context, accountID int64) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id = ? AND balance < 0", accountID).
+		Order("transaction_date, id").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// FindByAccountIdBetween returns the account transactions dated in [from, to),
+// oldest first.
+func (r *transactionRepository) FindByAccountIdBetween(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).
+		Where("account_id = ? AND transaction_date >= ? AND transaction_date < ?", accountID, from, to).
+		Order("transaction_date, id").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// SumBilledAmountsBefore returns the sum of the account transactions dated
+// before, leaving out installment purchases, which are billed through their
+// installments.
+func (r *transactionRepository) SumBilledAmountsBefore(ctx context.Context, accountID int64, before time.Time) (model.Money, error) {
+	var sum model.Money
+	err := r.db.WithContext(ctx).Model(&model.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_id = ? AND transaction_date < ?", accountID, before).
+		Where("NOT (operation_type = ? AND reversal_of_id IS NULL)", model.InstallmentPurchase).
+		Scan(&sum).Error
+	return sum, err
+}
+
+func (r *transactionRepository) UpdateBalance(ctx context.Context, id int64, balance model.Money) error {
+	return r.db.WithContext(ctx).Model(&model.Transaction{}).Where("id = ?", id).Update("balance", balance).Error
+}
+
+func (r *transactionRepository) UpdateReversal(ctx context.Context, id int64, reversedAmount model.Money, status model.TransactionStatus) error {
+	return r.db.WithContext(ctx).Model(&model.Transaction{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"reversed_amount": reversedAmount,
+		"status":          status,
+	}).Error
+}
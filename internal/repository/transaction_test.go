@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -19,19 +20,19 @@ func TestTransactionRepository_Create(t *testing.T) {
 		DocumentNumber: "123456780",
 	}
 
-	createdAccount, err := accountRepo.Create(account)
+	createdAccount, err := accountRepo.Create(context.Background(), account)
 
 	assert.NoError(t, err)
 	assert.NotZero(t, createdAccount.ID)
 
 	transaction := &model.Transaction{
 		AccountID:       createdAccount.ID,
-		Amount:          1000,
+		Amount:          model.MustParseMoney("1000.00"),
 		TransactionDate: time.Now(),
 		OperationType:   model.Purchase,
 	}
 
-	createdTransaction, err := repo.Create(transaction)
+	createdTransaction, err := repo.Create(context.Background(), transaction)
 
 	assert.NoError(t, err)
 	assert.NotZero(t, createdTransaction.ID)
@@ -46,11 +47,266 @@ func TestTransactionRepository_CreateError(t *testing.T) {
 
 	transaction := &model.Transaction{
 		AccountID:       999,
-		Amount:          1000,
+		Amount:          model.MustParseMoney("1000.00"),
 		TransactionDate: time.Now(),
 		OperationType:   model.Purchase,
 	}
 
-	_, err := repo.Create(transaction)
+	_, err := repo.Create(context.Background(), transaction)
 	assert.Error(t, err)
 }
+
+func TestTransactionRepository_FindOpenDebitsForUpdate(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	repo := NewTransactionRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456780"})
+	assert.NoError(t, err)
+
+	otherAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "987654321"})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	transactions := []*model.Transaction{
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("-20.00"), Balance: model.MustParseMoney("-20.00"), TransactionDate: now, OperationType: model.Purchase},
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("-10.00"), Balance: model.MustParseMoney("-10.00"), TransactionDate: now.Add(-time.Hour), OperationType: model.Withdrawal},
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("-30.00"), Balance: model.MustParseMoney("0.00"), TransactionDate: now.Add(-2 * time.Hour), OperationType: model.Purchase},
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("50.00"), Balance: model.MustParseMoney("50.00"), TransactionDate: now.Add(-3 * time.Hour), OperationType: model.Payment},
+		{AccountID: otherAccount.ID, Amount: model.MustParseMoney("-5.00"), Balance: model.MustParseMoney("-5.00"), TransactionDate: now.Add(-4 * time.Hour), OperationType: model.Purchase},
+	}
+	for _, transaction := range transactions {
+		_, err = repo.Create(context.Background(), transaction)
+		assert.NoError(t, err)
+	}
+
+	openDebits, err := repo.FindOpenDebitsForUpdate(context.Background(), createdAccount.ID)
+
+	assert.NoError(t, err)
+	assert.Len(t, openDebits, 2)
+	assert.Equal(t, transactions[1].ID, openDebits[0].ID)
+	assert.Equal(t, transactions[0].ID, openDebits[1].ID)
+}
+
+func TestTransactionRepository_UpdateBalance(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	repo := NewTransactionRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456780"})
+	assert.NoError(t, err)
+
+	createdTransaction, err := repo.Create(context.Background(), &model.Transaction{
+		AccountID:       createdAccount.ID,
+		Amount:          model.MustParseMoney("-20.00"),
+		Balance:         model.MustParseMoney("-20.00"),
+		TransactionDate: time.Now(),
+		OperationType:   model.Purchase,
+	})
+	assert.NoError(t, err)
+
+	err = repo.UpdateBalance(context.Background(), createdTransaction.ID, model.MustParseMoney("-5.00"))
+	assert.NoError(t, err)
+
+	openDebits, err := repo.FindOpenDebitsForUpdate(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Len(t, openDebits, 1)
+	assert.Equal(t, model.MustParseMoney("-5.00"), openDebits[0].Balance)
+}
+
+func TestTransactionRepository_UpdateReversal(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	repo := NewTransactionRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456781"})
+	assert.NoError(t, err)
+
+	original, err := repo.Create(context.Background(), &model.Transaction{
+		AccountID:       createdAccount.ID,
+		Amount:          model.MustParseMoney("-20.00"),
+		Balance:         model.MustParseMoney("-20.00"),
+		TransactionDate: time.Now(),
+		OperationType:   model.Purchase,
+	})
+	assert.NoError(t, err)
+
+	reversal, err := repo.Create(context.Background(), &model.Transaction{
+		AccountID:       createdAccount.ID,
+		Amount:          model.MustParseMoney("5.00"),
+		ReversalOfID:    &original.ID,
+		TransactionDate: time.Now(),
+		OperationType:   model.Purchase,
+	})
+	assert.NoError(t, err)
+
+	err = repo.UpdateReversal(context.Background(), original.ID, model.MustParseMoney("5.00"), model.PartiallyReversed)
+	assert.NoError(t, err)
+
+	foundOriginal, err := repo.FindById(context.Background(), original.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("5.00"), foundOriginal.ReversedAmount)
+	assert.Equal(t, model.PartiallyReversed, foundOriginal.Status)
+	assert.Nil(t, foundOriginal.ReversalOfID)
+
+	foundReversal, err := repo.FindByIdForUpdate(context.Background(), reversal.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Posted, foundReversal.Status)
+	assert.Equal(t, original.ID, *foundReversal.ReversalOfID)
+}
+
+func TestTransactionRepository_FindByAccountId(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	repo := NewTransactionRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456780"})
+	assert.NoError(t, err)
+
+	otherAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "987654321"})
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 9, 1, 10, 0, 0, 0, time.Local)
+	transactions := []*model.Transaction{
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("-10.00"), TransactionDate: start, OperationType: model.Purchase},
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("-25.50"), TransactionDate: start.Add(time.Hour), OperationType: model.Withdrawal},
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("40.00"), TransactionDate: start.Add(time.Hour), OperationType: model.Payment},
+		{AccountID: createdAccount.ID, Amount: model.MustParseMoney("-99.99"), TransactionDate: start.AddDate(0, 0, 2), OperationType: model.Purchase},
+		{AccountID: otherAccount.ID, Amount: model.MustParseMoney("-10.00"), TransactionDate: start, OperationType: model.Purchase},
+	}
+	for _, transaction := range transactions {
+		_, err = repo.Create(context.Background(), transaction)
+		assert.NoError(t, err)
+	}
+
+	ids := func(found []model.Transaction) []int64 {
+		result := make([]int64, 0, len(found))
+		for _, transaction := range found {
+			result = append(result, transaction.ID)
+		}
+		return result
+	}
+
+	found, err := repo.FindByAccountId(context.Background(), model.TransactionFilter{AccountID: createdAccount.ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{transactions[3].ID, transactions[2].ID, transactions[1].ID, transactions[0].ID}, ids(found))
+
+	found, err = repo.FindByAccountId(context.Background(), model.TransactionFilter{
+		AccountID:      createdAccount.ID,
+		OperationTypes: []model.OperationType{model.Purchase, model.Payment},
+		Sort:           model.SortAscending,
+		Limit:          10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{transactions[0].ID, transactions[2].ID, transactions[3].ID}, ids(found))
+
+	from, to := start.Add(time.Minute), start.AddDate(0, 0, 1)
+	found, err = repo.FindByAccountId(context.Background(), model.TransactionFilter{AccountID: createdAccount.ID, From: &from, To: &to, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{transactions[2].ID, transactions[1].ID}, ids(found))
+
+	minAmount, maxAmount := model.MustParseMoney("20.00"), model.MustParseMoney("50.00")
+	found, err = repo.FindByAccountId(context.Background(), model.TransactionFilter{AccountID: createdAccount.ID, MinAmount: &minAmount, MaxAmount: &maxAmount, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{transactions[2].ID, transactions[1].ID}, ids(found))
+}
+
+func TestTransactionRepository_FindByAccountIdCursor(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	repo := NewTransactionRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456780"})
+	assert.NoError(t, err)
+
+	date := time.Date(2024, 9, 1, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		_, err = repo.Create(context.Background(), &model.Transaction{
+			AccountID:       createdAccount.ID,
+			Amount:          model.MustParseMoney("-1.00"),
+			TransactionDate: date,
+			OperationType:   model.Purchase,
+		})
+		assert.NoError(t, err)
+	}
+
+	firstPage, err := repo.FindByAccountId(context.Background(), model.TransactionFilter{AccountID: createdAccount.ID, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, firstPage, 2)
+
+	_, err = repo.Create(context.Background(), &model.Transaction{
+		AccountID:       createdAccount.ID,
+		Amount:          model.MustParseMoney("-1.00"),
+		TransactionDate: date.Add(time.Hour),
+		OperationType:   model.Purchase,
+	})
+	assert.NoError(t, err)
+
+	last := firstPage[1]
+	secondPage, err := repo.FindByAccountId(context.Background(), model.TransactionFilter{
+		AccountID: createdAccount.ID,
+		Limit:     2,
+		After:     &model.TransactionCursor{TransactionDate: last.TransactionDate, ID: last.ID},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, secondPage, 1)
+	assert.Less(t, secondPage[0].ID, last.ID)
+}
+
+func TestTransactionRepository_FindByAccountIdBetweenAndSumBilledAmountsBefore(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	repo := NewTransactionRepository(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456782"})
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.August, 26, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, time.September, 26, 0, 0, 0, 0, time.UTC)
+
+	for _, posting := range []struct {
+		amount        string
+		date          time.Time
+		operationType model.OperationType
+	}{
+		{"-10.00", start.Add(-time.Second), model.Purchase},
+		{"-300.00", start.Add(-time.Second), model.InstallmentPurchase},
+		{"-20.00", start, model.Purchase},
+		{"5.50", end.Add(-time.Second), model.Payment},
+		{"-40.00", end, model.Purchase},
+	} {
+		_, err = repo.Create(context.Background(), &model.Transaction{
+			AccountID:       createdAccount.ID,
+			Amount:          model.MustParseMoney(posting.amount),
+			TransactionDate: posting.date,
+			OperationType:   posting.operationType,
+		})
+		assert.NoError(t, err)
+	}
+
+	transactions, err := repo.FindByAccountIdBetween(context.Background(), createdAccount.ID, start, end)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, model.MustParseMoney("-20.00"), transactions[0].Amount)
+	assert.Equal(t, model.MustParseMoney("5.50"), transactions[1].Amount)
+
+	sum, err := repo.SumBilledAmountsBefore(context.Background(), createdAccount.ID, end)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("-24.50"), sum)
+
+	sum, err = repo.SumBilledAmountsBefore(context.Background(), createdAccount.ID, start.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, sum)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories groups the repositories bound to a single database transaction.
+type Repositories struct {
+	Accounts     AccountRepository
+	Transactions TransactionRepository
+	Installments InstallmentRepository
+	Statements   StatementRepository
+	Ledger       LedgerRepository
+	Outbox       OutboxRepository
+}
+
+// Transactor runs a unit of work inside a database transaction. The work is
+// committed when fn returns nil and rolled back otherwise.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(repositories Repositories) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(repositories Repositories) error) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			Accounts:     NewAccountRepository(tx),
+			Transactions: NewTransactionRepository(tx),
+			Installments: NewInstallmentRepository(tx),
+			Statements:   NewStatementRepository(tx),
+			Ledger:       NewLedgerRepository(tx),
+			Outbox:       NewOutboxRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactor_WithinTransactionCommit(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	transactor := NewTransactor(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456"})
+	assert.NoError(t, err)
+
+	err = transactor.WithinTransaction(context.Background(), func(repositories Repositories) error {
+		_, err := repositories.Transactions.Create(context.Background(), &model.Transaction{
+			AccountID:       createdAccount.ID,
+			Amount:          model.MustParseMoney("-10.00"),
+			TransactionDate: time.Now(),
+			OperationType:   model.Purchase,
+		})
+		if err != nil {
+			return err
+		}
+		return repositories.Accounts.UpdateBalance(context.Background(), createdAccount.ID, model.MustParseMoney("-10.00"))
+	})
+	assert.NoError(t, err)
+
+	foundAccount, err := accountRepo.FindById(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("-10.00"), foundAccount.Balance)
+}
+
+func TestTransactor_WithinTransactionRollback(t *testing.T) {
+
+	ResetTestDB()
+
+	accountRepo := NewAccountRepository(db)
+	transactor := NewTransactor(db)
+
+	createdAccount, err := accountRepo.Create(context.Background(), &model.Account{DocumentNumber: "123456"})
+	assert.NoError(t, err)
+
+	err = transactor.WithinTransaction(context.Background(), func(repositories Repositories) error {
+		if err := repositories.Accounts.UpdateBalance(context.Background(), createdAccount.ID, model.MustParseMoney("-10.00")); err != nil {
+			return err
+		}
+		return errors.New("forced rollback")
+	})
+	assert.Error(t, err)
+
+	foundAccount, err := accountRepo.FindById(context.Background(), createdAccount.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, foundAccount.Balance)
+}
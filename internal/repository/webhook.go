@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	FindById(ctx context.Context, id int64) (*model.WebhookSubscription, error)
+	FindAll(ctx context.Context) ([]model.WebhookSubscription, error)
+	FindActive(ctx context.Context) ([]model.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *model.WebhookSubscription) error
+	Delete(ctx context.Context, id int64) (int64, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if err := r.db.WithContext(ctx).Create(subscription).Error; err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *webhookRepository) FindById(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	if err := r.db.WithContext(ctx).First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) FindAll(ctx context.Context) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	if err := r.db.WithContext(ctx).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) FindActive(ctx context.Context) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Update saves the URL, event types and active flag of the subscription.
+func (r *webhookRepository) Update(ctx context.Context, subscription *model.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Model(&model.WebhookSubscription{}).Where("id = ?", subscription.ID).Updates(map[string]interface{}{
+		"url":         subscription.URL,
+		"event_types": subscription.EventTypes,
+		"active":      subscription.Active,
+	}).Error
+}
+
+// Delete removes the subscription, and its deliveries with it, and returns how
+// many subscriptions were deleted.
+func (r *webhookRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&model.WebhookSubscription{}, id)
+	return result.RowsAffected, result.Error
+}
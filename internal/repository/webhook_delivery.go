@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"gorm.io/gorm"
+)
+
+const maxDeliveryErrorLength = 1024
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error)
+	FindDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	FindBySubscriptionId(ctx context.Context, subscriptionID int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error)
+	UpdateAttempt(ctx context.Context, delivery *model.WebhookDelivery) error
+	Redrive(ctx context.Context, subscriptionID int64, now time.Time) (int64, error)
+}
+
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db}
+}
+
+func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	if err := r.db.WithContext(ctx).Omit("Subscription").Create(delivery).Error; err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// FindDue returns up to limit pending deliveries whose next attempt is due,
+// oldest first.
+func (r *webhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindBySubscriptionId returns the latest deliveries of the subscription,
+// newest first, only those in status when it is not empty.
+func (r *webhookDeliveryRepository) FindBySubscriptionId(ctx context.Context, subscriptionID int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error) {
+	query := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateAttempt saves the outcome of a delivery attempt.
+func (r *webhookDeliveryRepository) UpdateAttempt(ctx context.Context, delivery *model.WebhookDelivery) error {
+	lastError := delivery.LastError
+	if len(lastError) > maxDeliveryErrorLength {
+		lastError = lastError[:maxDeliveryErrorLength]
+	}
+
+	return r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       lastError,
+		"delivered_at":     delivery.DeliveredAt,
+	}).Error
+}
+
+// Redrive moves the dead lettered deliveries of the subscription back to
+// pending with a fresh set of attempts, due now, and returns how many moved.
+func (r *webhookDeliveryRepository) Redrive(ctx context.Context, subscriptionID int64, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).
+		Where("subscription_id = ? AND status = ?", subscriptionID, model.DeliveryDeadLettered).
+		Updates(map[string]interface{}{
+			"status":          model.DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepository_CRUD(t *testing.T) {
+
+	ResetTestDB()
+
+	repo := NewWebhookRepository(db)
+	deliveryRepo := NewWebhookDeliveryRepository(db)
+
+	created, err := repo.Create(context.Background(), &model.WebhookSubscription{
+		URL:        "https://example.com/hooks",
+		Secret:     "secret",
+		EventTypes: model.EventTypes{model.AccountCreatedEvent, model.TransactionPostedEvent},
+		Active:     true,
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.Create(context.Background(), &model.WebhookSubscription{URL: "https://example.com/all", Secret: "secret"})
+	assert.NoError(t, err)
+
+	found, err := repo.FindById(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.EventTypes{model.AccountCreatedEvent, model.TransactionPostedEvent}, found.EventTypes)
+
+	found.URL = "https://example.com/v2/hooks"
+	found.EventTypes = model.EventTypes{model.TransactionPostedEvent}
+	found.Active = false
+	assert.NoError(t, repo.Update(context.Background(), found))
+
+	all, err := repo.FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, "https://example.com/v2/hooks", all[0].URL)
+	assert.Equal(t, model.EventTypes{model.TransactionPostedEvent}, all[0].EventTypes)
+	assert.Empty(t, all[1].EventTypes)
+
+	active, err := repo.FindActive(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, active)
+
+	_, err = deliveryRepo.Create(context.Background(), &model.WebhookDelivery{SubscriptionID: created.ID, OutboxMessageID: 1, EventType: model.TransactionPostedEvent, Payload: "{}", Status: model.DeliveryPending, NextAttemptAt: time.Now()})
+	assert.NoError(t, err)
+
+	deleted, err := repo.Delete(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	deliveries, err := deliveryRepo.FindBySubscriptionId(context.Background(), created.ID, "", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	deleted, err = repo.Delete(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, deleted)
+}
+
+func TestWebhookDeliveryRepository_Lifecycle(t *testing.T) {
+
+	ResetTestDB()
+
+	subscription, err := NewWebhookRepository(db).Create(context.Background(), &model.WebhookSubscription{URL: "https://example.com/hooks", Secret: "secret", Active: true})
+	assert.NoError(t, err)
+
+	repo := NewWebhookDeliveryRepository(db)
+	now := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+
+	for i, nextAttemptAt := range []time.Time{now, now.Add(time.Minute)} {
+		_, err = repo.Create(context.Background(), &model.WebhookDelivery{
+			SubscriptionID:  subscription.ID,
+			OutboxMessageID: int64(i + 1),
+			EventType:       model.AccountCreatedEvent,
+			Payload:         "{}",
+			Status:          model.DeliveryPending,
+			NextAttemptAt:   nextAttemptAt,
+		})
+		assert.NoError(t, err)
+	}
+
+	_, err = repo.Create(context.Background(), &model.WebhookDelivery{SubscriptionID: subscription.ID, OutboxMessageID: 1, EventType: model.AccountCreatedEvent, Payload: "{}", Status: model.DeliveryPending, NextAttemptAt: now})
+	assert.Error(t, err)
+
+	due, err := repo.FindDue(context.Background(), now, 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	delivery := &due[0]
+	delivery.Status = model.DeliveryDeadLettered
+	delivery.Attempts = 5
+	delivery.LastStatusCode = 500
+	delivery.LastError = "endpoint responded with status 500"
+	assert.NoError(t, repo.UpdateAttempt(context.Background(), delivery))
+
+	deadLettered, err := repo.FindBySubscriptionId(context.Background(), subscription.ID, model.DeliveryDeadLettered, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deadLettered, 1)
+	assert.Equal(t, 500, deadLettered[0].LastStatusCode)
+
+	redriven, err := repo.Redrive(context.Background(), subscription.ID, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), redriven)
+
+	due, err = repo.FindDue(context.Background(), now.Add(time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 2)
+	assert.Zero(t, due[0].Attempts)
+
+	all, err := repo.FindBySubscriptionId(context.Background(), subscription.ID, "", 1)
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.Equal(t, int64(2), all[0].OutboxMessageID)
+}
@@ -1,52 +1,145 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/metrics"
 	"github.com/gmerten/accounts_transactions/internal/model"
 	"github.com/gmerten/accounts_transactions/internal/repository"
-	log "github.com/sirupsen/logrus"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
 	"gorm.io/gorm"
 )
 
 type accountService struct {
 	repository repository.AccountRepository
+	transactor repository.Transactor
 }
 
 type AccountService interface {
-	CreateAccount(account *model.Account) (*model.Account, error)
-	GetAccountById(accountId int64) (*model.Account, error)
+	CreateAccount(ctx context.Context, account *model.Account) (*model.Account, error)
+	GetAccountById(ctx context.Context, accountId int64) (*model.Account, error)
+	UpdateAccountStatus(ctx context.Context, accountId int64, status model.AccountStatus) (*model.Account, error)
 }
 
-func NewAccountService(repository repository.AccountRepository) AccountService {
-	return &accountService{repository}
+func NewAccountService(repository repository.AccountRepository, transactor repository.Transactor) AccountService {
+	return &accountService{repository, transactor}
 }
 
-func (a *accountService) CreateAccount(account *model.Account) (*model.Account, error) {
-	account, err := a.repository.Create(account)
+// CreateAccount stores the account under its normalized document number, so
+// differently formatted copies of the same CPF or CNPJ conflict, and queues an
+// AccountCreated event in the same database transaction.
+func (a *accountService) CreateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.CreateAccount")
+	defer span.End()
+
+	documentNumber, documentType, err := model.ParseDocumentNumber(account.DocumentNumber)
+	if err != nil {
+		metrics.ValidationFailed(metrics.AccountService)
+		return nil, internalErrors.NewValidationError(internalErrors.InvalidDocumentNumber, "Document number must be a valid CPF or CNPJ")
+	}
+	account.DocumentNumber = documentNumber
+	account.DocumentType = documentType
+
+	if account.Status == "" {
+		account.Status = model.AccountActive
+	}
+	if account.ClosingDay == 0 {
+		account.ClosingDay = model.DefaultClosingDay
+	}
+	if account.DueDay == 0 {
+		account.DueDay = model.DefaultDueDay
+	}
+
+	err = a.transactor.WithinTransaction(ctx, func(repositories repository.Repositories) error {
+		if _, err := repositories.Accounts.Create(ctx, account); err != nil {
+			return err
+		}
+
+		message, err := model.NewOutboxMessage(model.NewAccountCreated(account), time.Now())
+		if err != nil {
+			return err
+		}
+		return repositories.Outbox.Append(ctx, message)
+	})
 	if err != nil {
-		log.WithError(err).Error("Error saving account")
+		logging.FromContext(ctx).WithError(err).Error("Error saving account")
 		if internalErrors.IsDuplicateKeyError(err) {
-			return nil, internalErrors.NewConflictError("Account with this document number already exists")
+			metrics.Conflict(metrics.AccountService)
+			return nil, internalErrors.NewConflictError(internalErrors.DuplicateDocument, "Account with this document number already exists")
 		}
 
+		tracing.RecordError(span, err)
 		return nil, err
 	}
+
+	span.SetAttributes(tracing.AccountIDKey.Int64(account.ID))
+	logging.AddField(ctx, "accountID", account.ID)
+	metrics.AccountCreated()
 	return account, nil
 }
 
-func (a *accountService) GetAccountById(accountId int64) (*model.Account, error) {
-	account, err := a.repository.FindById(accountId)
+func (a *accountService) GetAccountById(ctx context.Context, accountId int64) (*model.Account, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.GetAccountById", tracing.AccountIDKey.Int64(accountId))
+	defer span.End()
+	logging.AddField(ctx, "accountID", accountId)
+
+	account, err := a.repository.FindById(ctx, accountId)
 
 	if err != nil {
-		log.WithError(err).Error("Error getting account")
+		logging.FromContext(ctx).WithError(err).Error("Error getting account")
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, internalErrors.NewNotFoundError("Account not found")
+			return nil, internalErrors.NewNotFoundError(internalErrors.AccountNotFound, "Account not found")
 		}
+		tracing.RecordError(span, err)
 		return nil, err
 	}
 
 	return account, nil
 
 }
+
+// UpdateAccountStatus moves the account to the given status when the change is
+// allowed. The account row is locked so a closure cannot race with a posting
+// that leaves the balance non-zero.
+func (a *accountService) UpdateAccountStatus(ctx context.Context, accountId int64, status model.AccountStatus) (*model.Account, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.UpdateAccountStatus", tracing.AccountIDKey.Int64(accountId))
+	defer span.End()
+	logging.AddField(ctx, "accountID", accountId)
+
+	var account *model.Account
+
+	err := a.transactor.WithinTransaction(ctx, func(repositories repository.Repositories) error {
+		var err error
+		account, err = lockAccount(ctx, repositories.Accounts, accountId)
+		if err != nil {
+			return err
+		}
+
+		if !account.Status.CanTransitionTo(status) {
+			return internalErrors.NewUnprocessableEntityError(internalErrors.InvalidStatusTransition, "Account cannot change from "+string(account.Status)+" to "+string(status))
+		}
+
+		if status == model.AccountClosed && account.Balance != 0 {
+			return internalErrors.NewUnprocessableEntityError(internalErrors.AccountBalanceNotZero, "Account can only be closed with a zero balance")
+		}
+
+		if account.Status == status {
+			return nil
+		}
+
+		account.Status = status
+		return repositories.Accounts.UpdateStatus(ctx, account.ID, status)
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", accountId).WithError(err).Error("Error updating account status")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return account, nil
+}
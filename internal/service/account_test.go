@@ -1,33 +1,95 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
 	"github.com/gmerten/accounts_transactions/internal/model"
 	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
 func TestAccountService_CreateAccount(t *testing.T) {
 
 	mockRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
 
 	account := &model.Account{
 		ID:             1,
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	mockRepo.On("Create", account).Return(account, nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
 
-	service := NewAccountService(mockRepo)
-	createdAccount, err := service.CreateAccount(account)
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo, outboxRepository: mockOutboxRepo})
+	createdAccount, err := service.CreateAccount(context.Background(), account)
 
 	assert.NoError(t, err)
 	assert.Equal(t, account, createdAccount)
 
 	mockRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+}
+
+func TestAccountService_CreateAccountNormalizesDocumentNumber(t *testing.T) {
+
+	mockRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	mockRepo.On("Create", mock.AnythingOfType("*model.Account")).Return(&model.Account{}, nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo, outboxRepository: mockOutboxRepo})
+
+	account := &model.Account{DocumentNumber: "11.222.333/0001-81"}
+	_, err := service.CreateAccount(context.Background(), account)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "11222333000181", account.DocumentNumber)
+	assert.Equal(t, model.CNPJ, account.DocumentType)
+	assert.Equal(t, model.DefaultClosingDay, account.ClosingDay)
+	assert.Equal(t, model.DefaultDueDay, account.DueDay)
+
+	mockRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+}
+
+func TestAccountService_CreateAccountOutboxError(t *testing.T) {
+
+	mockRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	account := &model.Account{ID: 1, DocumentNumber: "12345678909"}
+
+	mockRepo.On("Create", account).Return(account, nil)
+	mockOutboxRepo.On("Append", mock.MatchedBy(func(message *model.OutboxMessage) bool {
+		return message.EventType == model.AccountCreatedEvent && message.AccountID == 1
+	})).Return(errors.New("error appending to outbox"))
+
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo, outboxRepository: mockOutboxRepo})
+
+	_, err := service.CreateAccount(context.Background(), account)
+	assert.EqualError(t, err, "error appending to outbox")
+
+	mockOutboxRepo.AssertExpectations(t)
+}
+
+func TestAccountService_CreateAccountInvalidDocumentNumberError(t *testing.T) {
+
+	mockRepo := new(MockAccountRepository)
+
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
+
+	_, err := service.CreateAccount(context.Background(), &model.Account{DocumentNumber: "123.456.789-00"})
+
+	assert.ErrorAs(t, err, &internalErrors.ValidationError{})
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
 }
 
 func TestAccountService_CreateAccountGenericError(t *testing.T) {
@@ -36,14 +98,14 @@ func TestAccountService_CreateAccountGenericError(t *testing.T) {
 
 	account := &model.Account{
 		ID:             2,
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	mockRepo.On("Create", account).Return(nil, errors.New("error creating account"))
 
-	service := NewAccountService(mockRepo)
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
 
-	_, err := service.CreateAccount(account)
+	_, err := service.CreateAccount(context.Background(), account)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -55,7 +117,7 @@ func TestAccountService_CreateAccountMySQLDuplicatedKeyError(t *testing.T) {
 
 	account := &model.Account{
 		ID:             2,
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	duplicatedKeyError := &mysql.MySQLError{
@@ -64,28 +126,47 @@ func TestAccountService_CreateAccountMySQLDuplicatedKeyError(t *testing.T) {
 
 	mockRepo.On("Create", account).Return(nil, duplicatedKeyError)
 
-	service := NewAccountService(mockRepo)
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
 
-	_, err := service.CreateAccount(account)
+	_, err := service.CreateAccount(context.Background(), account)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestAccountService_CreateAccountPostgresDuplicatedKeyError(t *testing.T) {
+
+	mockRepo := new(MockAccountRepository)
+
+	account := &model.Account{
+		ID:             2,
+		DocumentNumber: "12345678909",
+	}
+
+	mockRepo.On("Create", account).Return(nil, &pgconn.PgError{Code: "23505"})
+
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
+
+	_, err := service.CreateAccount(context.Background(), account)
+	assert.ErrorAs(t, err, &internalErrors.ConflictError{})
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestAccountService_CreateAccountDBDuplicatedKeyError(t *testing.T) {
 
 	mockRepo := new(MockAccountRepository)
 
 	account := &model.Account{
 		ID:             2,
-		DocumentNumber: "12345678",
+		DocumentNumber: "12345678909",
 	}
 
 	mockRepo.On("Create", account).Return(nil, gorm.ErrDuplicatedKey)
 
-	service := NewAccountService(mockRepo)
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
 
-	_, err := service.CreateAccount(account)
+	_, err := service.CreateAccount(context.Background(), account)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -97,13 +178,13 @@ func TestAccountService_GetAccountById(t *testing.T) {
 
 	account := &model.Account{
 		ID:             1,
-		DocumentNumber: "123435435",
+		DocumentNumber: "12343543577",
 	}
 	mockRepo.On("FindById", int64(1)).Return(account, nil)
 
-	service := NewAccountService(mockRepo)
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
 
-	foundAccount, err := service.GetAccountById(1)
+	foundAccount, err := service.GetAccountById(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, account, foundAccount)
 
@@ -116,9 +197,9 @@ func TestAccountService_GetAccountByIdGenericError(t *testing.T) {
 
 	mockRepo.On("FindById", int64(2)).Return(nil, errors.New("generic error"))
 
-	service := NewAccountService(mockRepo)
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
 
-	_, err := service.GetAccountById(2)
+	_, err := service.GetAccountById(context.Background(), 2)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -130,10 +211,99 @@ func TestAccountService_GetAccountByIdNotFoundError(t *testing.T) {
 
 	mockRepo.On("FindById", int64(2)).Return(nil, gorm.ErrRecordNotFound)
 
-	service := NewAccountService(mockRepo)
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
 
-	_, err := service.GetAccountById(2)
+	_, err := service.GetAccountById(context.Background(), 2)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestAccountService_UpdateAccountStatus(t *testing.T) {
+
+	mockRepo := new(MockAccountRepository)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12343543577",
+		Balance:        model.MustParseMoney("-10.00"),
+		Status:         model.AccountActive,
+	}
+	mockRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("UpdateStatus", int64(1), model.AccountBlocked).Return(nil)
+
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
+
+	updatedAccount, err := service.UpdateAccountStatus(context.Background(), 1, model.AccountBlocked)
+	assert.NoError(t, err)
+	assert.Equal(t, model.AccountBlocked, updatedAccount.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAccountService_UpdateAccountStatusUnchanged(t *testing.T) {
+
+	mockRepo := new(MockAccountRepository)
+
+	account := &model.Account{ID: 1, Status: model.AccountBlocked}
+	mockRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
+
+	updatedAccount, err := service.UpdateAccountStatus(context.Background(), 1, model.AccountBlocked)
+	assert.NoError(t, err)
+	assert.Equal(t, model.AccountBlocked, updatedAccount.Status)
+
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestAccountService_UpdateAccountStatusRejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		account     *model.Account
+		status      model.AccountStatus
+		expectedErr string
+	}{
+		{
+			name:        "reopen closed account",
+			account:     &model.Account{ID: 1, Status: model.AccountClosed},
+			status:      model.AccountActive,
+			expectedErr: "Account cannot change from closed to active",
+		},
+		{
+			name:        "close with outstanding balance",
+			account:     &model.Account{ID: 1, Status: model.AccountBlocked, Balance: model.MustParseMoney("-0.01")},
+			status:      model.AccountClosed,
+			expectedErr: "Account can only be closed with a zero balance",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAccountRepository)
+			mockRepo.On("FindByIdForUpdate", int64(1)).Return(tt.account, nil)
+
+			service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
+
+			_, err := service.UpdateAccountStatus(context.Background(), 1, tt.status)
+			assert.ErrorAs(t, err, &internalErrors.UnprocessableEntityError{})
+			assert.EqualError(t, err, tt.expectedErr)
+
+			mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAccountService_UpdateAccountStatusNotFoundError(t *testing.T) {
+
+	mockRepo := new(MockAccountRepository)
+
+	mockRepo.On("FindByIdForUpdate", int64(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewAccountService(mockRepo, &MockTransactor{accountRepository: mockRepo})
+
+	_, err := service.UpdateAccountStatus(context.Background(), 2, model.AccountClosed)
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/apikey"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type apiClientService struct {
+	repository repository.APIClientRepository
+	now        func() time.Time
+}
+
+// APIClientService manages the clients of the API and authenticates their
+// keys. Keys are only returned when they are issued or rotated, and are never
+// stored or logged.
+type APIClientService interface {
+	IssueClient(ctx context.Context, client *model.APIClient) (*model.APIClient, string, error)
+	GetClient(ctx context.Context, id int64) (*model.APIClient, error)
+	ListClients(ctx context.Context) ([]model.APIClient, error)
+	SetClientActive(ctx context.Context, id int64, active bool) (*model.APIClient, error)
+	RotateKey(ctx context.Context, id int64) (*model.APIClient, string, error)
+	RevokeClient(ctx context.Context, id int64) error
+	Authenticate(ctx context.Context, key string) (*model.APIClient, error)
+}
+
+func NewAPIClientService(repository repository.APIClientRepository) APIClientService {
+	return &apiClientService{repository, time.Now}
+}
+
+// IssueClient saves an active client with a new key, and returns the client
+// along with the key.
+func (a *apiClientService) IssueClient(ctx context.Context, client *model.APIClient) (*model.APIClient, string, error) {
+	ctx, span := tracing.Start(ctx, "APIClientService.IssueClient")
+	defer span.End()
+
+	key, err := apikey.New()
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error generating API key")
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	client.KeyPrefix = apikey.Prefix(key)
+	client.KeyHash = apikey.Hash(key)
+	client.Active = true
+
+	client, err = a.repository.Create(ctx, client)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error saving API client")
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	span.SetAttributes(tracing.APIClientIDKey.Int64(client.ID))
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"apiClientID": client.ID,
+		"keyPrefix":   client.KeyPrefix,
+		"scopes":      client.Scopes,
+	}).Info("API client issued")
+	return client, key, nil
+}
+
+func (a *apiClientService) GetClient(ctx context.Context, id int64) (*model.APIClient, error) {
+	ctx, span := tracing.Start(ctx, "APIClientService.GetClient", tracing.APIClientIDKey.Int64(id))
+	defer span.End()
+
+	client, err := a.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, internalErrors.NewNotFoundError(internalErrors.APIClientNotFound, "API client not found")
+		}
+		logging.FromContext(ctx).WithField("apiClientID", id).WithError(err).Error("Error getting API client")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return client, nil
+}
+
+func (a *apiClientService) ListClients(ctx context.Context) ([]model.APIClient, error) {
+	ctx, span := tracing.Start(ctx, "APIClientService.ListClients")
+	defer span.End()
+
+	clients, err := a.repository.FindAll(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error listing API clients")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return clients, nil
+}
+
+// SetClientActive enables or disables a client. The key of a disabled client
+// is rejected until the client is enabled again.
+func (a *apiClientService) SetClientActive(ctx context.Context, id int64, active bool) (*model.APIClient, error) {
+	ctx, span := tracing.Start(ctx, "APIClientService.SetClientActive", tracing.APIClientIDKey.Int64(id))
+	defer span.End()
+
+	client, err := a.GetClient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Active = active
+	if err = a.repository.Update(ctx, client); err != nil {
+		logging.FromContext(ctx).WithField("apiClientID", id).WithError(err).Error("Error updating API client")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"apiClientID": id,
+		"active":      active,
+	}).Info("API client updated")
+	return client, nil
+}
+
+// RotateKey replaces the key of a client with a new one, which is returned
+// along with the client. The previous key stops working at once.
+func (a *apiClientService) RotateKey(ctx context.Context, id int64) (*model.APIClient, string, error) {
+	ctx, span := tracing.Start(ctx, "APIClientService.RotateKey", tracing.APIClientIDKey.Int64(id))
+	defer span.End()
+
+	client, err := a.GetClient(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := apikey.New()
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error generating API key")
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	rotatedAt := a.now()
+	client.KeyPrefix = apikey.Prefix(key)
+	client.KeyHash = apikey.Hash(key)
+	client.RotatedAt = &rotatedAt
+
+	if err = a.repository.Update(ctx, client); err != nil {
+		logging.FromContext(ctx).WithField("apiClientID", id).WithError(err).Error("Error rotating API key")
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"apiClientID": id,
+		"keyPrefix":   client.KeyPrefix,
+	}).Info("API key rotated")
+	return client, key, nil
+}
+
+// RevokeClient deletes a client, so its key is rejected for good.
+func (a *apiClientService) RevokeClient(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "APIClientService.RevokeClient", tracing.APIClientIDKey.Int64(id))
+	defer span.End()
+
+	deleted, err := a.repository.Delete(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx).WithField("apiClientID", id).WithError(err).Error("Error revoking API client")
+		tracing.RecordError(span, err)
+		return err
+	}
+	if deleted == 0 {
+		return internalErrors.NewNotFoundError(internalErrors.APIClientNotFound, "API client not found")
+	}
+
+	logging.FromContext(ctx).WithField("apiClientID", id).Info("API client revoked")
+	return nil
+}
+
+// Authenticate returns the active client the key belongs to. Unknown keys and
+// the keys of disabled clients are rejected alike, so callers cannot tell
+// them apart.
+func (a *apiClientService) Authenticate(ctx context.Context, key string) (*model.APIClient, error) {
+	ctx, span := tracing.Start(ctx, "APIClientService.Authenticate")
+	defer span.End()
+
+	invalid := internalErrors.NewUnauthorizedError(internalErrors.InvalidAPIKey, "Invalid API key")
+	if !apikey.LooksValid(key) {
+		return nil, invalid
+	}
+
+	client, err := a.repository.FindByKeyHash(ctx, apikey.Hash(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.FromContext(ctx).WithField("keyPrefix", apikey.Prefix(key)).Warn("Unknown API key")
+			return nil, invalid
+		}
+		logging.FromContext(ctx).WithError(err).Error("Error authenticating API key")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(tracing.APIClientIDKey.Int64(client.ID))
+
+	if !client.Active {
+		logging.FromContext(ctx).WithField("apiClientID", client.ID).Warn("Disabled API client")
+		return nil, invalid
+	}
+	return client, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/apikey"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+const testAPIKey = "ak_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestAPIClientService_IssueClient(t *testing.T) {
+	mockRepo := new(MockAPIClientRepository)
+
+	mockRepo.On("Create", mock.AnythingOfType("*model.APIClient")).Return(&model.APIClient{ID: 1}, nil)
+
+	logger, hook := logTest.NewNullLogger()
+	ctx := logging.NewContext(context.Background(), logger.WithField("requestID", "issue"))
+
+	service := NewAPIClientService(mockRepo)
+
+	client := &model.APIClient{Name: "billing", Scopes: model.Scopes{model.ScopeAccountsRead}}
+	_, key, err := service.IssueClient(ctx, client)
+
+	assert.NoError(t, err)
+	assert.True(t, apikey.LooksValid(key))
+	assert.Equal(t, apikey.Hash(key), client.KeyHash)
+	assert.Equal(t, key[:11], client.KeyPrefix)
+	assert.True(t, client.Active)
+
+	for _, entry := range hook.AllEntries() {
+		line, _ := entry.String()
+		assert.NotContains(t, line, key[11:])
+		assert.NotContains(t, line, client.KeyHash)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAPIClientService_GetClientNotFoundError(t *testing.T) {
+	mockRepo := new(MockAPIClientRepository)
+
+	mockRepo.On("FindById", int64(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewAPIClientService(mockRepo)
+
+	_, err := service.GetClient(context.Background(), 2)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+	assert.EqualError(t, err, "API client not found")
+}
+
+func TestAPIClientService_SetClientActive(t *testing.T) {
+	mockRepo := new(MockAPIClientRepository)
+
+	existing := &model.APIClient{ID: 1, KeyHash: "hash", Active: true}
+	mockRepo.On("FindById", int64(1)).Return(existing, nil)
+	mockRepo.On("Update", existing).Return(nil)
+
+	service := NewAPIClientService(mockRepo)
+
+	updated, err := service.SetClientActive(context.Background(), 1, false)
+
+	assert.NoError(t, err)
+	assert.False(t, updated.Active)
+	assert.Equal(t, "hash", updated.KeyHash)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAPIClientService_RotateKey(t *testing.T) {
+	mockRepo := new(MockAPIClientRepository)
+
+	existing := &model.APIClient{ID: 1, KeyPrefix: "ak_01234567", KeyHash: apikey.Hash(testAPIKey), Active: true}
+	mockRepo.On("FindById", int64(1)).Return(existing, nil)
+	mockRepo.On("Update", existing).Return(nil)
+
+	now := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+	service := &apiClientService{mockRepo, func() time.Time { return now }}
+
+	rotated, key, err := service.RotateKey(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, testAPIKey, key)
+	assert.Equal(t, apikey.Hash(key), rotated.KeyHash)
+	assert.Equal(t, apikey.Prefix(key), rotated.KeyPrefix)
+	assert.Equal(t, now, *rotated.RotatedAt)
+	assert.True(t, rotated.Active)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAPIClientService_RevokeClientNotFoundError(t *testing.T) {
+	mockRepo := new(MockAPIClientRepository)
+
+	mockRepo.On("Delete", int64(2)).Return(int64(0), nil)
+
+	service := NewAPIClientService(mockRepo)
+
+	err := service.RevokeClient(context.Background(), 2)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+}
+
+func TestAPIClientService_Authenticate(t *testing.T) {
+	mockRepo := new(MockAPIClientRepository)
+
+	client := &model.APIClient{ID: 1, Scopes: model.Scopes{model.ScopeAccountsRead}, Active: true}
+	mockRepo.On("FindByKeyHash", apikey.Hash(testAPIKey)).Return(client, nil)
+
+	service := NewAPIClientService(mockRepo)
+
+	authenticated, err := service.Authenticate(context.Background(), testAPIKey)
+
+	assert.NoError(t, err)
+	assert.Equal(t, client, authenticated)
+}
+
+func TestAPIClientService_AuthenticateRejectsKey(t *testing.T) {
+	unknownKey := "ak_fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "malformed key", key: "not-a-key"},
+		{name: "unknown key", key: unknownKey},
+		{name: "disabled client", key: testAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAPIClientRepository)
+			mockRepo.On("FindByKeyHash", apikey.Hash(testAPIKey)).Return(&model.APIClient{ID: 1, Active: false}, nil)
+			mockRepo.On("FindByKeyHash", apikey.Hash(unknownKey)).Return(nil, gorm.ErrRecordNotFound)
+
+			service := NewAPIClientService(mockRepo)
+
+			_, err := service.Authenticate(context.Background(), tt.key)
+
+			var unauthorizedErr internalErrors.UnauthorizedError
+			assert.ErrorAs(t, err, &unauthorizedErr)
+			assert.Equal(t, internalErrors.InvalidAPIKey, unauthorizedErr.Code())
+		})
+	}
+}
+
+func TestAPIClientService_AuthenticateError(t *testing.T) {
+	mockRepo := new(MockAPIClientRepository)
+
+	mockRepo.On("FindByKeyHash", apikey.Hash(testAPIKey)).Return(nil, errors.New("database error"))
+
+	service := NewAPIClientService(mockRepo)
+
+	_, err := service.Authenticate(context.Background(), testAPIKey)
+
+	assert.EqualError(t, err, "database error")
+}
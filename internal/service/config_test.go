@@ -1,7 +1,13 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/ledger"
+	"github.com/gmerten/accounts_transactions/internal/migration"
 	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -13,7 +19,63 @@ type MockTransactionRepository struct {
 	mock.Mock
 }
 
-func (m *MockAccountRepository) Create(account *model.Account) (*model.Account, error) {
+type MockInstallmentRepository struct {
+	mock.Mock
+}
+
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+type MockPublisher struct {
+	mock.Mock
+}
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+type MockAPIClientRepository struct {
+	mock.Mock
+}
+
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+type MockSender struct {
+	mock.Mock
+}
+
+type MockTransactor struct {
+	accountRepository     *MockAccountRepository
+	transactionRepository *MockTransactionRepository
+	installmentRepository *MockInstallmentRepository
+	statementRepository   *MockStatementRepository
+	ledgerRepository      *MockLedgerRepository
+	outboxRepository      *MockOutboxRepository
+}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(repositories repository.Repositories) error) error {
+	return fn(repository.Repositories{
+		Accounts:     m.accountRepository,
+		Transactions: m.transactionRepository,
+		Installments: m.installmentRepository,
+		Statements:   m.statementRepository,
+		Ledger:       m.ledgerRepository,
+		Outbox:       m.outboxRepository,
+	})
+}
+
+func (m *MockAccountRepository) Create(ctx context.Context, account *model.Account) (*model.Account, error) {
 	args := m.Called(account)
 	res := args.Get(0)
 	err := args.Error(1)
@@ -24,7 +86,7 @@ func (m *MockAccountRepository) Create(account *model.Account) (*model.Account,
 	return res.(*model.Account), err
 }
 
-func (m *MockAccountRepository) FindById(accountID int64) (*model.Account, error) {
+func (m *MockAccountRepository) FindById(ctx context.Context, accountID int64) (*model.Account, error) {
 	args := m.Called(accountID)
 	res := args.Get(0)
 	err := args.Error(1)
@@ -36,7 +98,36 @@ func (m *MockAccountRepository) FindById(accountID int64) (*model.Account, error
 	return res.(*model.Account), err
 }
 
-func (m *MockTransactionRepository) Create(transaction *model.Transaction) (*model.Transaction, error) {
+func (m *MockAccountRepository) FindByIdForUpdate(ctx context.Context, accountID int64) (*model.Account, error) {
+	args := m.Called(accountID)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*model.Account), err
+}
+
+func (m *MockAccountRepository) FindAfterId(ctx context.Context, afterID int64, limit int) ([]model.Account, error) {
+	args := m.Called(afterID, limit)
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.([]model.Account), err
+}
+
+func (m *MockAccountRepository) UpdateBalance(ctx context.Context, accountID int64, balance model.Money) error {
+	args := m.Called(accountID, balance)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) Create(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
 	args := m.Called(transaction)
 
 	res := args.Get(0)
@@ -47,3 +138,531 @@ func (m *MockTransactionRepository) Create(transaction *model.Transaction) (*mod
 	}
 	return res.(*model.Transaction), err
 }
+
+func (m *MockTransactionRepository) FindOpenDebitsForUpdate(ctx context.Context, accountID int64) ([]model.Transaction, error) {
+	args := m.Called(accountID)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.Transaction), err
+}
+
+func (m *MockTransactionRepository) UpdateBalance(ctx context.Context, transactionID int64, balance model.Money) error {
+	args := m.Called(transactionID, balance)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) FindByAccountId(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	args := m.Called(filter)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.Transaction), err
+}
+
+type MockIdempotencyKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyKeyRepository) Create(ctx context.Context, idempotencyKey *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	args := m.Called(idempotencyKey)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.IdempotencyKey), err
+}
+
+func (m *MockIdempotencyKeyRepository) Find(ctx context.Context, key string, scope string) (*model.IdempotencyKey, error) {
+	args := m.Called(key, scope)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.IdempotencyKey), err
+}
+
+func (m *MockIdempotencyKeyRepository) Complete(ctx context.Context, idempotencyKey *model.IdempotencyKey) error {
+	args := m.Called(idempotencyKey)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) Delete(ctx context.Context, key string, scope string) error {
+	args := m.Called(key, scope)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAccountRepository) UpdateAvailableCreditLimit(ctx context.Context, accountID int64, availableCreditLimit model.Money) error {
+	args := m.Called(accountID, availableCreditLimit)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) UpdateStatus(ctx context.Context, accountID int64, status model.AccountStatus) error {
+	args := m.Called(accountID, status)
+	return args.Error(0)
+}
+
+type MockOperationTypeRepository struct {
+	mock.Mock
+}
+
+func (m *MockOperationTypeRepository) Create(ctx context.Context, operationType *model.OperationTypeDefinition) (*model.OperationTypeDefinition, error) {
+	args := m.Called(operationType)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.OperationTypeDefinition), err
+}
+
+func (m *MockOperationTypeRepository) FindById(ctx context.Context, id model.OperationType) (*model.OperationTypeDefinition, error) {
+	args := m.Called(id)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.OperationTypeDefinition), err
+}
+
+func (m *MockOperationTypeRepository) FindAll(ctx context.Context) ([]model.OperationTypeDefinition, error) {
+	args := m.Called()
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.OperationTypeDefinition), err
+}
+
+func (m *MockTransactionRepository) FindById(ctx context.Context, id int64) (*model.Transaction, error) {
+	args := m.Called(id)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.Transaction), err
+}
+
+func (m *MockTransactionRepository) FindByIdForUpdate(ctx context.Context, id int64) (*model.Transaction, error) {
+	args := m.Called(id)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.Transaction), err
+}
+
+func (m *MockTransactionRepository) UpdateReversal(ctx context.Context, transactionID int64, reversedAmount model.Money, status model.TransactionStatus) error {
+	args := m.Called(transactionID, reversedAmount, status)
+	return args.Error(0)
+}
+
+func (m *MockInstallmentRepository) Create(ctx context.Context, installments []model.Installment) error {
+	args := m.Called(installments)
+	return args.Error(0)
+}
+
+func (m *MockInstallmentRepository) FindByTransactionId(ctx context.Context, transactionID int64) ([]model.Installment, error) {
+	args := m.Called(transactionID)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.Installment), err
+}
+
+func (m *MockInstallmentRepository) FindByAccountIdDueBetween(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Installment, error) {
+	args := m.Called(accountID, from, to)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.Installment), err
+}
+
+func (m *MockInstallmentRepository) SumDueBefore(ctx context.Context, accountID int64, before time.Time) (model.Money, error) {
+	args := m.Called(accountID, before)
+	return args.Get(0).(model.Money), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindByAccountIdBetween(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Transaction, error) {
+	args := m.Called(accountID, from, to)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.Transaction), err
+}
+
+func (m *MockTransactionRepository) SumBilledAmountsBefore(ctx context.Context, accountID int64, before time.Time) (model.Money, error) {
+	args := m.Called(accountID, before)
+	return args.Get(0).(model.Money), args.Error(1)
+}
+
+func (m *MockStatementRepository) Create(ctx context.Context, statement *model.Statement) (*model.Statement, error) {
+	args := m.Called(statement)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.Statement), err
+}
+
+func (m *MockStatementRepository) FindByAccountIdAndPeriod(ctx context.Context, accountID int64, period string) (*model.Statement, error) {
+	args := m.Called(accountID, period)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.Statement), err
+}
+
+func (m *MockStatementRepository) FindByAccountId(ctx context.Context, accountID int64) ([]model.Statement, error) {
+	args := m.Called(accountID)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.Statement), err
+}
+
+func (m *MockStatementRepository) FindLatestByAccountId(ctx context.Context, accountID int64) (*model.Statement, error) {
+	args := m.Called(accountID)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.Statement), err
+}
+
+func (m *MockLedgerRepository) CreateJournal(ctx context.Context, journal *ledger.Journal) error {
+	args := m.Called(journal)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepository) FindJournals(ctx context.Context, afterID int64, limit int) ([]ledger.Journal, error) {
+	args := m.Called(afterID, limit)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]ledger.Journal), err
+}
+
+func (m *MockOutboxRepository) Append(ctx context.Context, message *model.OutboxMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) FindPending(ctx context.Context, limit int) ([]model.OutboxMessage, error) {
+	args := m.Called(limit)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.OutboxMessage), err
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	args := m.Called(id, publishedAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id int64, lastError string) error {
+	args := m.Called(id, lastError)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkDeadLettered(ctx context.Context, id int64, lastError string, deadLetteredAt time.Time) error {
+	args := m.Called(id, lastError, deadLetteredAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Redrive(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := m.Called(subscription)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.WebhookSubscription), err
+}
+
+func (m *MockWebhookRepository) FindById(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	args := m.Called(id)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.WebhookSubscription), err
+}
+
+func (m *MockWebhookRepository) FindAll(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called()
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.WebhookSubscription), err
+}
+
+func (m *MockWebhookRepository) FindActive(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called()
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.WebhookSubscription), err
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, subscription *model.WebhookSubscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Create(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	args := m.Called(delivery)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.WebhookDelivery), err
+}
+
+func (m *MockWebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(now, limit)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.WebhookDelivery), err
+}
+
+func (m *MockWebhookDeliveryRepository) FindBySubscriptionId(ctx context.Context, subscriptionID int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(subscriptionID, status, limit)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.WebhookDelivery), err
+}
+
+func (m *MockWebhookDeliveryRepository) UpdateAttempt(ctx context.Context, delivery *model.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) Redrive(ctx context.Context, subscriptionID int64, now time.Time) (int64, error) {
+	args := m.Called(subscriptionID, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSender) Send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	args := m.Called(subscription, delivery)
+	return args.Int(0), args.Error(1)
+}
+
+type MockHealthRepository struct {
+	mock.Mock
+}
+
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockMigrator struct {
+	mock.Mock
+}
+
+func (m *MockMigrator) Up() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMigrator) Down() (*migration.Migration, error) {
+	args := m.Called()
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*migration.Migration), err
+}
+
+func (m *MockMigrator) Status() ([]migration.Status, error) {
+	args := m.Called()
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]migration.Status), err
+}
+
+func (m *MockMigrator) Version(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMigrator) Latest() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
+func (m *MockAPIClientRepository) Create(ctx context.Context, client *model.APIClient) (*model.APIClient, error) {
+	args := m.Called(client)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
+
+func (m *MockAPIClientRepository) FindById(ctx context.Context, id int64) (*model.APIClient, error) {
+	args := m.Called(id)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
+
+func (m *MockAPIClientRepository) FindByKeyHash(ctx context.Context, keyHash string) (*model.APIClient, error) {
+	args := m.Called(keyHash)
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.(*model.APIClient), err
+}
+
+func (m *MockAPIClientRepository) FindAll(ctx context.Context) ([]model.APIClient, error) {
+	args := m.Called()
+
+	res := args.Get(0)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+	return res.([]model.APIClient), err
+}
+
+func (m *MockAPIClientRepository) Update(ctx context.Context, client *model.APIClient) error {
+	args := m.Called(client)
+	return args.Error(0)
+}
+
+func (m *MockAPIClientRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
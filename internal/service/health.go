@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/migration"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+)
+
+const (
+	DatabaseCheck   = "database"
+	MigrationsCheck = "migrations"
+)
+
+type healthService struct {
+	repository repository.HealthRepository
+	migrator   migration.Migrator
+}
+
+type HealthService interface {
+	Readiness(ctx context.Context) *model.Readiness
+}
+
+func NewHealthService(repository repository.HealthRepository, migrator migration.Migrator) HealthService {
+	return &healthService{repository, migrator}
+}
+
+// Readiness checks that the database is reachable and that its schema is at
+// the latest migration known to the binary. The migrations are not checked
+// while the database is unreachable.
+func (h *healthService) Readiness(ctx context.Context) *model.Readiness {
+	if err := h.repository.Ping(ctx); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Database is unreachable")
+		return &model.Readiness{Checks: []model.HealthCheck{
+			{Name: DatabaseCheck, Error: "database is unreachable"},
+			{Name: MigrationsCheck, Error: "not checked, database is unreachable"},
+		}}
+	}
+
+	readiness := &model.Readiness{Checks: []model.HealthCheck{{Name: DatabaseCheck}}}
+
+	migrations := model.HealthCheck{Name: MigrationsCheck}
+	version, err := h.migrator.Version(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Error reading the schema version")
+		migrations.Error = "schema version could not be read"
+	} else if latest := h.migrator.Latest(); version != latest {
+		migrations.Error = fmt.Sprintf("schema is at version %d, expected %d", version, latest)
+	}
+	readiness.Checks = append(readiness.Checks, migrations)
+
+	return readiness
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService_Ready(t *testing.T) {
+	mockRepo := new(MockHealthRepository)
+	mockMigrator := new(MockMigrator)
+
+	mockRepo.On("Ping").Return(nil)
+	mockMigrator.On("Version").Return(15, nil)
+	mockMigrator.On("Latest").Return(15)
+
+	service := NewHealthService(mockRepo, mockMigrator)
+
+	readiness := service.Readiness(context.Background())
+
+	assert.True(t, readiness.Ready())
+	assert.Equal(t, []model.HealthCheck{{Name: DatabaseCheck}, {Name: MigrationsCheck}}, readiness.Checks)
+
+	mockRepo.AssertExpectations(t)
+	mockMigrator.AssertExpectations(t)
+}
+
+func TestHealthService_DatabaseUnreachable(t *testing.T) {
+	mockRepo := new(MockHealthRepository)
+	mockMigrator := new(MockMigrator)
+
+	mockRepo.On("Ping").Return(errors.New("connection refused"))
+
+	service := NewHealthService(mockRepo, mockMigrator)
+
+	readiness := service.Readiness(context.Background())
+
+	assert.False(t, readiness.Ready())
+	assert.Equal(t, "database is unreachable", readiness.Checks[0].Error)
+	assert.Equal(t, "not checked, database is unreachable", readiness.Checks[1].Error)
+
+	mockRepo.AssertExpectations(t)
+	mockMigrator.AssertNotCalled(t, "Version")
+}
+
+func TestHealthService_PendingMigrations(t *testing.T) {
+	mockRepo := new(MockHealthRepository)
+	mockMigrator := new(MockMigrator)
+
+	mockRepo.On("Ping").Return(nil)
+	mockMigrator.On("Version").Return(14, nil)
+	mockMigrator.On("Latest").Return(15)
+
+	service := NewHealthService(mockRepo, mockMigrator)
+
+	readiness := service.Readiness(context.Background())
+
+	assert.False(t, readiness.Ready())
+	assert.Equal(t, model.HealthCheck{Name: DatabaseCheck}, readiness.Checks[0])
+	assert.Equal(t, "schema is at version 14, expected 15", readiness.Checks[1].Error)
+}
+
+func TestHealthService_VersionError(t *testing.T) {
+	mockRepo := new(MockHealthRepository)
+	mockMigrator := new(MockMigrator)
+
+	mockRepo.On("Ping").Return(nil)
+	mockMigrator.On("Version").Return(0, errors.New("table locked"))
+
+	service := NewHealthService(mockRepo, mockMigrator)
+
+	readiness := service.Readiness(context.Background())
+
+	assert.False(t, readiness.Ready())
+	assert.Equal(t, "schema version could not be read", readiness.Checks[1].Error)
+}
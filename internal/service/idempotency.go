@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/metrics"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type idempotencyService struct {
+	repository repository.IdempotencyKeyRepository
+	ttl        time.Duration
+	lease      time.Duration
+	now        func() time.Time
+}
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, key string, scope string, requestHash string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, key string, scope string, statusCode int, responseBody []byte) error
+	Release(ctx context.Context, key string, scope string) error
+	PurgeExpired(ctx context.Context) error
+}
+
+func NewIdempotencyService(repository repository.IdempotencyKeyRepository, ttl time.Duration, lease time.Duration) IdempotencyService {
+	return &idempotencyService{repository, ttl, lease, time.Now}
+}
+
+// Begin reserves the key for a new request. When the key was already used for
+// the same request the stored record is returned, and it is Completed when its
+// response can be replayed. A key still in progress after its lease belongs to
+// a request that never finished, and it is reserved again.
+func (i *idempotencyService) Begin(ctx context.Context, key string, scope string, requestHash string) (*model.IdempotencyKey, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Begin")
+	defer span.End()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		now := i.now()
+		idempotencyKey := &model.IdempotencyKey{
+			Key:         key,
+			Scope:       scope,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(i.ttl),
+		}
+
+		_, err := i.repository.Create(ctx, idempotencyKey)
+		if err == nil {
+			return idempotencyKey, nil
+		}
+		if !internalErrors.IsDuplicateKeyError(err) {
+			logging.FromContext(ctx).WithError(err).Error("Error saving idempotency key")
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+
+		existing, err := i.repository.Find(ctx, key, scope)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			logging.FromContext(ctx).WithError(err).Error("Error getting idempotency key")
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+
+		if existing.IsExpired(now) || existing.IsAbandoned(now, i.lease) {
+			if err = i.repository.Delete(ctx, key, scope); err != nil {
+				logging.FromContext(ctx).WithError(err).Error("Error deleting stale idempotency key")
+				tracing.RecordError(span, err)
+				return nil, err
+			}
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, internalErrors.NewUnprocessableEntityError(internalErrors.IdempotencyKeyReused, "Idempotency-Key was already used with a different request payload")
+		}
+
+		if !existing.Completed {
+			metrics.Conflict(metrics.IdempotencyService)
+			return nil, internalErrors.NewConflictError(internalErrors.IdempotencyKeyInUse, "A request with this Idempotency-Key is still being processed")
+		}
+
+		return existing, nil
+	}
+
+	metrics.Conflict(metrics.IdempotencyService)
+	return nil, internalErrors.NewConflictError(internalErrors.IdempotencyKeyInUse, "A request with this Idempotency-Key is still being processed")
+}
+
+func (i *idempotencyService) Complete(ctx context.Context, key string, scope string, statusCode int, responseBody []byte) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Complete")
+	defer span.End()
+
+	err := i.repository.Complete(ctx, &model.IdempotencyKey{
+		Key:          key,
+		Scope:        scope,
+		StatusCode:   statusCode,
+		ResponseBody: responseBody,
+	})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error completing idempotency key")
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+// Release frees a reserved key whose request failed, so a retry runs again.
+func (i *idempotencyService) Release(ctx context.Context, key string, scope string) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Release")
+	defer span.End()
+
+	err := i.repository.Delete(ctx, key, scope)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error releasing idempotency key")
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+func (i *idempotencyService) PurgeExpired(ctx context.Context) error {
+	purged, err := i.repository.DeleteExpired(ctx, i.now())
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error purging expired idempotency keys")
+		return err
+	}
+	logging.FromContext(ctx).WithField("purged", purged).Debug("Purged expired idempotency keys")
+	return nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newTestIdempotencyService(mockRepo *MockIdempotencyKeyRepository, now time.Time) IdempotencyService {
+	return &idempotencyService{mockRepo, time.Hour, time.Minute, func() time.Time { return now }}
+}
+
+func TestIdempotencyService_BeginNewKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	now := time.Now()
+
+	expected := &model.IdempotencyKey{
+		Key:         "key-1",
+		Scope:       "POST /transactions",
+		RequestHash: "hash",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	mockRepo.On("Create", expected).Return(expected, nil)
+
+	service := newTestIdempotencyService(mockRepo, now)
+
+	idempotencyKey, err := service.Begin(context.Background(), "key-1", "POST /transactions", "hash")
+
+	assert.NoError(t, err)
+	assert.False(t, idempotencyKey.Completed)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_BeginReplay(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	now := time.Now()
+
+	existing := &model.IdempotencyKey{
+		Key:          "key-1",
+		Scope:        "POST /transactions",
+		RequestHash:  "hash",
+		Completed:    true,
+		StatusCode:   201,
+		ResponseBody: []byte(`{}`),
+		ExpiresAt:    now.Add(time.Minute),
+	}
+
+	mockRepo.On("Create", mock.Anything).Return(nil, gorm.ErrDuplicatedKey)
+	mockRepo.On("Find", "key-1", "POST /transactions").Return(existing, nil)
+
+	service := newTestIdempotencyService(mockRepo, now)
+
+	idempotencyKey, err := service.Begin(context.Background(), "key-1", "POST /transactions", "hash")
+
+	assert.NoError(t, err)
+	assert.Equal(t, existing, idempotencyKey)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_BeginPayloadMismatch(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	now := time.Now()
+
+	existing := &model.IdempotencyKey{
+		Key:         "key-1",
+		Scope:       "POST /transactions",
+		RequestHash: "hash",
+		Completed:   true,
+		ExpiresAt:   now.Add(time.Minute),
+	}
+
+	mockRepo.On("Create", mock.Anything).Return(nil, gorm.ErrDuplicatedKey)
+	mockRepo.On("Find", "key-1", "POST /transactions").Return(existing, nil)
+
+	service := newTestIdempotencyService(mockRepo, now)
+
+	_, err := service.Begin(context.Background(), "key-1", "POST /transactions", "other-hash")
+
+	assert.ErrorAs(t, err, &internalErrors.UnprocessableEntityError{})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_BeginInProgress(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	now := time.Now()
+
+	existing := &model.IdempotencyKey{
+		Key:         "key-1",
+		Scope:       "POST /transactions",
+		RequestHash: "hash",
+		CreatedAt:   now.Add(-30 * time.Second),
+		ExpiresAt:   now.Add(time.Minute),
+	}
+
+	mockRepo.On("Create", mock.Anything).Return(nil, gorm.ErrDuplicatedKey)
+	mockRepo.On("Find", "key-1", "POST /transactions").Return(existing, nil)
+
+	service := newTestIdempotencyService(mockRepo, now)
+
+	_, err := service.Begin(context.Background(), "key-1", "POST /transactions", "hash")
+
+	assert.ErrorAs(t, err, &internalErrors.ConflictError{})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_BeginAbandonedKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	now := time.Now()
+
+	abandoned := &model.IdempotencyKey{
+		Key:         "key-1",
+		Scope:       "POST /transactions",
+		RequestHash: "hash",
+		CreatedAt:   now.Add(-time.Minute),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	mockRepo.On("Create", mock.Anything).Return(nil, gorm.ErrDuplicatedKey).Once()
+	mockRepo.On("Find", "key-1", "POST /transactions").Return(abandoned, nil)
+	mockRepo.On("Delete", "key-1", "POST /transactions").Return(nil)
+	mockRepo.On("Create", mock.Anything).Return(&model.IdempotencyKey{}, nil).Once()
+
+	service := newTestIdempotencyService(mockRepo, now)
+
+	idempotencyKey, err := service.Begin(context.Background(), "key-1", "POST /transactions", "hash")
+
+	assert.NoError(t, err)
+	assert.False(t, idempotencyKey.Completed)
+	assert.Equal(t, now, idempotencyKey.CreatedAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_BeginExpiredKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	now := time.Now()
+
+	expired := &model.IdempotencyKey{
+		Key:         "key-1",
+		Scope:       "POST /transactions",
+		RequestHash: "other-hash",
+		Completed:   true,
+		ExpiresAt:   now.Add(-time.Minute),
+	}
+
+	mockRepo.On("Create", mock.Anything).Return(nil, gorm.ErrDuplicatedKey).Once()
+	mockRepo.On("Find", "key-1", "POST /transactions").Return(expired, nil)
+	mockRepo.On("Delete", "key-1", "POST /transactions").Return(nil)
+	mockRepo.On("Create", mock.Anything).Return(&model.IdempotencyKey{}, nil).Once()
+
+	service := newTestIdempotencyService(mockRepo, now)
+
+	idempotencyKey, err := service.Begin(context.Background(), "key-1", "POST /transactions", "hash")
+
+	assert.NoError(t, err)
+	assert.False(t, idempotencyKey.Completed)
+	assert.Equal(t, "hash", idempotencyKey.RequestHash)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_BeginError(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+
+	mockRepo.On("Create", mock.Anything).Return(nil, errors.New("generic error"))
+
+	service := newTestIdempotencyService(mockRepo, time.Now())
+
+	_, err := service.Begin(context.Background(), "key-1", "POST /transactions", "hash")
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_CompleteAndRelease(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+
+	mockRepo.On("Complete", &model.IdempotencyKey{
+		Key:          "key-1",
+		Scope:        "POST /accounts",
+		StatusCode:   201,
+		ResponseBody: []byte(`{}`),
+	}).Return(nil)
+	mockRepo.On("Delete", "key-2", "POST /accounts").Return(nil)
+
+	service := newTestIdempotencyService(mockRepo, time.Now())
+
+	assert.NoError(t, service.Complete(context.Background(), "key-1", "POST /accounts", 201, []byte(`{}`)))
+	assert.NoError(t, service.Release(context.Background(), "key-2", "POST /accounts"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_PurgeExpired(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	now := time.Now()
+
+	mockRepo.On("DeleteExpired", now).Return(int64(3), nil)
+
+	service := newTestIdempotencyService(mockRepo, now)
+
+	assert.NoError(t, service.PurgeExpired(context.Background()))
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type installmentService struct {
+	repository            repository.InstallmentRepository
+	transactionRepository repository.TransactionRepository
+	now                   func() time.Time
+}
+
+type InstallmentService interface {
+	GetInstallmentPlan(ctx context.Context, transactionID int64) (*model.InstallmentPlan, error)
+	GetInstallment(ctx context.Context, transactionID int64, number int) (*model.Installment, error)
+}
+
+func NewInstallmentService(repository repository.InstallmentRepository, transactionRepository repository.TransactionRepository) InstallmentService {
+	return &installmentService{repository, transactionRepository, time.Now}
+}
+
+// GetInstallmentPlan returns the installments of a purchase, with the status
+// of each one derived from how much of the purchase was paid or reversed.
+func (i *installmentService) GetInstallmentPlan(ctx context.Context, transactionID int64) (*model.InstallmentPlan, error) {
+	ctx, span := tracing.Start(ctx, "InstallmentService.GetInstallmentPlan", tracing.TransactionIDKey.Int64(transactionID))
+	defer span.End()
+
+	transaction, err := i.transactionRepository.FindById(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, internalErrors.NewNotFoundError(internalErrors.TransactionNotFound, "Transaction not found")
+		}
+		logging.FromContext(ctx).WithField("transactionID", transactionID).WithError(err).Error("Error getting transaction")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	installments, err := i.repository.FindByTransactionId(ctx, transactionID)
+	if err != nil {
+		logging.FromContext(ctx).WithField("transactionID", transactionID).WithError(err).Error("Error getting installments")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	if len(installments) == 0 {
+		return nil, internalErrors.NewNotFoundError(internalErrors.InstallmentPlanNotFound, "Transaction has no installment plan")
+	}
+
+	return model.NewInstallmentPlan(transaction, installments, i.now()), nil
+}
+
+func (i *installmentService) GetInstallment(ctx context.Context, transactionID int64, number int) (*model.Installment, error) {
+	ctx, span := tracing.Start(ctx, "InstallmentService.GetInstallment", tracing.TransactionIDKey.Int64(transactionID))
+	defer span.End()
+
+	plan, err := i.GetInstallmentPlan(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, installment := range plan.Installments {
+		if installment.Number == number {
+			return &installment, nil
+		}
+	}
+
+	return nil, internalErrors.NewNotFoundError(internalErrors.InstallmentNotFound, "Installment not found")
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newTestInstallmentService(repository *MockInstallmentRepository, transactionRepository *MockTransactionRepository, now time.Time) InstallmentService {
+	return &installmentService{repository, transactionRepository, func() time.Time { return now }}
+}
+
+func TestInstallmentService_GetInstallmentPlan(t *testing.T) {
+	mockRepo := new(MockInstallmentRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	transaction := &model.Transaction{
+		ID:              5,
+		Amount:          model.MustParseMoney("-90.00"),
+		Balance:         model.MustParseMoney("-60.00"),
+		OperationType:   model.InstallmentPurchase,
+		TransactionDate: time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	mockTransactionRepo.On("FindById", int64(5)).Return(transaction, nil)
+	mockRepo.On("FindByTransactionId", int64(5)).Return(model.NewInstallments(transaction, 3), nil)
+
+	service := newTestInstallmentService(mockRepo, mockTransactionRepo, time.Date(2024, time.March, 20, 0, 0, 0, 0, time.UTC))
+
+	plan, err := service.GetInstallmentPlan(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.Len(t, plan.Installments, 3)
+	assert.Equal(t, model.InstallmentPaid, plan.Installments[0].Status)
+	assert.Equal(t, model.InstallmentOverdue, plan.Installments[1].Status)
+	assert.Equal(t, model.InstallmentScheduled, plan.Installments[2].Status)
+
+	installment, err := service.GetInstallment(context.Background(), 5, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, installment.Number)
+	assert.Equal(t, model.InstallmentOverdue, installment.Status)
+
+	_, err = service.GetInstallment(context.Background(), 5, 4)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+}
+
+func TestInstallmentService_GetInstallmentPlanNotFoundError(t *testing.T) {
+	mockRepo := new(MockInstallmentRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	mockTransactionRepo.On("FindById", int64(5)).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("FindById", int64(6)).Return(&model.Transaction{ID: 6, OperationType: model.Purchase}, nil)
+	mockRepo.On("FindByTransactionId", int64(6)).Return([]model.Installment{}, nil)
+
+	service := NewInstallmentService(mockRepo, mockTransactionRepo)
+
+	_, err := service.GetInstallmentPlan(context.Background(), 5)
+	assert.EqualError(t, err, "Transaction not found")
+
+	_, err = service.GetInstallmentPlan(context.Background(), 6)
+	assert.EqualError(t, err, "Transaction has no installment plan")
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+}
+
+func TestInstallmentService_GetInstallmentPlanError(t *testing.T) {
+	mockRepo := new(MockInstallmentRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	mockTransactionRepo.On("FindById", int64(5)).Return(&model.Transaction{ID: 5}, nil)
+	mockRepo.On("FindByTransactionId", int64(5)).Return(nil, errors.New("db error"))
+
+	service := NewInstallmentService(mockRepo, mockTransactionRepo)
+
+	_, err := service.GetInstallmentPlan(context.Background(), 5)
+	assert.EqualError(t, err, "db error")
+}
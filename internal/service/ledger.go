@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gmerten/accounts_transactions/internal/ledger"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+)
+
+const ledgerVerifyBatchSize = 500
+
+type ledgerService struct {
+	repository repository.LedgerRepository
+}
+
+type LedgerService interface {
+	Verify(ctx context.Context) (*ledger.Report, error)
+}
+
+func NewLedgerService(repository repository.LedgerRepository) LedgerService {
+	return &ledgerService{repository}
+}
+
+// Verify scans every journal of the ledger in batches and reports the ones
+// that do not balance.
+func (l *ledgerService) Verify(ctx context.Context) (*ledger.Report, error) {
+	report := &ledger.Report{}
+
+	var afterID int64
+	for {
+		journals, err := l.repository.FindJournals(ctx, afterID, ledgerVerifyBatchSize)
+		if err != nil {
+			logging.FromContext(ctx).WithField("afterID", afterID).WithError(err).Error("Error reading ledger journals")
+			return nil, err
+		}
+
+		report.Check(journals)
+
+		if len(journals) < ledgerVerifyBatchSize {
+			return report, nil
+		}
+		afterID = journals[len(journals)-1].ID
+	}
+}
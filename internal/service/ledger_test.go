@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/ledger"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerService_Verify(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+
+	batch := make([]ledger.Journal, ledgerVerifyBatchSize)
+	for i := range batch {
+		journal := ledger.NewTransactionJournal(&model.Transaction{ID: int64(i + 1), AccountID: 1, OperationType: model.Purchase, Amount: model.MustParseMoney("-10.00")})
+		journal.ID = int64(i + 1)
+		batch[i] = *journal
+	}
+	unbalanced := ledger.Journal{ID: 501, TransactionID: 501, Entries: []ledger.Entry{
+		{LedgerAccount: ledger.CustomerAccount(1), Amount: model.MustParseMoney("10.00")},
+		{LedgerAccount: ledger.Cash, Amount: model.MustParseMoney("-10.01")},
+	}}
+
+	mockRepo.On("FindJournals", int64(0), ledgerVerifyBatchSize).Return(batch, nil)
+	mockRepo.On("FindJournals", int64(500), ledgerVerifyBatchSize).Return([]ledger.Journal{unbalanced}, nil)
+
+	service := NewLedgerService(mockRepo)
+
+	report, err := service.Verify(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, report.Balanced())
+	assert.Equal(t, 501, report.JournalsChecked)
+	assert.Equal(t, model.MustParseMoney("-0.01"), report.Total)
+	assert.Len(t, report.Imbalances, 1)
+	assert.Equal(t, int64(501), report.Imbalances[0].JournalID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLedgerService_VerifyError(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+
+	mockRepo.On("FindJournals", int64(0), ledgerVerifyBatchSize).Return(nil, errors.New("db error"))
+
+	service := NewLedgerService(mockRepo)
+
+	_, err := service.Verify(context.Background())
+	assert.EqualError(t, err, "db error")
+}
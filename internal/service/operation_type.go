@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/metrics"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type operationTypeService struct {
+	repository repository.OperationTypeRepository
+}
+
+type OperationTypeService interface {
+	CreateOperationType(ctx context.Context, operationType *model.OperationTypeDefinition) (*model.OperationTypeDefinition, error)
+	ListOperationTypes(ctx context.Context) ([]model.OperationTypeDefinition, error)
+	GetActiveOperationType(ctx context.Context, id model.OperationType) (*model.OperationTypeDefinition, error)
+}
+
+func NewOperationTypeService(repository repository.OperationTypeRepository) OperationTypeService {
+	return &operationTypeService{repository}
+}
+
+func (o *operationTypeService) CreateOperationType(ctx context.Context, operationType *model.OperationTypeDefinition) (*model.OperationTypeDefinition, error) {
+	ctx, span := tracing.Start(ctx, "OperationTypeService.CreateOperationType")
+	defer span.End()
+
+	operationType, err := o.repository.Create(ctx, operationType)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error saving operation type")
+		if internalErrors.IsDuplicateKeyError(err) {
+			metrics.Conflict(metrics.OperationTypeService)
+			return nil, internalErrors.NewConflictError(internalErrors.DuplicateOperationType, "Operation type with this ID already exists")
+		}
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return operationType, nil
+}
+
+func (o *operationTypeService) ListOperationTypes(ctx context.Context) ([]model.OperationTypeDefinition, error) {
+	ctx, span := tracing.Start(ctx, "OperationTypeService.ListOperationTypes")
+	defer span.End()
+
+	operationTypes, err := o.repository.FindAll(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error listing operation types")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return operationTypes, nil
+}
+
+// GetActiveOperationType returns the catalog entry transactions may be posted
+// with, or a validation error when it is unknown or inactive.
+func (o *operationTypeService) GetActiveOperationType(ctx context.Context, id model.OperationType) (*model.OperationTypeDefinition, error) {
+	ctx, span := tracing.Start(ctx, "OperationTypeService.GetActiveOperationType")
+	defer span.End()
+
+	operationType, err := o.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			metrics.ValidationFailed(metrics.OperationTypeService)
+			return nil, internalErrors.NewValidationError(internalErrors.InvalidOperationType, "Invalid operation type")
+		}
+		logging.FromContext(ctx).WithField("operationTypeID", id).WithError(err).Error("Error getting operation type")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	if !operationType.Active {
+		metrics.ValidationFailed(metrics.OperationTypeService)
+		return nil, internalErrors.NewValidationError(internalErrors.OperationTypeInactive, "Operation type is not active")
+	}
+
+	return operationType, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOperationTypeService_CreateOperationType(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	operationType := &model.OperationTypeDefinition{
+		Description: "Refund",
+		Sign:        model.Credit,
+		Active:      true,
+	}
+
+	mockRepo.On("Create", operationType).Return(operationType, nil)
+
+	service := NewOperationTypeService(mockRepo)
+
+	createdOperationType, err := service.CreateOperationType(context.Background(), operationType)
+
+	assert.NoError(t, err)
+	assert.Equal(t, operationType, createdOperationType)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOperationTypeService_CreateOperationTypeDuplicatedKeyError(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	operationType := &model.OperationTypeDefinition{
+		ID:          1,
+		Description: "Refund",
+		Sign:        model.Credit,
+	}
+
+	mockRepo.On("Create", operationType).Return(nil, gorm.ErrDuplicatedKey)
+
+	service := NewOperationTypeService(mockRepo)
+
+	_, err := service.CreateOperationType(context.Background(), operationType)
+
+	assert.ErrorAs(t, err, &internalErrors.ConflictError{})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOperationTypeService_ListOperationTypes(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	mockRepo.On("FindAll").Return(model.BuiltInOperationTypes, nil)
+
+	service := NewOperationTypeService(mockRepo)
+
+	operationTypes, err := service.ListOperationTypes(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.BuiltInOperationTypes, operationTypes)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOperationTypeService_ListOperationTypesError(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	mockRepo.On("FindAll").Return(nil, errors.New("generic error"))
+
+	service := NewOperationTypeService(mockRepo)
+
+	_, err := service.ListOperationTypes(context.Background())
+
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOperationTypeService_GetActiveOperationType(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	mockRepo.On("FindById", model.Payment).Return(&model.BuiltInOperationTypes[3], nil)
+
+	service := NewOperationTypeService(mockRepo)
+
+	operationType, err := service.GetActiveOperationType(context.Background(), model.Payment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.Credit, operationType.Sign)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOperationTypeService_GetActiveOperationTypeInactiveError(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	mockRepo.On("FindById", model.OperationType(5)).Return(&model.OperationTypeDefinition{ID: 5, Sign: model.Debit}, nil)
+
+	service := NewOperationTypeService(mockRepo)
+
+	_, err := service.GetActiveOperationType(context.Background(), 5)
+
+	assert.ErrorAs(t, err, &internalErrors.ValidationError{})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOperationTypeService_GetActiveOperationTypeNotFoundError(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	mockRepo.On("FindById", model.OperationType(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewOperationTypeService(mockRepo)
+
+	_, err := service.GetActiveOperationType(context.Background(), 9)
+
+	assert.ErrorAs(t, err, &internalErrors.ValidationError{})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOperationTypeService_GetActiveOperationTypeGenericError(t *testing.T) {
+	mockRepo := new(MockOperationTypeRepository)
+
+	mockRepo.On("FindById", model.OperationType(9)).Return(nil, errors.New("generic error"))
+
+	service := NewOperationTypeService(mockRepo)
+
+	_, err := service.GetActiveOperationType(context.Background(), 9)
+
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/publisher"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+)
+
+const outboxRelayBatchSize = 100
+
+type outboxRelay struct {
+	repository  repository.OutboxRepository
+	publisher   publisher.Publisher
+	maxAttempts int
+	now         func() time.Time
+}
+
+type OutboxRelay interface {
+	RelayPending(ctx context.Context) (int, error)
+	RedriveDeadLetters(ctx context.Context) (int64, error)
+}
+
+func NewOutboxRelay(repository repository.OutboxRepository, publisher publisher.Publisher, maxAttempts int) OutboxRelay {
+	return &outboxRelay{repository, publisher, maxAttempts, time.Now}
+}
+
+// RelayPending delivers a batch of pending outbox messages and returns how many
+// were published. A message is marked published only after the publisher
+// accepts it, so a crash in between delivers it again. When a message of an
+// account fails, the account's later messages wait until it is published,
+// keeping each account's events in order. After maxAttempts attempts it is dead
+// lettered, and the account's later messages wait until it is redriven. Only
+// one relay should run per database.
+func (o *outboxRelay) RelayPending(ctx context.Context) (int, error) {
+	messages, err := o.repository.FindPending(ctx, outboxRelayBatchSize)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error reading outbox")
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[int64]bool)
+	for i := range messages {
+		message := &messages[i]
+		if blocked[message.AccountID] {
+			continue
+		}
+
+		if err = o.publisher.Publish(ctx, message); err != nil {
+			// The later messages are left for the next run, which holds them
+			// back for as long as this one is failing or dead lettered.
+			blocked[message.AccountID] = true
+			o.fail(ctx, message, err)
+			continue
+		}
+
+		if err = o.repository.MarkPublished(ctx, message.ID, o.now()); err != nil {
+			logging.FromContext(ctx).WithField("messageID", message.ID).WithError(err).Error("Error marking outbox message published")
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// RedriveDeadLetters queues the dead lettered messages again, so they and the
+// later messages of their accounts are relayed, and returns how many were
+// queued.
+func (o *outboxRelay) RedriveDeadLetters(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "OutboxRelay.RedriveDeadLetters")
+	defer span.End()
+
+	redriven, err := o.repository.Redrive(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error redriving outbox messages")
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+	return redriven, nil
+}
+
+// fail records a failed delivery of the message, dead lettering it when it ran
+// out of attempts.
+func (o *outboxRelay) fail(ctx context.Context, message *model.OutboxMessage, publishErr error) {
+	logger := logging.FromContext(ctx).WithField("messageID", message.ID)
+
+	var err error
+	if message.Attempts+1 >= o.maxAttempts {
+		logger.WithError(publishErr).Error("Dead lettering outbox message")
+		err = o.repository.MarkDeadLettered(ctx, message.ID, publishErr.Error(), o.now())
+	} else {
+		logger.WithError(publishErr).Warn("Error publishing outbox message")
+		err = o.repository.MarkFailed(ctx, message.ID, publishErr.Error())
+	}
+	if err != nil {
+		logger.WithError(err).Error("Error recording outbox failure")
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxRelay_RelayPending(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	mockPublisher := new(MockPublisher)
+
+	now := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+	messages := []model.OutboxMessage{
+		{ID: 1, AccountID: 1, EventType: model.AccountCreatedEvent},
+		{ID: 2, AccountID: 2, EventType: model.AccountCreatedEvent},
+		{ID: 3, AccountID: 1, EventType: model.TransactionPostedEvent},
+		{ID: 4, AccountID: 2, EventType: model.TransactionPostedEvent},
+	}
+
+	mockRepo.On("FindPending", outboxRelayBatchSize).Return(messages, nil)
+	mockPublisher.On("Publish", &messages[0]).Return(nil)
+	mockPublisher.On("Publish", &messages[1]).Return(errors.New("broker unavailable"))
+	mockPublisher.On("Publish", &messages[2]).Return(nil)
+	mockRepo.On("MarkPublished", int64(1), now).Return(nil)
+	mockRepo.On("MarkFailed", int64(2), "broker unavailable").Return(nil)
+	mockRepo.On("MarkPublished", int64(3), now).Return(nil)
+
+	relay := &outboxRelay{mockRepo, mockPublisher, 8, func() time.Time { return now }}
+
+	published, err := relay.RelayPending(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	mockPublisher.AssertNotCalled(t, "Publish", &messages[3])
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RelayPendingDeadLetters(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	mockPublisher := new(MockPublisher)
+
+	now := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+	messages := []model.OutboxMessage{
+		{ID: 1, AccountID: 1, EventType: model.AccountCreatedEvent, Attempts: 7},
+		{ID: 2, AccountID: 1, EventType: model.TransactionPostedEvent},
+		{ID: 3, AccountID: 2, EventType: model.AccountCreatedEvent, Attempts: 2},
+	}
+
+	mockRepo.On("FindPending", outboxRelayBatchSize).Return(messages, nil)
+	mockPublisher.On("Publish", &messages[0]).Return(errors.New("invalid payload"))
+	mockPublisher.On("Publish", &messages[2]).Return(errors.New("broker unavailable"))
+	mockRepo.On("MarkDeadLettered", int64(1), "invalid payload", now).Return(nil)
+	mockRepo.On("MarkFailed", int64(3), "broker unavailable").Return(nil)
+
+	relay := &outboxRelay{mockRepo, mockPublisher, 8, func() time.Time { return now }}
+
+	published, err := relay.RelayPending(context.Background())
+
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+
+	mockPublisher.AssertNotCalled(t, "Publish", &messages[1])
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RelayPendingMarkPublishedError(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	mockPublisher := new(MockPublisher)
+
+	messages := []model.OutboxMessage{
+		{ID: 1, AccountID: 1, EventType: model.AccountCreatedEvent},
+		{ID: 2, AccountID: 1, EventType: model.TransactionPostedEvent},
+	}
+
+	mockRepo.On("FindPending", outboxRelayBatchSize).Return(messages, nil)
+	mockPublisher.On("Publish", &messages[0]).Return(nil)
+	mockRepo.On("MarkPublished", int64(1), mock.Anything).Return(errors.New("db error"))
+
+	relay := NewOutboxRelay(mockRepo, mockPublisher, 8)
+
+	published, err := relay.RelayPending(context.Background())
+
+	assert.EqualError(t, err, "db error")
+	assert.Zero(t, published)
+	mockPublisher.AssertNotCalled(t, "Publish", &messages[1])
+}
+
+func TestOutboxRelay_RelayPendingFindError(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+
+	mockRepo.On("FindPending", outboxRelayBatchSize).Return(nil, errors.New("db error"))
+
+	relay := NewOutboxRelay(mockRepo, new(MockPublisher), 8)
+
+	_, err := relay.RelayPending(context.Background())
+
+	assert.EqualError(t, err, "db error")
+}
+
+func TestOutboxRelay_RedriveDeadLetters(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+
+	mockRepo.On("Redrive").Return(int64(3), nil)
+
+	relay := NewOutboxRelay(mockRepo, new(MockPublisher), 8)
+
+	redriven, err := relay.RedriveDeadLetters(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), redriven)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RedriveDeadLettersError(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+
+	mockRepo.On("Redrive").Return(int64(0), errors.New("db error"))
+
+	relay := NewOutboxRelay(mockRepo, new(MockPublisher), 8)
+
+	_, err := relay.RedriveDeadLetters(context.Background())
+
+	assert.EqualError(t, err, "db error")
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/metrics"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type statementService struct {
+	repository            repository.StatementRepository
+	accountRepository     repository.AccountRepository
+	transactionRepository repository.TransactionRepository
+	installmentRepository repository.InstallmentRepository
+	transactor            repository.Transactor
+	now                   func() time.Time
+}
+
+const statementCloseBatchSize = 100
+
+type StatementService interface {
+	CloseDueStatements(ctx context.Context, accountID int64) error
+	CloseAllDueStatements(ctx context.Context) (int, error)
+	ListStatements(ctx context.Context, accountID int64) ([]model.Statement, error)
+	GetStatement(ctx context.Context, accountID int64, period string) (*model.Statement, error)
+}
+
+func NewStatementService(repository repository.StatementRepository, accountRepository repository.AccountRepository, transactionRepository repository.TransactionRepository, installmentRepository repository.InstallmentRepository, transactor repository.Transactor) StatementService {
+	return &statementService{repository, accountRepository, transactionRepository, installmentRepository, transactor, time.Now}
+}
+
+// CloseDueStatements closes and stores every ended cycle of the account that
+// has no statement yet, starting after the latest stored one or at the cycle
+// of the first transaction. Transactions are always dated when posted, so an
+// ended cycle cannot gain transactions and its statement never changes. The
+// account row is locked, so concurrent callers do not close a cycle twice.
+func (s *statementService) CloseDueStatements(ctx context.Context, accountID int64) error {
+	ctx, span := tracing.Start(ctx, "StatementService.CloseDueStatements", tracing.AccountIDKey.Int64(accountID))
+	defer span.End()
+	logging.AddField(ctx, "accountID", accountID)
+
+	now := s.now()
+
+	err := s.transactor.WithinTransaction(ctx, func(repositories repository.Repositories) error {
+		account, err := lockAccount(ctx, repositories.Accounts, accountID)
+		if err != nil {
+			return err
+		}
+
+		month, openingBalance, found, err := nextCycle(ctx, repositories.Statements, repositories.Transactions, account)
+		if err != nil || !found {
+			return err
+		}
+
+		for current := account.PeriodAt(now); month.Before(current); month = month.AddDate(0, 1, 0) {
+			transactions, err := repositories.Transactions.FindByAccountIdBetween(ctx, accountID, account.CycleStart(month), account.CycleEnd(month))
+			if err != nil {
+				return err
+			}
+			installments, err := repositories.Installments.FindByAccountIdDueBetween(ctx, accountID, account.CycleStart(month), account.CycleEnd(month))
+			if err != nil {
+				return err
+			}
+
+			statement := model.NewStatement(account, month, openingBalance, transactions, installments)
+			statement.ClosedAt = &now
+			if _, err = repositories.Statements.Create(ctx, statement); err != nil {
+				return err
+			}
+			openingBalance = statement.ClosingBalance
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error closing statements")
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// CloseAllDueStatements closes the ended cycles of every account and returns
+// how many accounts had statements closed. It is run by a background worker,
+// so reads never write statements. Accounts with nothing to close are told
+// apart without locking them.
+func (s *statementService) CloseAllDueStatements(ctx context.Context) (int, error) {
+	now := s.now()
+	closed := 0
+
+	var afterID int64
+	for {
+		accounts, err := s.accountRepository.FindAfterId(ctx, afterID, statementCloseBatchSize)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Error reading accounts")
+			return closed, err
+		}
+
+		for i := range accounts {
+			if err = ctx.Err(); err != nil {
+				return closed, err
+			}
+
+			account := &accounts[i]
+			month, _, found, err := nextCycle(ctx, s.repository, s.transactionRepository, account)
+			if err != nil {
+				logging.FromContext(ctx).WithField("accountID", account.ID).WithError(err).Error("Error finding due statements")
+				continue
+			}
+			if !found || !month.Before(account.PeriodAt(now)) {
+				continue
+			}
+
+			if err = s.CloseDueStatements(ctx, account.ID); err == nil {
+				closed++
+			}
+		}
+
+		if len(accounts) < statementCloseBatchSize {
+			return closed, nil
+		}
+		afterID = accounts[len(accounts)-1].ID
+	}
+}
+
+// nextCycle returns the first cycle of the account without a statement, along
+// with its opening balance. found is false when the account has neither
+// statements nor transactions.
+func nextCycle(ctx context.Context, statements repository.StatementRepository, transactions repository.TransactionRepository, account *model.Account) (month time.Time, openingBalance model.Money, found bool, err error) {
+	latest, err := statements.FindLatestByAccountId(ctx, account.ID)
+	switch {
+	case err == nil:
+		latestMonth, err := model.ParsePeriod(latest.Period)
+		if err != nil {
+			return time.Time{}, 0, false, err
+		}
+		return latestMonth.AddDate(0, 1, 0), latest.ClosingBalance, true, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		first, err := transactions.FindByAccountId(ctx, model.TransactionFilter{AccountID: account.ID, Sort: model.SortAscending, Limit: 1})
+		if err != nil || len(first) == 0 {
+			return time.Time{}, 0, false, err
+		}
+		return account.PeriodAt(first[0].TransactionDate), 0, true, nil
+	default:
+		return time.Time{}, 0, false, err
+	}
+}
+
+// ListStatements returns the closed statements of the account, newest first.
+// Cycles are closed by a background worker, so a cycle that just ended is
+// listed once the worker closed it.
+func (s *statementService) ListStatements(ctx context.Context, accountID int64) ([]model.Statement, error) {
+	ctx, span := tracing.Start(ctx, "StatementService.ListStatements", tracing.AccountIDKey.Int64(accountID))
+	defer span.End()
+	logging.AddField(ctx, "accountID", accountID)
+
+	if _, err := s.getAccount(ctx, accountID); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	statements, err := s.repository.FindByAccountId(ctx, accountID)
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error listing statements")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return statements, nil
+}
+
+// GetStatement returns the statement of the given period with its
+// transactions. The current cycle, and an ended one the background worker did
+// not close yet, are returned open, computed on the fly and not stored.
+func (s *statementService) GetStatement(ctx context.Context, accountID int64, period string) (*model.Statement, error) {
+	ctx, span := tracing.Start(ctx, "StatementService.GetStatement", tracing.AccountIDKey.Int64(accountID))
+	defer span.End()
+	logging.AddField(ctx, "accountID", accountID)
+
+	month, err := model.ParsePeriod(period)
+	if err != nil {
+		metrics.ValidationFailed(metrics.StatementService)
+		return nil, internalErrors.NewValidationError(internalErrors.InvalidPeriod, "Invalid period, expected YYYY-MM")
+	}
+
+	statement, err := s.repository.FindByAccountIdAndPeriod(ctx, accountID, period)
+	if err == nil {
+		transactions, installments, err := s.findBilled(ctx, accountID, statement.PeriodStart, statement.PeriodEnd)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		statement.Transactions = model.BilledTransactions(transactions)
+		statement.Installments = installments
+		return statement, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error getting statement")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	current := account.PeriodAt(s.now())
+	if month.After(current) {
+		return nil, internalErrors.NewNotFoundError(internalErrors.StatementNotFound, "Statement not found")
+	}
+	if month.Before(current) {
+		next, _, found, err := nextCycle(ctx, s.repository, s.transactionRepository, account)
+		if err != nil {
+			logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error getting statement")
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		if !found || month.Before(next) {
+			return nil, internalErrors.NewNotFoundError(internalErrors.StatementNotFound, "Statement not found")
+		}
+	}
+
+	openingBalance, err := s.billedBalanceBefore(ctx, accountID, account.CycleStart(month))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	transactions, installments, err := s.findBilled(ctx, accountID, account.CycleStart(month), account.CycleEnd(month))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return model.NewStatement(account, month, openingBalance, transactions, installments), nil
+}
+
+// findBilled returns the transactions posted and the installments due in
+// [from, to).
+func (s *statementService) findBilled(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Transaction, []model.Installment, error) {
+	transactions, err := s.transactionRepository.FindByAccountIdBetween(ctx, accountID, from, to)
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error getting statement transactions")
+		return nil, nil, err
+	}
+
+	installments, err := s.installmentRepository.FindByAccountIdDueBetween(ctx, accountID, from, to)
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error getting statement installments")
+		return nil, nil, err
+	}
+	return transactions, installments, nil
+}
+
+// billedBalanceBefore is what the statements of the account billed before:
+// its transactions other than installment purchases, less the installments
+// that fell due.
+func (s *statementService) billedBalanceBefore(ctx context.Context, accountID int64, before time.Time) (model.Money, error) {
+	billed, err := s.transactionRepository.SumBilledAmountsBefore(ctx, accountID, before)
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error getting statement opening balance")
+		return 0, err
+	}
+
+	due, err := s.installmentRepository.SumDueBefore(ctx, accountID, before)
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error getting statement opening balance")
+		return 0, err
+	}
+	return billed - due, nil
+}
+
+func (s *statementService) getAccount(ctx context.Context, accountID int64) (*model.Account, error) {
+	account, err := s.accountRepository.FindById(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, internalErrors.NewNotFoundError(internalErrors.AccountNotFound, "Account not found")
+		}
+		logging.FromContext(ctx).WithField("accountID", accountID).WithError(err).Error("Error getting account")
+		return nil, err
+	}
+	return account, nil
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newTestStatementService(repository *MockStatementRepository, accountRepository *MockAccountRepository, transactionRepository *MockTransactionRepository, now time.Time) StatementService {
+	installmentRepository := new(MockInstallmentRepository)
+	installmentRepository.On("FindByAccountIdDueBetween", mock.Anything, mock.Anything, mock.Anything).Return([]model.Installment{}, nil)
+	installmentRepository.On("SumDueBefore", mock.Anything, mock.Anything).Return(model.Money(0), nil)
+	return newTestStatementServiceWithInstallments(repository, accountRepository, transactionRepository, installmentRepository, now)
+}
+
+func newTestStatementServiceWithInstallments(repository *MockStatementRepository, accountRepository *MockAccountRepository, transactionRepository *MockTransactionRepository, installmentRepository *MockInstallmentRepository, now time.Time) StatementService {
+	transactor := &MockTransactor{accountRepository, transactionRepository, installmentRepository, repository, new(MockLedgerRepository), new(MockOutboxRepository)}
+	return &statementService{repository, accountRepository, transactionRepository, installmentRepository, transactor, func() time.Time { return now }}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStatementService_CloseDueStatementsFromFirstTransaction(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	account := &model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	purchase := model.Transaction{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-300.00"), TransactionDate: date(2024, time.August, 10)}
+	payment := model.Transaction{ID: 2, AccountID: 1, Amount: model.MustParseMoney("100.00"), TransactionDate: date(2024, time.September, 1)}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("FindByAccountId", model.TransactionFilter{AccountID: 1, Sort: model.SortAscending, Limit: 1}).Return([]model.Transaction{purchase}, nil)
+	mockTransactionRepo.On("FindByAccountIdBetween", int64(1), date(2024, time.July, 26), date(2024, time.August, 26)).Return([]model.Transaction{purchase}, nil)
+	mockTransactionRepo.On("FindByAccountIdBetween", int64(1), date(2024, time.August, 26), date(2024, time.September, 26)).Return([]model.Transaction{payment}, nil)
+
+	var created []*model.Statement
+	mockRepo.On("Create", mock.AnythingOfType("*model.Statement")).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(0).(*model.Statement))
+	}).Return(&model.Statement{}, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 3, 12, 0, 0, 0, time.UTC))
+
+	err := service.CloseDueStatements(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, created, 2)
+
+	assert.Equal(t, "2024-08", created[0].Period)
+	assert.Equal(t, model.Money(0), created[0].OpeningBalance)
+	assert.Equal(t, model.MustParseMoney("300.00"), created[0].TotalDebits)
+	assert.Equal(t, model.MustParseMoney("-300.00"), created[0].ClosingBalance)
+	assert.Equal(t, model.MustParseMoney("45.00"), created[0].MinimumPayment)
+	assert.Equal(t, date(2024, time.September, 5), created[0].DueDate)
+	assert.True(t, created[0].IsClosed())
+
+	assert.Equal(t, "2024-09", created[1].Period)
+	assert.Equal(t, model.MustParseMoney("-300.00"), created[1].OpeningBalance)
+	assert.Equal(t, model.MustParseMoney("100.00"), created[1].TotalCredits)
+	assert.Equal(t, model.MustParseMoney("-200.00"), created[1].ClosingBalance)
+
+	mockRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestStatementService_CloseDueStatementsBillsInstallments(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+	mockInstallmentRepo := new(MockInstallmentRepository)
+
+	account := &model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	purchase := model.Transaction{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-300.00"), OperationType: model.InstallmentPurchase, TransactionDate: date(2024, time.September, 10)}
+	installments := model.NewInstallments(&purchase, 3)
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("FindByAccountId", model.TransactionFilter{AccountID: 1, Sort: model.SortAscending, Limit: 1}).Return([]model.Transaction{purchase}, nil)
+	mockTransactionRepo.On("FindByAccountIdBetween", int64(1), date(2024, time.August, 26), date(2024, time.September, 26)).Return([]model.Transaction{purchase}, nil)
+	mockInstallmentRepo.On("FindByAccountIdDueBetween", int64(1), date(2024, time.August, 26), date(2024, time.September, 26)).Return([]model.Installment{}, nil)
+	for i, month := range []time.Month{time.September, time.October, time.November} {
+		mockTransactionRepo.On("FindByAccountIdBetween", int64(1), date(2024, month, 26), date(2024, month+1, 26)).Return([]model.Transaction{}, nil)
+		mockInstallmentRepo.On("FindByAccountIdDueBetween", int64(1), date(2024, month, 26), date(2024, month+1, 26)).Return(installments[i:i+1], nil)
+	}
+
+	var created []*model.Statement
+	mockRepo.On("Create", mock.AnythingOfType("*model.Statement")).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(0).(*model.Statement))
+	}).Return(&model.Statement{}, nil)
+
+	service := newTestStatementServiceWithInstallments(mockRepo, mockAccountRepo, mockTransactionRepo, mockInstallmentRepo, time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC))
+
+	err := service.CloseDueStatements(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, created, 4)
+
+	assert.Equal(t, "2024-09", created[0].Period)
+	assert.Zero(t, created[0].TotalDebits)
+	assert.Zero(t, created[0].MinimumPayment)
+
+	for i, period := range []string{"2024-10", "2024-11", "2024-12"} {
+		assert.Equal(t, period, created[i+1].Period)
+		assert.Equal(t, model.MustParseMoney("100.00"), created[i+1].TotalDebits, period)
+	}
+	assert.Equal(t, model.MustParseMoney("-100.00"), created[1].ClosingBalance)
+	assert.Equal(t, model.MustParseMoney("15.00"), created[1].MinimumPayment)
+	assert.Equal(t, model.MustParseMoney("-300.00"), created[3].ClosingBalance)
+
+	mockTransactionRepo.AssertExpectations(t)
+	mockInstallmentRepo.AssertExpectations(t)
+}
+
+func TestStatementService_CloseDueStatementsAfterLatest(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	account := &model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	latest := &model.Statement{AccountID: 1, Period: "2024-09", ClosingBalance: model.MustParseMoney("-200.00")}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(latest, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 25, 23, 59, 0, 0, time.UTC))
+
+	err := service.CloseDueStatements(context.Background(), 1)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockTransactionRepo.AssertNotCalled(t, "FindByAccountIdBetween", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStatementService_CloseDueStatementsWithoutTransactions(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(&model.Account{ID: 1, ClosingDay: 25, DueDay: 5}, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("FindByAccountId", mock.Anything).Return([]model.Transaction{}, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC))
+
+	err := service.CloseDueStatements(context.Background(), 1)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestStatementService_ListStatementsAccountNotFoundError(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	mockAccountRepo.On("FindById", int64(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Now())
+
+	_, err := service.ListStatements(context.Background(), 2)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+	mockRepo.AssertNotCalled(t, "FindByAccountId", mock.Anything)
+}
+
+func TestStatementService_ListStatementsDoesNotClose(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	statements := []model.Statement{{AccountID: 1, Period: "2024-09"}}
+
+	mockAccountRepo.On("FindById", int64(1)).Return(&model.Account{ID: 1, ClosingDay: 25, DueDay: 5}, nil)
+	mockRepo.On("FindByAccountId", int64(1)).Return(statements, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.December, 3, 0, 0, 0, 0, time.UTC))
+
+	found, err := service.ListStatements(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, statements, found)
+	mockAccountRepo.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestStatementService_GetStatementClosed(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	closedAt := date(2024, time.September, 26)
+	statement := &model.Statement{
+		AccountID:   1,
+		Period:      "2024-09",
+		PeriodStart: date(2024, time.August, 26),
+		PeriodEnd:   date(2024, time.September, 26),
+		ClosedAt:    &closedAt,
+	}
+	transactions := []model.Transaction{{ID: 2, AccountID: 1, Amount: model.MustParseMoney("100.00")}}
+
+	mockRepo.On("FindByAccountIdAndPeriod", int64(1), "2024-09").Return(statement, nil)
+	mockTransactionRepo.On("FindByAccountIdBetween", int64(1), statement.PeriodStart, statement.PeriodEnd).Return(transactions, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC))
+
+	found, err := service.GetStatement(context.Background(), 1, "2024-09")
+
+	assert.NoError(t, err)
+	assert.True(t, found.IsClosed())
+	assert.Equal(t, transactions, found.Transactions)
+
+	mockRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestStatementService_GetStatementCurrentCycle(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	account := &model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	latest := &model.Statement{AccountID: 1, Period: "2024-09", ClosingBalance: model.MustParseMoney("-200.00")}
+	transactions := []model.Transaction{{ID: 3, AccountID: 1, Amount: model.MustParseMoney("-50.00")}}
+
+	mockAccountRepo.On("FindById", int64(1)).Return(account, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(latest, nil)
+	mockRepo.On("FindByAccountIdAndPeriod", int64(1), mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("SumBilledAmountsBefore", int64(1), date(2024, time.September, 26)).Return(model.MustParseMoney("-200.00"), nil)
+	mockTransactionRepo.On("FindByAccountIdBetween", int64(1), date(2024, time.September, 26), date(2024, time.October, 26)).Return(transactions, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC))
+
+	statement, err := service.GetStatement(context.Background(), 1, "2024-10")
+
+	assert.NoError(t, err)
+	assert.False(t, statement.IsClosed())
+	assert.Equal(t, model.MustParseMoney("-250.00"), statement.ClosingBalance)
+	assert.Equal(t, transactions, statement.Transactions)
+
+	_, err = service.GetStatement(context.Background(), 1, "2024-11")
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+
+	_, err = service.GetStatement(context.Background(), 1, "2024-08")
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockAccountRepo.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything)
+}
+
+func TestStatementService_GetStatementEndedCycleNotClosedYet(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	account := &model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	latest := &model.Statement{AccountID: 1, Period: "2024-08", ClosingBalance: model.MustParseMoney("-300.00")}
+	transactions := []model.Transaction{{ID: 2, AccountID: 1, Amount: model.MustParseMoney("100.00")}}
+
+	mockAccountRepo.On("FindById", int64(1)).Return(account, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(latest, nil)
+	mockRepo.On("FindByAccountIdAndPeriod", int64(1), "2024-09").Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("SumBilledAmountsBefore", int64(1), date(2024, time.August, 26)).Return(model.MustParseMoney("-300.00"), nil)
+	mockTransactionRepo.On("FindByAccountIdBetween", int64(1), date(2024, time.August, 26), date(2024, time.September, 26)).Return(transactions, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC))
+
+	statement, err := service.GetStatement(context.Background(), 1, "2024-09")
+
+	assert.NoError(t, err)
+	assert.False(t, statement.IsClosed())
+	assert.Equal(t, model.MustParseMoney("-200.00"), statement.ClosingBalance)
+	assert.Equal(t, transactions, statement.Transactions)
+	mockAccountRepo.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestStatementService_GetStatementInvalidPeriodError(t *testing.T) {
+	service := newTestStatementService(new(MockStatementRepository), new(MockAccountRepository), new(MockTransactionRepository), time.Now())
+
+	_, err := service.GetStatement(context.Background(), 1, "2024-9")
+
+	assert.ErrorAs(t, err, &internalErrors.ValidationError{})
+}
+
+func TestStatementService_GetStatementError(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	mockRepo.On("FindByAccountIdAndPeriod", int64(1), "2024-09").Return(nil, errors.New("db error"))
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Now())
+
+	_, err := service.GetStatement(context.Background(), 1, "2024-09")
+
+	assert.EqualError(t, err, "db error")
+}
+
+func TestStatementService_CloseAllDueStatements(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	due := model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	upToDate := model.Account{ID: 2, ClosingDay: 25, DueDay: 5}
+	empty := model.Account{ID: 3, ClosingDay: 25, DueDay: 5}
+	purchase := model.Transaction{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-300.00"), TransactionDate: date(2024, time.September, 10)}
+
+	mockAccountRepo.On("FindAfterId", int64(0), statementCloseBatchSize).Return([]model.Account{due, upToDate, empty}, nil)
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(&due, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindLatestByAccountId", int64(2)).Return(&model.Statement{AccountID: 2, Period: "2024-09"}, nil)
+	mockRepo.On("FindLatestByAccountId", int64(3)).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("FindByAccountId", model.TransactionFilter{AccountID: 1, Sort: model.SortAscending, Limit: 1}).Return([]model.Transaction{purchase}, nil)
+	mockTransactionRepo.On("FindByAccountId", model.TransactionFilter{AccountID: 3, Sort: model.SortAscending, Limit: 1}).Return([]model.Transaction{}, nil)
+	mockTransactionRepo.On("FindByAccountIdBetween", int64(1), date(2024, time.August, 26), date(2024, time.September, 26)).Return([]model.Transaction{purchase}, nil)
+
+	var created []*model.Statement
+	mockRepo.On("Create", mock.AnythingOfType("*model.Statement")).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(0).(*model.Statement))
+	}).Return(&model.Statement{}, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC))
+
+	closed, err := service.CloseAllDueStatements(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, closed)
+	assert.Len(t, created, 1)
+	assert.Equal(t, "2024-09", created[0].Period)
+	mockAccountRepo.AssertNotCalled(t, "FindByIdForUpdate", int64(2))
+	mockAccountRepo.AssertNotCalled(t, "FindByIdForUpdate", int64(3))
+}
+
+func TestStatementService_CloseAllDueStatementsContinuesAfterError(t *testing.T) {
+	mockRepo := new(MockStatementRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+
+	failing := model.Account{ID: 1, ClosingDay: 25, DueDay: 5}
+	upToDate := model.Account{ID: 2, ClosingDay: 25, DueDay: 5}
+
+	mockAccountRepo.On("FindAfterId", int64(0), statementCloseBatchSize).Return([]model.Account{failing, upToDate}, nil)
+	mockRepo.On("FindLatestByAccountId", int64(1)).Return(nil, errors.New("db error"))
+	mockRepo.On("FindLatestByAccountId", int64(2)).Return(&model.Statement{AccountID: 2, Period: "2024-09"}, nil)
+
+	service := newTestStatementService(mockRepo, mockAccountRepo, mockTransactionRepo, time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC))
+
+	closed, err := service.CloseAllDueStatements(context.Background())
+
+	assert.NoError(t, err)
+	assert.Zero(t, closed)
+	mockRepo.AssertExpectations(t)
+}
@@ -1,22 +1,322 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/ledger"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/metrics"
 	"github.com/gmerten/accounts_transactions/internal/model"
 	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"gorm.io/gorm"
 )
 
 type transactionService struct {
 	repository repository.TransactionRepository
+	transactor repository.Transactor
 }
 
 type TransactionService interface {
-	CreateTransaction(transaction *model.Transaction) (*model.Transaction, error)
+	CreateTransaction(ctx context.Context, transaction *model.Transaction, installmentCount int) (*model.Transaction, error)
+	ListTransactions(ctx context.Context, filter model.TransactionFilter) (*model.TransactionPage, error)
+	ReverseTransaction(ctx context.Context, transactionID int64, amount *model.Money) (*model.Transaction, *model.Transaction, error)
+}
+
+func NewTransactionService(repository repository.TransactionRepository, transactor repository.Transactor) TransactionService {
+	return &transactionService{repository, transactor}
+}
+
+// CreateTransaction saves the transaction and its ledger journal and applies its
+// amount to the account balance and available credit limit in the same database
+// transaction, with the account row locked. Debits beyond the available credit
+// limit are rejected, and credits first discharge the account's open debits,
+// oldest first. Installment purchases get a plan of installmentCount monthly
+// installments, a single one when it is zero.
+func (t *transactionService) CreateTransaction(ctx context.Context, transaction *model.Transaction, installmentCount int) (*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.CreateTransaction",
+		tracing.AccountIDKey.Int64(transaction.AccountID),
+		tracing.OperationTypeKey.Int(int(transaction.OperationType)),
+	)
+	defer span.End()
+	logging.AddField(ctx, "accountID", transaction.AccountID)
+
+	if transaction.OperationType == model.InstallmentPurchase {
+		installmentCount = max(installmentCount, 1)
+	} else if installmentCount > 1 {
+		metrics.ValidationFailed(metrics.TransactionService)
+		return nil, internalErrors.NewValidationError(internalErrors.InvalidInstallments, "Only installment purchases can be split in installments")
+	}
+
+	if installmentCount > model.MaxInstallments {
+		metrics.ValidationFailed(metrics.TransactionService)
+		return nil, internalErrors.NewValidationError(internalErrors.InvalidInstallments, "Installment purchases can have at most "+strconv.Itoa(model.MaxInstallments)+" installments")
+	}
+	if transaction.Amount.Abs() < model.Money(installmentCount) {
+		metrics.ValidationFailed(metrics.TransactionService)
+		return nil, internalErrors.NewValidationError(internalErrors.InvalidInstallments, "Amount is too small for "+strconv.Itoa(installmentCount)+" installments")
+	}
+
+	err := t.transactor.WithinTransaction(ctx, func(repositories repository.Repositories) error {
+		account, err := lockActiveAccount(ctx, repositories.Accounts, transaction.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.AvailableCreditLimit != nil && *account.AvailableCreditLimit+transaction.Amount < 0 {
+			return internalErrors.NewCreditLimitExceededError("Transaction exceeds the available credit limit")
+		}
+
+		transaction.Balance = transaction.Amount
+		if err = post(ctx, repositories, account, transaction); err != nil {
+			return err
+		}
+
+		if transaction.OperationType != model.InstallmentPurchase {
+			return nil
+		}
+		return repositories.Installments.Create(ctx, model.NewInstallments(transaction, installmentCount))
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", transaction.AccountID).WithError(err).Error("Error saving transaction")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(tracing.TransactionIDKey.Int64(transaction.ID))
+	metrics.TransactionPosted(transaction.OperationType)
+	return transaction, nil
+}
+
+// ListTransactions returns one page of the account transactions matching the
+// filter, along with the cursor of the next page when there is one.
+func (t *transactionService) ListTransactions(ctx context.Context, filter model.TransactionFilter) (*model.TransactionPage, error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.ListTransactions", tracing.AccountIDKey.Int64(filter.AccountID))
+	defer span.End()
+	logging.AddField(ctx, "accountID", filter.AccountID)
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	transactions, err := t.repository.FindByAccountId(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx).WithField("accountID", filter.AccountID).WithError(err).Error("Error listing transactions")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	page := &model.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.Next = &model.TransactionCursor{TransactionDate: last.TransactionDate, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// ReverseTransaction posts a compensating transaction for the given amount of
+// the original one, or for all of what is still unreversed when amount is nil.
+// The compensation settles the original's own balance first and then flows
+// like any other posting. It returns the compensating and the updated original
+// transactions.
+func (t *transactionService) ReverseTransaction(ctx context.Context, transactionID int64, amount *model.Money) (*model.Transaction, *model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.ReverseTransaction", tracing.TransactionIDKey.Int64(transactionID))
+	defer span.End()
+
+	var reversal, original *model.Transaction
+
+	err := t.transactor.WithinTransaction(ctx, func(repositories repository.Repositories) error {
+		transaction, err := repositories.Transactions.FindById(ctx, transactionID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return internalErrors.NewNotFoundError(internalErrors.TransactionNotFound, "Transaction not found")
+			}
+			return err
+		}
+		logging.AddField(ctx, "accountID", transaction.AccountID)
+
+		account, err := lockActiveAccount(ctx, repositories.Accounts, transaction.AccountID)
+		if err != nil {
+			return err
+		}
+
+		original, err = repositories.Transactions.FindByIdForUpdate(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOfID != nil {
+			return internalErrors.NewUnprocessableEntityError(internalErrors.ReversalNotAllowed, "A reversal cannot be reversed")
+		}
+
+		unreversed := original.UnreversedAmount()
+		if unreversed <= 0 {
+			return internalErrors.NewUnprocessableEntityError(internalErrors.TransactionAlreadyReversed, "Transaction is already reversed")
+		}
+
+		portion := unreversed
+		if amount != nil {
+			portion = *amount
+		}
+		if portion <= 0 || portion > unreversed {
+			return internalErrors.NewUnprocessableEntityError(internalErrors.InvalidReversalAmount, "Reversal amount must be positive and at most the unreversed amount of "+unreversed.String())
+		}
+
+		reversal = &model.Transaction{
+			OperationType:   original.OperationType,
+			AccountID:       original.AccountID,
+			ReversalOfID:    &original.ID,
+			TransactionDate: time.Now(),
+		}
+		if original.Amount < 0 {
+			reversal.Amount = portion
+			settled := min(portion, -original.Balance)
+			original.Balance += settled
+			reversal.Balance = portion - settled
+		} else {
+			reversal.Amount = -portion
+			settled := min(portion, original.Balance)
+			original.Balance -= settled
+			reversal.Balance = -(portion - settled)
+		}
+
+		original.ReversedAmount += portion
+		original.Status = model.PartiallyReversed
+		if original.UnreversedAmount() == 0 {
+			original.Status = model.Reversed
+		}
+
+		if err = repositories.Transactions.UpdateBalance(ctx, original.ID, original.Balance); err != nil {
+			return err
+		}
+		if err = repositories.Transactions.UpdateReversal(ctx, original.ID, original.ReversedAmount, original.Status); err != nil {
+			return err
+		}
+
+		return post(ctx, repositories, account, reversal)
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).WithField("transactionID", transactionID).WithError(err).Error("Error reversing transaction")
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+
+	metrics.TransactionReversed(original.OperationType)
+	return reversal, original, nil
+}
+
+// lockActiveAccount is like lockAccount but rejects accounts that cannot take
+// postings.
+func lockActiveAccount(ctx context.Context, accounts repository.AccountRepository, accountID int64) (*model.Account, error) {
+	account, err := lockAccount(ctx, accounts, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive() {
+		return nil, internalErrors.NewAccountNotActiveError("Account is " + string(account.Status))
+	}
+	return account, nil
 }
 
-func NewTransactionService(repository repository.TransactionRepository) TransactionService {
-	return &transactionService{repository}
+func lockAccount(ctx context.Context, accounts repository.AccountRepository, accountID int64) (*model.Account, error) {
+	account, err := accounts.FindByIdForUpdate(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, internalErrors.NewNotFoundError(internalErrors.AccountNotFound, "Account not found")
+		}
+		return nil, err
+	}
+	return account, nil
 }
 
-func (t *transactionService) CreateTransaction(transaction *model.Transaction) (*model.Transaction, error) {
-	return t.repository.Create(transaction)
+// post saves a transaction whose Balance is already set against the locked
+// account, along with its ledger journal and TransactionPosted event. A
+// positive balance discharges open debits before the transaction is saved, and
+// the amount is then applied to the account.
+func post(ctx context.Context, repositories repository.Repositories, account *model.Account, transaction *model.Transaction) error {
+	if transaction.Status == "" {
+		transaction.Status = model.Posted
+	}
+
+	if transaction.Balance > 0 {
+		if err := discharge(ctx, repositories.Transactions, transaction); err != nil {
+			return err
+		}
+	}
+
+	if account.AvailableCreditLimit != nil {
+		availableCreditLimit := *account.AvailableCreditLimit + creditLimitChange(transaction)
+		if err := repositories.Accounts.UpdateAvailableCreditLimit(ctx, account.ID, availableCreditLimit); err != nil {
+			return err
+		}
+	}
+
+	if _, err := repositories.Transactions.Create(ctx, transaction); err != nil {
+		// The account row is locked, so only the operation type can be missing.
+		if internalErrors.IsForeignKeyError(err) {
+			metrics.ValidationFailed(metrics.TransactionService)
+			return internalErrors.NewValidationError(internalErrors.InvalidOperationType, "Invalid operation type")
+		}
+		return err
+	}
+
+	journal := ledger.NewTransactionJournal(transaction)
+	if err := journal.Validate(); err != nil {
+		return err
+	}
+	if err := repositories.Ledger.CreateJournal(ctx, journal); err != nil {
+		return err
+	}
+
+	message, err := model.NewOutboxMessage(model.NewTransactionPosted(transaction), transaction.TransactionDate)
+	if err != nil {
+		return err
+	}
+	if err = repositories.Outbox.Append(ctx, message); err != nil {
+		return err
+	}
+
+	return repositories.Accounts.UpdateBalance(ctx, account.ID, account.Balance+transaction.Amount)
+}
+
+// creditLimitChange is how much a discharged transaction moves the available
+// credit limit. Debits use up what they leave open, and credits give back only
+// the debits they paid off, so paying more than is owed does not raise the
+// limit above the one granted.
+func creditLimitChange(transaction *model.Transaction) model.Money {
+	if transaction.Amount > 0 {
+		return transaction.Amount - transaction.Balance
+	}
+	return transaction.Balance
+}
+
+// discharge pays off the open debits of the credit's account in FIFO order.
+// Whatever is left of the credit remains as its positive balance.
+func discharge(ctx context.Context, transactions repository.TransactionRepository, credit *model.Transaction) error {
+	debits, err := transactions.FindOpenDebitsForUpdate(ctx, credit.AccountID)
+	if err != nil {
+		return err
+	}
+
+	for _, debit := range debits {
+		if credit.Balance <= 0 {
+			break
+		}
+
+		settled := min(credit.Balance, -debit.Balance)
+		credit.Balance -= settled
+
+		if err = transactions.UpdateBalance(ctx, debit.ID, debit.Balance+settled); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -1,51 +1,876 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/ledger"
 	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestTransactionService_CreateTransaction(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
 	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Balance:        model.MustParseMoney("50.00"),
+		Status:         model.AccountActive,
+	}
 
 	transaction := &model.Transaction{
 		ID:            1,
 		AccountID:     1,
-		Amount:        100.0,
+		Amount:        model.MustParseMoney("-100.00"),
 		OperationType: 1,
 	}
 
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
 	mockRepo.On("Create", transaction).Return(transaction, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-50.00")).Return(nil)
+
+	var journal *ledger.Journal
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Run(func(args mock.Arguments) {
+		journal = args.Get(0).(*ledger.Journal)
+	}).Return(nil)
 
-	service := NewTransactionService(mockRepo)
+	var message *model.OutboxMessage
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Run(func(args mock.Arguments) {
+		message = args.Get(0).(*model.OutboxMessage)
+	}).Return(nil)
 
-	createdTransaction, err := service.CreateTransaction(transaction)
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	createdTransaction, err := service.CreateTransaction(context.Background(), transaction, 0)
 
 	assert.NoError(t, err)
 	assert.Equal(t, transaction, createdTransaction)
+	assert.Equal(t, model.MustParseMoney("-100.00"), createdTransaction.Balance)
+	assert.Equal(t, int64(1), journal.TransactionID)
+	assert.Equal(t, []ledger.Entry{
+		{LedgerAccount: ledger.CustomerAccount(1), Amount: model.MustParseMoney("100.00")},
+		{LedgerAccount: ledger.MerchantSettlement, Amount: model.MustParseMoney("-100.00")},
+	}, journal.Entries)
+	assert.Equal(t, model.TransactionPostedEvent, message.EventType)
+	assert.Equal(t, int64(1), message.AccountID)
 
 	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
 }
 
 func TestTransactionService_CreateTransactionError(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
 	mockRepo := new(MockTransactionRepository)
 
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
+	}
+
 	transaction := &model.Transaction{
 		ID:            1,
 		AccountID:     1,
-		Amount:        100.0,
+		Amount:        model.MustParseMoney("-100.00"),
 		OperationType: 1,
 	}
 
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
 	mockRepo.On("Create", transaction).Return(nil, errors.New("error creating transaction"))
 
-	service := NewTransactionService(mockRepo)
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 0)
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionUnknownOperationTypeError(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+
+	account := &model.Account{ID: 1, Status: model.AccountActive}
+	transaction := &model.Transaction{AccountID: 1, Amount: model.MustParseMoney("-100.00"), OperationType: 99}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("Create", transaction).Return(nil, &pgconn.PgError{Code: "23503"})
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 0)
+	assert.ErrorAs(t, err, &internalErrors.ValidationError{})
+	assert.EqualError(t, err, "Invalid operation type")
+}
+
+func TestTransactionService_CreateTransactionJournalError(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+
+	account := &model.Account{ID: 1, Status: model.AccountActive}
+
+	transaction := &model.Transaction{
+		ID:            1,
+		AccountID:     1,
+		Amount:        model.MustParseMoney("-100.00"),
+		OperationType: 1,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("Create", transaction).Return(transaction, nil)
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(errors.New("error creating journal"))
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, new(MockOutboxRepository)})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 0)
+	assert.EqualError(t, err, "error creating journal")
+
+	mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_CreateTransactionAccountNotFoundError(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+
+	transaction := &model.Transaction{
+		AccountID:     2,
+		Amount:        model.MustParseMoney("-100.00"),
+		OperationType: 1,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 0)
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+
+	mockAccountRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", transaction)
+}
+
+func TestTransactionService_CreateTransactionPartialPayment(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Balance:        model.MustParseMoney("-73.80"),
+		Status:         model.AccountActive,
+	}
+
+	openDebits := []model.Transaction{
+		{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-50.00"), Balance: model.MustParseMoney("-50.00"), OperationType: model.Purchase},
+		{ID: 2, AccountID: 1, Amount: model.MustParseMoney("-23.50"), Balance: model.MustParseMoney("-23.50"), OperationType: model.Purchase},
+		{ID: 3, AccountID: 1, Amount: model.MustParseMoney("-18.70"), Balance: model.MustParseMoney("-0.30"), OperationType: model.Withdrawal},
+	}
+
+	payment := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("60.00"),
+		OperationType: model.Payment,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindOpenDebitsForUpdate", int64(1)).Return(openDebits, nil)
+	mockRepo.On("UpdateBalance", int64(1), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("UpdateBalance", int64(2), model.MustParseMoney("-13.50")).Return(nil)
+	mockRepo.On("Create", payment).Return(payment, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-13.80")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	createdTransaction, err := service.CreateTransaction(context.Background(), payment, 0)
+
+	assert.NoError(t, err)
+	assert.Zero(t, createdTransaction.Balance)
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateBalance", int64(3), mock.Anything)
+}
+
+func TestTransactionService_CreateTransactionOverPayment(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Balance:        model.MustParseMoney("-70.00"),
+		Status:         model.AccountActive,
+	}
+
+	openDebits := []model.Transaction{
+		{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-50.00"), Balance: model.MustParseMoney("-50.00"), OperationType: model.Purchase},
+		{ID: 2, AccountID: 1, Amount: model.MustParseMoney("-20.00"), Balance: model.MustParseMoney("-20.00"), OperationType: model.Withdrawal},
+	}
+
+	payment := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("100.00"),
+		OperationType: model.Payment,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindOpenDebitsForUpdate", int64(1)).Return(openDebits, nil)
+	mockRepo.On("UpdateBalance", int64(1), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("UpdateBalance", int64(2), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("Create", payment).Return(payment, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("30.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	createdTransaction, err := service.CreateTransaction(context.Background(), payment, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("30.00"), createdTransaction.Balance)
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionPaymentWithoutDebits(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
+	}
+
+	payment := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("100.00"),
+		OperationType: model.Payment,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindOpenDebitsForUpdate", int64(1)).Return([]model.Transaction{}, nil)
+	mockRepo.On("Create", payment).Return(payment, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("100.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	createdTransaction, err := service.CreateTransaction(context.Background(), payment, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("100.00"), createdTransaction.Balance)
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionDischargeError(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+
+	account := &model.Account{
+		ID:             1,
+		DocumentNumber: "12345678",
+		Status:         model.AccountActive,
+	}
+
+	payment := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("100.00"),
+		OperationType: model.Payment,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindOpenDebitsForUpdate", int64(1)).Return(nil, errors.New("error finding debits"))
 
-	_, err := service.CreateTransaction(transaction)
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, err := service.CreateTransaction(context.Background(), payment, 0)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", payment)
+}
+
+func TestTransactionService_ListTransactions(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	now := time.Now()
+	transactions := []model.Transaction{
+		{ID: 3, AccountID: 1, TransactionDate: now},
+		{ID: 2, AccountID: 1, TransactionDate: now.Add(-time.Minute)},
+		{ID: 1, AccountID: 1, TransactionDate: now.Add(-time.Hour)},
+	}
+
+	mockRepo.On("FindByAccountId", model.TransactionFilter{AccountID: 1, Limit: 3}).Return(transactions, nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{new(MockAccountRepository), mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	page, err := service.ListTransactions(context.Background(), model.TransactionFilter{AccountID: 1, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, transactions[:2], page.Transactions)
+	assert.Equal(t, &model.TransactionCursor{TransactionDate: transactions[1].TransactionDate, ID: 2}, page.Next)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ListTransactionsLastPage(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	transactions := []model.Transaction{
+		{ID: 1, AccountID: 1, TransactionDate: time.Now()},
+	}
+
+	mockRepo.On("FindByAccountId", model.TransactionFilter{AccountID: 1, Limit: 3}).Return(transactions, nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{new(MockAccountRepository), mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	page, err := service.ListTransactions(context.Background(), model.TransactionFilter{AccountID: 1, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, transactions, page.Transactions)
+	assert.Nil(t, page.Next)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ListTransactionsError(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	mockRepo.On("FindByAccountId", mock.Anything).Return(nil, errors.New("error listing transactions"))
+
+	service := NewTransactionService(mockRepo, &MockTransactor{new(MockAccountRepository), mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, err := service.ListTransactions(context.Background(), model.TransactionFilter{AccountID: 1, Limit: 2})
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionWithinCreditLimit(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	availableCreditLimit := model.MustParseMoney("100.00")
+	account := &model.Account{
+		ID:                   1,
+		DocumentNumber:       "12345678",
+		AvailableCreditLimit: &availableCreditLimit,
+		Status:               model.AccountActive,
+	}
+
+	transaction := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("-100.00"),
+		OperationType: model.Purchase,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockAccountRepo.On("UpdateAvailableCreditLimit", int64(1), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("Create", transaction).Return(transaction, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-100.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 0)
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionCreditLimitExceeded(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+
+	availableCreditLimit := model.MustParseMoney("100.00")
+	account := &model.Account{
+		ID:                   1,
+		DocumentNumber:       "12345678",
+		AvailableCreditLimit: &availableCreditLimit,
+		Status:               model.AccountActive,
+	}
+
+	transaction := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("-100.01"),
+		OperationType: model.Withdrawal,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 0)
+
+	assert.ErrorAs(t, err, &internalErrors.CreditLimitExceededError{})
+
+	mockAccountRepo.AssertExpectations(t)
+	mockAccountRepo.AssertNotCalled(t, "UpdateAvailableCreditLimit", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", transaction)
+}
+
+func TestTransactionService_CreateTransactionPaymentRestoresCreditLimit(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	availableCreditLimit := model.MustParseMoney("20.00")
+	account := &model.Account{
+		ID:                   1,
+		DocumentNumber:       "12345678",
+		Balance:              model.MustParseMoney("-80.00"),
+		AvailableCreditLimit: &availableCreditLimit,
+		Status:               model.AccountActive,
+	}
+
+	openDebits := []model.Transaction{
+		{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-80.00"), Balance: model.MustParseMoney("-80.00"), OperationType: model.Purchase},
+	}
+
+	payment := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("50.00"),
+		OperationType: model.Payment,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockAccountRepo.On("UpdateAvailableCreditLimit", int64(1), model.MustParseMoney("70.00")).Return(nil)
+	mockRepo.On("FindOpenDebitsForUpdate", int64(1)).Return(openDebits, nil)
+	mockRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-30.00")).Return(nil)
+	mockRepo.On("Create", payment).Return(payment, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-30.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	_, err := service.CreateTransaction(context.Background(), payment, 0)
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionOverPaymentCapsCreditLimit(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	// The granted limit is 100.00, of which 80.00 is owed.
+	availableCreditLimit := model.MustParseMoney("20.00")
+	account := &model.Account{
+		ID:                   1,
+		DocumentNumber:       "12345678",
+		Balance:              model.MustParseMoney("-80.00"),
+		AvailableCreditLimit: &availableCreditLimit,
+		Status:               model.AccountActive,
+	}
+
+	openDebits := []model.Transaction{
+		{ID: 1, AccountID: 1, Amount: model.MustParseMoney("-80.00"), Balance: model.MustParseMoney("-80.00"), OperationType: model.Purchase},
+	}
+
+	payment := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("10000.00"),
+		OperationType: model.Payment,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockAccountRepo.On("UpdateAvailableCreditLimit", int64(1), model.MustParseMoney("100.00")).Return(nil)
+	mockRepo.On("FindOpenDebitsForUpdate", int64(1)).Return(openDebits, nil)
+	mockRepo.On("UpdateBalance", int64(1), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("Create", payment).Return(payment, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("9920.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	createdTransaction, err := service.CreateTransaction(context.Background(), payment, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("9920.00"), createdTransaction.Balance)
+
+	mockRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionPaymentWithoutDebitsKeepsCreditLimit(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	availableCreditLimit := model.MustParseMoney("500.00")
+	account := &model.Account{
+		ID:                   1,
+		DocumentNumber:       "12345678",
+		AvailableCreditLimit: &availableCreditLimit,
+		Status:               model.AccountActive,
+	}
+
+	payment := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("10000.00"),
+		OperationType: model.Payment,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockAccountRepo.On("UpdateAvailableCreditLimit", int64(1), model.MustParseMoney("500.00")).Return(nil)
+	mockRepo.On("FindOpenDebitsForUpdate", int64(1)).Return([]model.Transaction{}, nil)
+	mockRepo.On("Create", payment).Return(payment, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("10000.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	_, err := service.CreateTransaction(context.Background(), payment, 0)
+
+	assert.NoError(t, err)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ReversePaymentReopensOnlyDischargedCredit(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	// The payment of 100.00 paid off a debit of 30.00 and kept 70.00 unused,
+	// so reversing it takes back only the 30.00 of credit it gave.
+	limit := model.MustParseMoney("100.00")
+	account := &model.Account{ID: 1, Status: model.AccountActive, Balance: model.MustParseMoney("70.00"), AvailableCreditLimit: &limit}
+
+	original := &model.Transaction{
+		ID:            7,
+		AccountID:     1,
+		Amount:        model.MustParseMoney("100.00"),
+		Balance:       model.MustParseMoney("70.00"),
+		OperationType: model.Payment,
+		Status:        model.Posted,
+	}
+
+	mockRepo.On("FindById", int64(7)).Return(original, nil)
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindByIdForUpdate", int64(7)).Return(original, nil)
+	mockRepo.On("UpdateBalance", int64(7), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("UpdateReversal", int64(7), model.MustParseMoney("100.00"), model.Reversed).Return(nil)
+	mockAccountRepo.On("UpdateAvailableCreditLimit", int64(1), model.MustParseMoney("70.00")).Return(nil)
+	mockRepo.On("Create", mock.AnythingOfType("*model.Transaction")).Return(&model.Transaction{}, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-30.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	reversal, _, err := service.ReverseTransaction(context.Background(), 7, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("-30.00"), reversal.Balance)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ReverseTransaction(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	limit := model.MustParseMoney("900.00")
+	account := &model.Account{ID: 1, Status: model.AccountActive, Balance: model.MustParseMoney("-100.00"), AvailableCreditLimit: &limit}
+
+	original := &model.Transaction{
+		ID:            7,
+		AccountID:     1,
+		Amount:        model.MustParseMoney("-100.00"),
+		Balance:       model.MustParseMoney("-100.00"),
+		OperationType: model.Purchase,
+		Status:        model.Posted,
+	}
+
+	mockRepo.On("FindById", int64(7)).Return(original, nil)
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindByIdForUpdate", int64(7)).Return(original, nil)
+	mockRepo.On("UpdateBalance", int64(7), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("UpdateReversal", int64(7), model.MustParseMoney("100.00"), model.Reversed).Return(nil)
+	mockAccountRepo.On("UpdateAvailableCreditLimit", int64(1), model.MustParseMoney("1000.00")).Return(nil)
+	mockRepo.On("Create", mock.AnythingOfType("*model.Transaction")).Return(&model.Transaction{}, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("0.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	reversal, reversed, err := service.ReverseTransaction(context.Background(), 7, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("100.00"), reversal.Amount)
+	assert.Zero(t, reversal.Balance)
+	assert.Equal(t, model.Posted, reversal.Status)
+	assert.Equal(t, int64(7), *reversal.ReversalOfID)
+	assert.Equal(t, model.Purchase, reversal.OperationType)
+	assert.Equal(t, model.Reversed, reversed.Status)
+	assert.Equal(t, model.MustParseMoney("100.00"), reversed.ReversedAmount)
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "FindOpenDebitsForUpdate", mock.Anything)
+}
+
+func TestTransactionService_ReverseTransactionPartial(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	account := &model.Account{ID: 1, Status: model.AccountActive, Balance: model.MustParseMoney("40.00")}
+
+	original := &model.Transaction{
+		ID:            7,
+		AccountID:     1,
+		Amount:        model.MustParseMoney("60.00"),
+		Balance:       model.MustParseMoney("40.00"),
+		OperationType: model.Payment,
+		Status:        model.Posted,
+	}
+
+	mockRepo.On("FindById", int64(7)).Return(original, nil)
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("FindByIdForUpdate", int64(7)).Return(original, nil)
+	mockRepo.On("UpdateBalance", int64(7), model.MustParseMoney("0.00")).Return(nil)
+	mockRepo.On("UpdateReversal", int64(7), model.MustParseMoney("50.00"), model.PartiallyReversed).Return(nil)
+	mockRepo.On("Create", mock.AnythingOfType("*model.Transaction")).Return(&model.Transaction{}, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-10.00")).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	amount := model.MustParseMoney("50.00")
+	reversal, reversed, err := service.ReverseTransaction(context.Background(), 7, &amount)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("-50.00"), reversal.Amount)
+	assert.Equal(t, model.MustParseMoney("-10.00"), reversal.Balance)
+	assert.Equal(t, model.PartiallyReversed, reversed.Status)
+	assert.Equal(t, model.MustParseMoney("10.00"), reversed.UnreversedAmount())
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ReverseTransactionRejected(t *testing.T) {
+	reversalOfID := int64(3)
+	tooMuch := model.MustParseMoney("60.00")
+
+	tests := []struct {
+		name        string
+		original    *model.Transaction
+		amount      *model.Money
+		expectedErr string
+	}{
+		{
+			name:        "already reversed",
+			original:    &model.Transaction{ID: 7, AccountID: 1, Amount: model.MustParseMoney("-50.00"), ReversedAmount: model.MustParseMoney("50.00"), Status: model.Reversed},
+			expectedErr: "Transaction is already reversed",
+		},
+		{
+			name:        "reversal of a reversal",
+			original:    &model.Transaction{ID: 7, AccountID: 1, Amount: model.MustParseMoney("50.00"), ReversalOfID: &reversalOfID},
+			expectedErr: "A reversal cannot be reversed",
+		},
+		{
+			name:        "amount above unreversed",
+			original:    &model.Transaction{ID: 7, AccountID: 1, Amount: model.MustParseMoney("-80.00"), ReversedAmount: model.MustParseMoney("30.00"), Status: model.PartiallyReversed},
+			amount:      &tooMuch,
+			expectedErr: "Reversal amount must be positive and at most the unreversed amount of 50.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAccountRepo := new(MockAccountRepository)
+			mockRepo := new(MockTransactionRepository)
+
+			mockRepo.On("FindById", int64(7)).Return(tt.original, nil)
+			mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(&model.Account{ID: 1, Status: model.AccountActive}, nil)
+			mockRepo.On("FindByIdForUpdate", int64(7)).Return(tt.original, nil)
+
+			service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+			_, _, err := service.ReverseTransaction(context.Background(), 7, tt.amount)
+
+			assert.ErrorAs(t, err, &internalErrors.UnprocessableEntityError{})
+			assert.EqualError(t, err, tt.expectedErr)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+			mockRepo.AssertNotCalled(t, "UpdateReversal", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransactionService_ReverseTransactionNotFound(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+
+	mockRepo.On("FindById", int64(7)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, _, err := service.ReverseTransaction(context.Background(), 7, nil)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+	mockAccountRepo.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything)
+}
+
+func TestTransactionService_CreateTransactionAccountNotActive(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+
+	transaction := &model.Transaction{
+		AccountID:     1,
+		Amount:        model.MustParseMoney("-10.00"),
+		OperationType: model.Purchase,
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(&model.Account{ID: 1, Status: model.AccountBlocked}, nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 0)
+	assert.ErrorAs(t, err, &internalErrors.AccountNotActiveError{})
+	assert.EqualError(t, err, "Account is blocked")
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_CreateTransactionWithInstallments(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockRepo := new(MockTransactionRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	mockInstallmentRepo := new(MockInstallmentRepository)
+
+	account := &model.Account{ID: 1, Status: model.AccountActive}
+
+	transaction := &model.Transaction{
+		ID:              5,
+		AccountID:       1,
+		Amount:          model.MustParseMoney("-100.00"),
+		OperationType:   model.InstallmentPurchase,
+		TransactionDate: time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	mockAccountRepo.On("FindByIdForUpdate", int64(1)).Return(account, nil)
+	mockRepo.On("Create", transaction).Return(transaction, nil)
+	mockAccountRepo.On("UpdateBalance", int64(1), model.MustParseMoney("-100.00")).Return(nil)
+	mockInstallmentRepo.On("Create", mock.MatchedBy(func(installments []model.Installment) bool {
+		return len(installments) == 3 &&
+			installments[0].Amount == model.MustParseMoney("33.34") &&
+			installments[2].TransactionID == 5 &&
+			installments[2].DueDate.Equal(time.Date(2024, time.April, 10, 0, 0, 0, 0, time.UTC))
+	})).Return(nil)
+
+	mockLedgerRepo.On("CreateJournal", mock.AnythingOfType("*ledger.Journal")).Return(nil)
+	mockOutboxRepo.On("Append", mock.AnythingOfType("*model.OutboxMessage")).Return(nil)
+
+	service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, mockInstallmentRepo, new(MockStatementRepository), mockLedgerRepo, mockOutboxRepo})
+
+	_, err := service.CreateTransaction(context.Background(), transaction, 3)
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockInstallmentRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_CreateTransactionInvalidInstallments(t *testing.T) {
+	tests := []struct {
+		name             string
+		operationType    model.OperationType
+		amount           model.Money
+		installmentCount int
+	}{
+		{"not an installment purchase", model.Purchase, model.MustParseMoney("-100.00"), 2},
+		{"too many installments", model.InstallmentPurchase, model.MustParseMoney("-100.00"), model.MaxInstallments + 1},
+		{"amount below one cent per installment", model.InstallmentPurchase, model.MustParseMoney("-0.02"), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAccountRepo := new(MockAccountRepository)
+			mockRepo := new(MockTransactionRepository)
+
+			service := NewTransactionService(mockRepo, &MockTransactor{mockAccountRepo, mockRepo, new(MockInstallmentRepository), new(MockStatementRepository), new(MockLedgerRepository), new(MockOutboxRepository)})
+
+			_, err := service.CreateTransaction(context.Background(), &model.Transaction{AccountID: 1, Amount: tt.amount, OperationType: tt.operationType}, tt.installmentCount)
+
+			assert.ErrorAs(t, err, &internalErrors.ValidationError{})
+			mockAccountRepo.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything)
+		})
+	}
 }
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/tracing"
+	"github.com/gmerten/accounts_transactions/internal/webhook"
+	"gorm.io/gorm"
+)
+
+type webhookService struct {
+	repository         repository.WebhookRepository
+	deliveryRepository repository.WebhookDeliveryRepository
+	now                func() time.Time
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id int64) (*model.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]model.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, id int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error)
+	RedriveDeadLetters(ctx context.Context, id int64) (int64, error)
+}
+
+func NewWebhookService(repository repository.WebhookRepository, deliveryRepository repository.WebhookDeliveryRepository) WebhookService {
+	return &webhookService{repository, deliveryRepository, time.Now}
+}
+
+// CreateWebhook saves the subscription, generating its signing secret when
+// none was given.
+func (w *webhookService) CreateWebhook(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+
+	if subscription.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Error generating webhook secret")
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+
+	subscription, err := w.repository.Create(ctx, subscription)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error saving webhook")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (w *webhookService) GetWebhook(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetWebhook")
+	defer span.End()
+
+	subscription, err := w.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, internalErrors.NewNotFoundError(internalErrors.WebhookNotFound, "Webhook not found")
+		}
+		logging.FromContext(ctx).WithField("webhookID", id).WithError(err).Error("Error getting webhook")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (w *webhookService) ListWebhooks(ctx context.Context) ([]model.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListWebhooks")
+	defer span.End()
+
+	subscriptions, err := w.repository.FindAll(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error listing webhooks")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// UpdateWebhook replaces the URL, event types and active flag of an existing
+// subscription. Its secret never changes.
+func (w *webhookService) UpdateWebhook(ctx context.Context, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.UpdateWebhook")
+	defer span.End()
+
+	existing, err := w.GetWebhook(ctx, subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.URL = subscription.URL
+	existing.EventTypes = subscription.EventTypes
+	existing.Active = subscription.Active
+
+	if err = w.repository.Update(ctx, existing); err != nil {
+		logging.FromContext(ctx).WithField("webhookID", existing.ID).WithError(err).Error("Error updating webhook")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (w *webhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+
+	deleted, err := w.repository.Delete(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx).WithField("webhookID", id).WithError(err).Error("Error deleting webhook")
+		tracing.RecordError(span, err)
+		return err
+	}
+	if deleted == 0 {
+		return internalErrors.NewNotFoundError(internalErrors.WebhookNotFound, "Webhook not found")
+	}
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of the subscription, newest
+// first, optionally only those in the given status.
+func (w *webhookService) ListDeliveries(ctx context.Context, id int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListDeliveries")
+	defer span.End()
+
+	if _, err := w.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := w.deliveryRepository.FindBySubscriptionId(ctx, id, status, limit)
+	if err != nil {
+		logging.FromContext(ctx).WithField("webhookID", id).WithError(err).Error("Error listing webhook deliveries")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RedriveDeadLetters queues the dead lettered deliveries of the subscription
+// again and returns how many were queued.
+func (w *webhookService) RedriveDeadLetters(ctx context.Context, id int64) (int64, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.RedriveDeadLetters")
+	defer span.End()
+
+	if _, err := w.GetWebhook(ctx, id); err != nil {
+		return 0, err
+	}
+
+	redriven, err := w.deliveryRepository.Redrive(ctx, id, w.now())
+	if err != nil {
+		logging.FromContext(ctx).WithField("webhookID", id).WithError(err).Error("Error redriving webhook deliveries")
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+	return redriven, nil
+}
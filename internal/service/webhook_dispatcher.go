@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/logging"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/publisher"
+	"github.com/gmerten/accounts_transactions/internal/repository"
+	"github.com/gmerten/accounts_transactions/internal/webhook"
+)
+
+const webhookDeliveryBatchSize = 100
+
+type webhookDispatcher struct {
+	repository         repository.WebhookRepository
+	deliveryRepository repository.WebhookDeliveryRepository
+	sender             webhook.Sender
+	maxAttempts        int
+	now                func() time.Time
+}
+
+// WebhookDispatcher is the outbox publisher of webhooks. Publish queues a
+// delivery of the event for each matching subscription, and DeliverDue sends
+// the queued deliveries.
+type WebhookDispatcher interface {
+	publisher.Publisher
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+func NewWebhookDispatcher(repository repository.WebhookRepository, deliveryRepository repository.WebhookDeliveryRepository, sender webhook.Sender, maxAttempts int) WebhookDispatcher {
+	return &webhookDispatcher{repository, deliveryRepository, sender, maxAttempts, time.Now}
+}
+
+// Publish queues the event for every active subscription that accepts its
+// type. Deliveries are unique per subscription and event, so republishing an
+// event does not queue it twice.
+func (w *webhookDispatcher) Publish(ctx context.Context, message *model.OutboxMessage) error {
+	subscriptions, err := w.repository.FindActive(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error listing active webhooks")
+		return err
+	}
+
+	var payload []byte
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !subscription.Accepts(message.EventType) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = webhook.NewEnvelope(message); err != nil {
+				return err
+			}
+		}
+
+		_, err = w.deliveryRepository.Create(ctx, &model.WebhookDelivery{
+			SubscriptionID:  subscription.ID,
+			OutboxMessageID: message.ID,
+			EventType:       message.EventType,
+			Payload:         string(payload),
+			Status:          model.DeliveryPending,
+			NextAttemptAt:   w.now(),
+		})
+		// A duplicate was queued by an earlier publish of the event, and a
+		// foreign key violation means the subscription was deleted meanwhile.
+		if err != nil && !internalErrors.IsDuplicateKeyError(err) && !internalErrors.IsForeignKeyError(err) {
+			logging.FromContext(ctx).WithField("webhookID", subscription.ID).WithError(err).Error("Error queueing webhook delivery")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverDue sends a batch of due deliveries and returns how many succeeded.
+// Failed deliveries are retried with exponential backoff and dead lettered
+// after maxAttempts attempts. Deliveries of inactive subscriptions are dead
+// lettered without being sent.
+func (w *webhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := w.deliveryRepository.FindDue(ctx, w.now(), webhookDeliveryBatchSize)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error reading due webhook deliveries")
+		return 0, err
+	}
+
+	subscriptions := make(map[int64]*model.WebhookSubscription)
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		subscription, found := subscriptions[delivery.SubscriptionID]
+		if !found {
+			if subscription, err = w.repository.FindById(ctx, delivery.SubscriptionID); err != nil {
+				logging.FromContext(ctx).WithField("webhookID", delivery.SubscriptionID).WithError(err).Error("Error getting webhook")
+				continue
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		if w.attempt(ctx, subscription, delivery) {
+			delivered++
+		}
+
+		if err = w.deliveryRepository.UpdateAttempt(ctx, delivery); err != nil {
+			logging.FromContext(ctx).WithField("deliveryID", delivery.ID).WithError(err).Error("Error saving webhook delivery attempt")
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+// attempt sends the delivery and records the outcome on it.
+func (w *webhookDispatcher) attempt(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) bool {
+	if !subscription.Active {
+		delivery.Status = model.DeliveryDeadLettered
+		delivery.LastError = "Webhook is not active"
+		return false
+	}
+
+	statusCode, err := w.sender.Send(ctx, subscription, delivery)
+	now := w.now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+
+	if err == nil {
+		delivery.Status = model.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return true
+	}
+
+	logging.FromContext(ctx).WithField("deliveryID", delivery.ID).WithError(err).Warn("Error sending webhook delivery")
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= w.maxAttempts {
+		delivery.Status = model.DeliveryDeadLettered
+	} else {
+		delivery.NextAttemptAt = now.Add(webhook.Backoff(delivery.Attempts))
+	}
+	return false
+}
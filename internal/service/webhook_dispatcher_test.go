@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/gmerten/accounts_transactions/internal/webhook"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookDispatcher_Publish(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockDeliveryRepo := new(MockWebhookDeliveryRepository)
+
+	now := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+	message := &model.OutboxMessage{ID: 7, AccountID: 1, EventType: model.TransactionPostedEvent, Payload: `{}`, OccurredAt: now}
+
+	mockRepo.On("FindActive").Return([]model.WebhookSubscription{
+		{ID: 1, Active: true},
+		{ID: 2, Active: true, EventTypes: model.EventTypes{model.AccountCreatedEvent}},
+		{ID: 3, Active: true, EventTypes: model.EventTypes{model.TransactionPostedEvent}},
+		{ID: 4, Active: true},
+	}, nil)
+	mockDeliveryRepo.On("Create", mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+		return delivery.SubscriptionID == 1 && delivery.OutboxMessageID == 7 && delivery.Status == model.DeliveryPending && delivery.NextAttemptAt.Equal(now)
+	})).Return(&model.WebhookDelivery{}, nil)
+	mockDeliveryRepo.On("Create", mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+		return delivery.SubscriptionID == 3
+	})).Return(nil, &mysql.MySQLError{Number: 1062})
+	mockDeliveryRepo.On("Create", mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+		return delivery.SubscriptionID == 4
+	})).Return(nil, &pgconn.PgError{Code: "23503"})
+
+	dispatcher := &webhookDispatcher{mockRepo, mockDeliveryRepo, new(MockSender), 3, func() time.Time { return now }}
+
+	err := dispatcher.Publish(context.Background(), message)
+
+	assert.NoError(t, err)
+	mockDeliveryRepo.AssertNumberOfCalls(t, "Create", 3)
+	mockDeliveryRepo.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_PublishError(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockDeliveryRepo := new(MockWebhookDeliveryRepository)
+
+	mockRepo.On("FindActive").Return([]model.WebhookSubscription{{ID: 1, Active: true}}, nil)
+	mockDeliveryRepo.On("Create", mock.AnythingOfType("*model.WebhookDelivery")).Return(nil, errors.New("db error"))
+
+	dispatcher := NewWebhookDispatcher(mockRepo, mockDeliveryRepo, new(MockSender), 3)
+
+	err := dispatcher.Publish(context.Background(), &model.OutboxMessage{ID: 7, EventType: model.AccountCreatedEvent, Payload: `{}`})
+
+	assert.EqualError(t, err, "db error")
+}
+
+func TestWebhookDispatcher_DeliverDue(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockDeliveryRepo := new(MockWebhookDeliveryRepository)
+	mockSender := new(MockSender)
+
+	now := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+	active := &model.WebhookSubscription{ID: 1, Active: true}
+	inactive := &model.WebhookSubscription{ID: 2, Active: false}
+	deliveries := []model.WebhookDelivery{
+		{ID: 1, SubscriptionID: 1, Status: model.DeliveryPending},
+		{ID: 2, SubscriptionID: 1, Status: model.DeliveryPending, Attempts: 1},
+		{ID: 3, SubscriptionID: 1, Status: model.DeliveryPending, Attempts: 2},
+		{ID: 4, SubscriptionID: 2, Status: model.DeliveryPending},
+	}
+
+	mockDeliveryRepo.On("FindDue", now, webhookDeliveryBatchSize).Return(deliveries, nil)
+	mockRepo.On("FindById", int64(1)).Return(active, nil).Once()
+	mockRepo.On("FindById", int64(2)).Return(inactive, nil).Once()
+	mockSender.On("Send", active, &deliveries[0]).Return(204, nil)
+	mockSender.On("Send", active, &deliveries[1]).Return(500, errors.New("unexpected status 500"))
+	mockSender.On("Send", active, &deliveries[2]).Return(0, errors.New("connection refused"))
+	mockDeliveryRepo.On("UpdateAttempt", mock.AnythingOfType("*model.WebhookDelivery")).Return(nil)
+
+	dispatcher := &webhookDispatcher{mockRepo, mockDeliveryRepo, mockSender, 3, func() time.Time { return now }}
+
+	delivered, err := dispatcher.DeliverDue(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	assert.Equal(t, model.DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, &now, deliveries[0].DeliveredAt)
+
+	assert.Equal(t, model.DeliveryPending, deliveries[1].Status)
+	assert.Equal(t, 2, deliveries[1].Attempts)
+	assert.Equal(t, 500, deliveries[1].LastStatusCode)
+	assert.Equal(t, now.Add(webhook.Backoff(2)), deliveries[1].NextAttemptAt)
+
+	assert.Equal(t, model.DeliveryDeadLettered, deliveries[2].Status)
+	assert.Equal(t, 3, deliveries[2].Attempts)
+	assert.Equal(t, "connection refused", deliveries[2].LastError)
+
+	assert.Equal(t, model.DeliveryDeadLettered, deliveries[3].Status)
+	assert.Equal(t, "Webhook is not active", deliveries[3].LastError)
+	mockSender.AssertNotCalled(t, "Send", inactive, mock.Anything)
+
+	mockDeliveryRepo.AssertNumberOfCalls(t, "UpdateAttempt", 4)
+	mockRepo.AssertExpectations(t)
+	mockSender.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_DeliverDueUpdateError(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockDeliveryRepo := new(MockWebhookDeliveryRepository)
+	mockSender := new(MockSender)
+
+	subscription := &model.WebhookSubscription{ID: 1, Active: true}
+	deliveries := []model.WebhookDelivery{
+		{ID: 1, SubscriptionID: 1, Status: model.DeliveryPending},
+		{ID: 2, SubscriptionID: 1, Status: model.DeliveryPending},
+	}
+
+	mockDeliveryRepo.On("FindDue", mock.Anything, webhookDeliveryBatchSize).Return(deliveries, nil)
+	mockRepo.On("FindById", int64(1)).Return(subscription, nil)
+	mockSender.On("Send", subscription, &deliveries[0]).Return(200, nil)
+	mockDeliveryRepo.On("UpdateAttempt", &deliveries[0]).Return(errors.New("db error"))
+
+	dispatcher := NewWebhookDispatcher(mockRepo, mockDeliveryRepo, mockSender, 3)
+
+	delivered, err := dispatcher.DeliverDue(context.Background())
+
+	assert.EqualError(t, err, "db error")
+	assert.Equal(t, 1, delivered)
+	mockSender.AssertNotCalled(t, "Send", subscription, &deliveries[1])
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestWebhookService_CreateWebhookGeneratesSecret(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+
+	mockRepo.On("Create", mock.AnythingOfType("*model.WebhookSubscription")).Return(&model.WebhookSubscription{ID: 1}, nil)
+
+	service := NewWebhookService(mockRepo, new(MockWebhookDeliveryRepository))
+
+	subscription := &model.WebhookSubscription{URL: "https://example.com/hooks", Active: true}
+	_, err := service.CreateWebhook(context.Background(), subscription)
+
+	assert.NoError(t, err)
+	assert.Len(t, subscription.Secret, 64)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_CreateWebhookKeepsSecret(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+
+	subscription := &model.WebhookSubscription{URL: "https://example.com/hooks", Secret: "a-secret-of-my-own"}
+	mockRepo.On("Create", subscription).Return(subscription, nil)
+
+	service := NewWebhookService(mockRepo, new(MockWebhookDeliveryRepository))
+
+	created, err := service.CreateWebhook(context.Background(), subscription)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a-secret-of-my-own", created.Secret)
+}
+
+func TestWebhookService_GetWebhookNotFoundError(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+
+	mockRepo.On("FindById", int64(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewWebhookService(mockRepo, new(MockWebhookDeliveryRepository))
+
+	_, err := service.GetWebhook(context.Background(), 2)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+	assert.EqualError(t, err, "Webhook not found")
+}
+
+func TestWebhookService_UpdateWebhookKeepsSecret(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+
+	existing := &model.WebhookSubscription{ID: 1, URL: "https://example.com/old", Secret: "the-original-secret", Active: true}
+	mockRepo.On("FindById", int64(1)).Return(existing, nil)
+	mockRepo.On("Update", existing).Return(nil)
+
+	service := NewWebhookService(mockRepo, new(MockWebhookDeliveryRepository))
+
+	updated, err := service.UpdateWebhook(context.Background(), &model.WebhookSubscription{
+		ID:         1,
+		URL:        "https://example.com/new",
+		EventTypes: model.EventTypes{model.TransactionPostedEvent},
+		Active:     false,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", updated.URL)
+	assert.Equal(t, model.EventTypes{model.TransactionPostedEvent}, updated.EventTypes)
+	assert.False(t, updated.Active)
+	assert.Equal(t, "the-original-secret", updated.Secret)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeleteWebhookNotFoundError(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+
+	mockRepo.On("Delete", int64(2)).Return(int64(0), nil)
+
+	service := NewWebhookService(mockRepo, new(MockWebhookDeliveryRepository))
+
+	err := service.DeleteWebhook(context.Background(), 2)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+}
+
+func TestWebhookService_ListDeliveries(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockDeliveryRepo := new(MockWebhookDeliveryRepository)
+
+	deliveries := []model.WebhookDelivery{{ID: 2, SubscriptionID: 1, Status: model.DeliveryDeadLettered}}
+	mockRepo.On("FindById", int64(1)).Return(&model.WebhookSubscription{ID: 1}, nil)
+	mockDeliveryRepo.On("FindBySubscriptionId", int64(1), model.DeliveryDeadLettered, 20).Return(deliveries, nil)
+
+	service := NewWebhookService(mockRepo, mockDeliveryRepo)
+
+	found, err := service.ListDeliveries(context.Background(), 1, model.DeliveryDeadLettered, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, deliveries, found)
+
+	mockDeliveryRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_RedriveDeadLetters(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockDeliveryRepo := new(MockWebhookDeliveryRepository)
+
+	now := time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC)
+	mockRepo.On("FindById", int64(1)).Return(&model.WebhookSubscription{ID: 1}, nil)
+	mockDeliveryRepo.On("Redrive", int64(1), now).Return(int64(3), nil)
+
+	service := &webhookService{mockRepo, mockDeliveryRepo, func() time.Time { return now }}
+
+	redriven, err := service.RedriveDeadLetters(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), redriven)
+}
+
+func TestWebhookService_RedriveDeadLettersNotFoundError(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockDeliveryRepo := new(MockWebhookDeliveryRepository)
+
+	mockRepo.On("FindById", int64(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewWebhookService(mockRepo, mockDeliveryRepo)
+
+	_, err := service.RedriveDeadLetters(context.Background(), 2)
+
+	assert.ErrorAs(t, err, &internalErrors.NotFoundError{})
+	mockDeliveryRepo.AssertNotCalled(t, "Redrive", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_ListWebhooksError(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+
+	mockRepo.On("FindAll").Return(nil, errors.New("db error"))
+
+	service := NewWebhookService(mockRepo, new(MockWebhookDeliveryRepository))
+
+	_, err := service.ListWebhooks(context.Background())
+
+	assert.EqualError(t, err, "db error")
+}
@@ -0,0 +1,110 @@
+// Package tracing sets up OpenTelemetry tracing and starts the spans of the
+// HTTP, service and database layers. Incoming requests continue the trace of
+// their W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gmerten/accounts_transactions/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/gmerten/accounts_transactions"
+
+// Attribute keys of the service spans.
+const (
+	AccountIDKey     = attribute.Key("account.id")
+	TransactionIDKey = attribute.Key("transaction.id")
+	OperationTypeKey = attribute.Key("transaction.operation_type")
+	APIClientIDKey   = attribute.Key("api_client.id")
+)
+
+// Setup installs the global tracer provider and the W3C trace context and
+// baggage propagators. It returns a function that flushes the pending spans
+// and stops the provider. With the none exporter no spans are recorded, but
+// trace context is still propagated.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	setPropagator()
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case config.StdoutExporter:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.OTLPExporter:
+		var options []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// InMemory installs a tracer provider that keeps every span in the returned
+// exporter as soon as it ends, for tests.
+func InMemory() *tracetest.InMemoryExporter {
+	setPropagator()
+
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	return exporter
+}
+
+func setPropagator() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Start starts a span as a child of the span in ctx, if any, and returns a
+// context holding it. The span must be ended by the caller.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// statusError is implemented by the errors the API reports with their own
+// status code.
+type statusError interface {
+	StatusCode() int
+}
+
+// RecordError records an error on the span and marks it failed. Errors
+// reported to the client with a 4xx status, such as a rejected posting, are
+// part of the normal flow and leave the span untouched.
+func RecordError(span trace.Span, err error) {
+	var statusErr statusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode() < http.StatusInternalServerError {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
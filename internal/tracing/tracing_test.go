@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmerten/accounts_transactions/internal/config"
+	internalErrors "github.com/gmerten/accounts_transactions/internal/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSetup_NoExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: config.NoExporter})
+
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestRecordError(t *testing.T) {
+	exporter := InMemory()
+
+	_, failed := Start(context.Background(), "failed")
+	RecordError(failed, errors.New("connection refused"))
+	failed.End()
+
+	_, rejected := Start(context.Background(), "rejected")
+	RecordError(rejected, internalErrors.NewCreditLimitExceededError("Transaction exceeds the available credit limit"))
+	rejected.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Len(t, spans[0].Events, 1)
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	assert.Empty(t, spans[1].Events)
+}
+
+func TestStartRequest_ContinuesTrace(t *testing.T) {
+	exporter := InMemory()
+
+	req := httptest.NewRequest("GET", "/accounts/1", http.NoBody)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	_, span := StartRequest(req)
+	EndRequest(span, "GET", "/accounts/{accountID}", http.StatusInternalServerError)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /accounts/{accountID}", spans[0].Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
+
+func TestGormPlugin(t *testing.T) {
+	exporter := InMemory()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GormPlugin()))
+	require.NoError(t, db.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY, document_number TEXT)").Error)
+
+	var count int64
+	require.NoError(t, db.WithContext(context.Background()).Table("accounts").Count(&count).Error)
+	assert.Empty(t, exporter.GetSpans())
+
+	ctx, parent := Start(context.Background(), "parent")
+	require.NoError(t, db.WithContext(ctx).Table("accounts").Where("document_number = ?", "12345678909").Count(&count).Error)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "db.query accounts", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	for _, attribute := range spans[0].Attributes {
+		if attribute.Key == "db.query.text" {
+			assert.Contains(t, attribute.Value.AsString(), "document_number = ?")
+			assert.NotContains(t, attribute.Value.AsString(), "12345678909")
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package webhook signs and sends event deliveries to subscribed HTTP
+// endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	signaturePrefix = "sha256="
+
+	minBackoff = 10 * time.Second
+	maxBackoff = time.Hour
+)
+
+// Envelope is the body sent to endpoints. ID is the ID of the event, shared by
+// every delivery and redelivery of it, so receivers can deduplicate.
+type Envelope struct {
+	ID         int64           `json:"id"`
+	Type       model.EventType `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func NewEnvelope(message *model.OutboxMessage) ([]byte, error) {
+	return json.Marshal(Envelope{
+		ID:         message.ID,
+		Type:       message.EventType,
+		OccurredAt: message.OccurredAt,
+		Data:       json.RawMessage(message.Payload),
+	})
+}
+
+// Sign returns the signature header value of a body sent at timestamp, the
+// hex encoded HMAC-SHA256 of "<unix timestamp>.<body>" keyed with the secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a received body.
+func Verify(secret string, timestamp string, body []byte, signature string) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, unix, body)), []byte(signature))
+}
+
+// NewSecret generates a random signing secret.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Backoff is how long to wait after the given number of failed attempts. It
+// doubles with every attempt, from 10 seconds up to an hour.
+func Backoff(attempts int) time.Duration {
+	delay := minBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// Sender posts a delivery to its subscription endpoint. It returns the status
+// code of the response, and an error unless it was a 2xx.
+type Sender interface {
+	Send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error)
+}
+
+type httpSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewHTTPSender(timeout time.Duration) Sender {
+	return &httpSender{&http.Client{Timeout: timeout}, time.Now}
+}
+
+func (s *httpSender) Send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := s.now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, string(delivery.EventType))
+	request.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, body))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("endpoint responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
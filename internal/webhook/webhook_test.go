@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gmerten/accounts_transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", 1725271200, body)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.True(t, Verify("secret", "1725271200", body, signature))
+	assert.False(t, Verify("other", "1725271200", body, signature))
+	assert.False(t, Verify("secret", "1725271201", body, signature))
+	assert.False(t, Verify("secret", "1725271200", []byte(`{"id":2}`), signature))
+	assert.False(t, Verify("secret", "yesterday", body, signature))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 20*time.Second, Backoff(2))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(10))
+	assert.Equal(t, time.Hour, Backoff(1000))
+}
+
+func TestNewEnvelope(t *testing.T) {
+	message := &model.OutboxMessage{
+		ID:         9,
+		EventType:  model.AccountCreatedEvent,
+		Payload:    `{"account_id":1}`,
+		OccurredAt: time.Date(2024, time.September, 2, 10, 0, 0, 0, time.UTC),
+	}
+
+	envelope, err := NewEnvelope(message)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":9,"type":"account.created","occurred_at":"2024-09-02T10:00:00Z","data":{"account_id":1}}`, string(envelope))
+}
+
+func TestHTTPSender_Send(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	status := http.StatusNoContent
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	subscription := &model.WebhookSubscription{URL: receiver.URL, Secret: "secret"}
+	delivery := &model.WebhookDelivery{ID: 4, EventType: model.TransactionPostedEvent, Payload: `{"id":1}`}
+
+	sender := NewHTTPSender(time.Second)
+
+	statusCode, err := sender.Send(context.Background(), subscription, delivery)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+	assert.Equal(t, `{"id":1}`, string(receivedBody))
+	assert.Equal(t, "transaction.posted", received.Header.Get(EventHeader))
+	assert.Equal(t, "4", received.Header.Get(DeliveryHeader))
+	assert.True(t, Verify("secret", received.Header.Get(TimestampHeader), receivedBody, received.Header.Get(SignatureHeader)))
+
+	status = http.StatusServiceUnavailable
+
+	statusCode, err = sender.Send(context.Background(), subscription, delivery)
+
+	assert.EqualError(t, err, "endpoint responded with status 503")
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+}
@@ -0,0 +1,44 @@
+#!/bin/sh
+# Smoke tests the Docker image on SQLite: builds it, applies the migrations,
+# starts the API and creates an account with an admin key. The SQLite driver
+# only works in images built with cgo, which this catches.
+set -eu
+
+image=accounts_transactions:smoke
+container=accounts_transactions_smoke
+port=${SMOKE_PORT:-18080}
+
+cleanup() {
+	docker rm -f "$container" >/dev/null 2>&1 || true
+}
+trap cleanup EXIT
+
+docker build -t "$image" .
+cleanup
+docker run -d --name "$container" -p "$port:8080" \
+	-e DB_DRIVER=sqlite -e DB_NAME=/tmp/transactions.db \
+	"$image" sh -c "./main migrate up && exec ./main" >/dev/null
+
+ready=
+for _ in $(seq 1 30); do
+	if curl -fsS "http://localhost:$port/readyz" >/dev/null 2>&1; then
+		ready=1
+		break
+	fi
+	sleep 1
+done
+if [ -z "$ready" ]; then
+	echo "smoke test failed: the API did not become ready" >&2
+	docker logs "$container" >&2
+	exit 1
+fi
+
+key=$(docker exec "$container" ./main api-clients issue smoke admin | tail -n 1)
+
+curl -fsS --request POST \
+	--url "http://localhost:$port/accounts" \
+	--header "Authorization: Bearer $key" \
+	--header 'Content-Type: application/json' \
+	--data '{"document_number": "52998224725"}'
+echo
+echo "smoke test passed"